@@ -14,30 +14,18 @@ func newAccountCmd(app *app) *cobra.Command {
 
 	cmd.AddCommand(
 		newAccountListCmd(app),
+		newAccountTagCmd(app),
+		newAccountSetBaseURLCmd(app),
+		newAccountSetNameCmd(app),
+		newAccountSetIDCmd(app),
+		newAccountRemoveCmd(app),
+		newAccountDisableCmd(app),
+		newAccountEnableCmd(app),
 	)
 
 	return cmd
 }
 
-func newAccountListCmd(app *app) *cobra.Command {
-	return &cobra.Command{
-		Use:   "list",
-		Short: "List configured accounts",
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			statuses, err := app.service.GetStatusAll(cmd.Context())
-			if err != nil {
-				return err
-			}
-
-			for _, status := range statuses {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", status.Account.ID, status.Account.Name)
-			}
-
-			return nil
-		},
-	}
-}
-
 func newNotImplementedCmd(use string, short string) *cobra.Command {
 	return &cobra.Command{
 		Use:   use,
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountSetBaseURLCmd(app *app) *cobra.Command {
+	var accountID string
+	var clearOverride bool
+
+	cmd := &cobra.Command{
+		Use:   "set-base-url [url]",
+		Short: "Override the usage API base URL for one account",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseURL := ""
+			switch {
+			case clearOverride && len(args) > 0:
+				return fmt.Errorf("--clear cannot be combined with a url")
+			case !clearOverride && len(args) == 0:
+				return fmt.Errorf("a url or --clear is required")
+			case !clearOverride:
+				parsed, err := parseAccountBaseURL(args[0])
+				if err != nil {
+					return err
+				}
+				baseURL = parsed
+			}
+
+			if err := app.service.SetAccountBaseURL(cmd.Context(), domain.AccountID(accountID), baseURL); err != nil {
+				return err
+			}
+
+			if baseURL == "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Account %s uses the default base URL\n", accountID)
+				return nil
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Account %s base URL: %s\n", accountID, baseURL)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	cmd.Flags().BoolVar(&clearOverride, "clear", false, "Remove the override and use OA_USAGE_BASE_URL again")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
+
+func parseAccountBaseURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("base url must use http or https, got %q", raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("base url host is required, got %q", raw)
+	}
+	return trimmed, nil
+}
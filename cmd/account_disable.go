@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountDisableCmd(app *app) *cobra.Command {
+	return newAccountSetDisabledCmd(app, "disable", "Exclude an account from pool selection without removing it", true)
+}
+
+func newAccountEnableCmd(app *app) *cobra.Command {
+	return newAccountSetDisabledCmd(app, "enable", "Let pools select a disabled account again", false)
+}
+
+func newAccountSetDisabledCmd(app *app, use, short string, disabled bool) *cobra.Command {
+	var accountID string
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := app.service.SetAccountDisabled(cmd.Context(), domain.AccountID(accountID), disabled); err != nil {
+				return err
+			}
+
+			state := "enabled"
+			if disabled {
+				state = "disabled"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Account %s %s\n", accountID, state)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -43,3 +44,46 @@ func nextAvailableAccountID(ctx context.Context, app *app) (domain.AccountID, er
 		}
 	}
 }
+
+// resolveAccountRef finds an account by exact ID, then by case-insensitive
+// name, then by an unambiguous name prefix (so "user1" matches
+// "user1@example.com").
+func resolveAccountRef(accounts []domain.Account, ref string) (domain.Account, error) {
+	trimmed := strings.TrimSpace(ref)
+	if trimmed == "" {
+		return domain.Account{}, fmt.Errorf("account reference is empty")
+	}
+
+	for _, account := range accounts {
+		if string(account.ID) == trimmed {
+			return account, nil
+		}
+	}
+	for _, account := range accounts {
+		if strings.EqualFold(strings.TrimSpace(account.Name), trimmed) {
+			return account, nil
+		}
+	}
+
+	lowered := strings.ToLower(trimmed)
+	matches := make([]domain.Account, 0, 1)
+	for _, account := range accounts {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(account.Name)), lowered) {
+			matches = append(matches, account)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return domain.Account{}, accountNotFoundError{ref: trimmed}
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, account := range matches {
+			names = append(names, fmt.Sprintf("%s (%s)", account.Name, account.ID))
+		}
+		sort.Strings(names)
+		return domain.Account{}, fmt.Errorf("account %q is ambiguous (matches: %s)", trimmed, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+type accountListColumn struct {
+	name    string
+	header  string
+	value   func(application.Status) string
+	valueAt func(application.Status, time.Time) string
+}
+
+func (c accountListColumn) cell(status application.Status, now time.Time) string {
+	if c.valueAt != nil {
+		return c.valueAt(status, now)
+	}
+	return c.value(status)
+}
+
+var accountListColumns = []accountListColumn{
+	{name: "id", header: "ID", value: func(s application.Status) string { return string(s.Account.ID) }},
+	{name: "name", header: "NAME", value: func(s application.Status) string { return s.Account.Name }},
+	{name: "plan", header: "PLAN", value: func(s application.Status) string { return valueOrDash(s.Account.Metadata.PlanType) }},
+	{name: "weekly", header: "WEEKLY", value: func(s application.Status) string { return limitPercentCell(s.WeeklyLimit) }},
+	{name: "daily", header: "DAILY", value: func(s application.Status) string { return limitPercentCell(s.DailyLimit) }},
+	{name: "expiry", header: "EXPIRY", value: subscriptionExpiryCell},
+	{name: "tags", header: "TAGS", value: func(s application.Status) string { return valueOrDash(strings.Join(s.Account.Metadata.Tags, ",")) }},
+	{name: "provider", header: "PROVIDER", value: func(s application.Status) string { return valueOrDash(s.Account.Metadata.Provider) }},
+	{name: "last-fetched", header: "LAST FETCHED", valueAt: lastFetchedCell},
+}
+
+const defaultAccountListColumns = "id,name"
+
+const accountListSortLastFetched = "last-fetched"
+
+type accountListEntry struct {
+	ID            domain.AccountID `json:"id"`
+	Name          string           `json:"name"`
+	Provider      string           `json:"provider,omitempty"`
+	PlanType      string           `json:"plan_type,omitempty"`
+	WeeklyPercent *float64         `json:"weekly_percent,omitempty"`
+	DailyPercent  *float64         `json:"daily_percent,omitempty"`
+	ActiveUntil   *time.Time       `json:"active_until,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Active        bool             `json:"active"`
+	Disabled      bool             `json:"disabled,omitempty"`
+	SecretBackend string           `json:"secret_backend,omitempty"`
+	SecretMissing bool             `json:"secret_missing,omitempty"`
+	LastFetchedAt *time.Time       `json:"last_fetched_at,omitempty"`
+
+	Subscription *accountListSubscription `json:"subscription,omitempty"`
+}
+
+type accountListSubscription struct {
+	ActiveStart     time.Time `json:"active_start"`
+	ActiveUntil     time.Time `json:"active_until"`
+	WillRenew       bool      `json:"will_renew"`
+	BillingPeriod   string    `json:"billing_period,omitempty"`
+	BillingCurrency string    `json:"billing_currency,omitempty"`
+	IsDelinquent    bool      `json:"is_delinquent"`
+}
+
+func newAccountListCmd(app *app) *cobra.Command {
+	var (
+		columns           string
+		onlyActive        bool
+		withSecretBackend bool
+		format            string
+		sortBy            string
+		accountRef        string
+		emailOnly         bool
+		count             bool
+		onlyExhausted     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured accounts",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			selected, err := parseAccountListColumns(columns)
+			if err != nil {
+				return err
+			}
+			outFormat, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+			sortBy = strings.ToLower(strings.TrimSpace(sortBy))
+			if sortBy != "" && sortBy != accountListSortLastFetched {
+				return fmt.Errorf("unsupported sort %q (valid: %s)", sortBy, accountListSortLastFetched)
+			}
+
+			if emailOnly && outFormat != outputFormatText {
+				return fmt.Errorf("--email-only cannot be combined with --format or --json")
+			}
+			if count && outFormat != outputFormatText {
+				return fmt.Errorf("--count cannot be combined with --format or --json")
+			}
+
+			statuses, err := loadStatuses(cmd, app.service, accountRef)
+			if err != nil {
+				return err
+			}
+			if sortBy == accountListSortLastFetched {
+				sortStatusesByLastFetched(statuses)
+			}
+
+			activeAccountID, err := app.continuityService.GetActiveAccountID(cmd.Context(), application.DefaultOpenAIPoolID)
+			if err != nil {
+				return fmt.Errorf("load active pool account: %w", err)
+			}
+			if onlyActive {
+				if activeAccountID == "" {
+					return fmt.Errorf("no active account in pool %s", application.DefaultOpenAIPoolID)
+				}
+				statuses = filterStatusesByAccount(statuses, activeAccountID)
+			}
+			if onlyExhausted {
+				statuses = filterExhaustedStatuses(statuses, app.clock.Now())
+			}
+
+			out := cmd.OutOrStdout()
+			if count {
+				_, _ = fmt.Fprintln(out, len(statuses))
+				return nil
+			}
+			if emailOnly {
+				// Account names hold the login email; one per line for scripts.
+				for _, status := range statuses {
+					_, _ = fmt.Fprintln(out, sanitizeForTerminal(status.Account.Name))
+				}
+				return nil
+			}
+			if outFormat != outputFormatText {
+				entries := make([]accountListEntry, 0, len(statuses))
+				for _, status := range statuses {
+					entry := newAccountListEntry(status, activeAccountID)
+					entry.SecretMissing, err = secretMissing(cmd.Context(), app, status.Account)
+					if err != nil {
+						return err
+					}
+					if withSecretBackend {
+						entry.SecretBackend = secretBackendName(cmd.Context(), app, status.Account)
+					}
+					entries = append(entries, entry)
+				}
+				return writeStructured(out, outFormat, entries)
+			}
+
+			now := app.clock.Now()
+			if cmd.Flags().Changed("columns") {
+				headers := make([]string, 0, len(selected))
+				for _, column := range selected {
+					headers = append(headers, column.header)
+				}
+				if withSecretBackend {
+					headers = append(headers, "BACKEND")
+				}
+				_, _ = fmt.Fprintln(out, strings.Join(headers, "\t"))
+			}
+
+			for _, status := range statuses {
+				cells := make([]string, 0, len(selected))
+				for _, column := range selected {
+					cells = append(cells, column.cell(status, now))
+				}
+				if withSecretBackend {
+					cells = append(cells, secretBackendCell(cmd.Context(), app, status.Account))
+				}
+				if activeAccountID != "" && status.Account.ID == activeAccountID {
+					cells = append(cells, "(active)")
+				}
+				missing, err := secretMissing(cmd.Context(), app, status.Account)
+				if err != nil {
+					return err
+				}
+				if missing {
+					cells = append(cells, "(secret missing)")
+				}
+				_, _ = fmt.Fprintln(out, strings.Join(cells, "\t"))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&columns, "columns", defaultAccountListColumns, "Comma-separated columns to display (id,name,plan,weekly,daily,expiry,tags,provider,last-fetched)")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort accounts (last-fetched: most recently fetched first)")
+	cmd.Flags().BoolVar(&onlyActive, "active", false, "Show only the pool-active account")
+	bindFormatFlag(cmd, &format)
+	cmd.Flags().BoolVar(&withSecretBackend, "with-secret-backend", false, "Show which secret backend holds each account's secret")
+	cmd.Flags().StringVar(&accountRef, "account", "", "Only list this account (ID or name; a unique name prefix also matches)")
+	cmd.Flags().BoolVar(&emailOnly, "email-only", false, "Print only each account's name/email, one per line")
+	cmd.Flags().BoolVar(&onlyExhausted, "exhausted", false, "Show only accounts whose weekly or 5-hour window is used up")
+	cmd.Flags().BoolVar(&count, "count", false, "Print only the number of matching accounts")
+	cmd.MarkFlagsMutuallyExclusive("email-only", "columns")
+	cmd.MarkFlagsMutuallyExclusive("email-only", "with-secret-backend")
+	cmd.MarkFlagsMutuallyExclusive("count", "email-only")
+	cmd.MarkFlagsMutuallyExclusive("count", "columns")
+	cmd.MarkFlagsMutuallyExclusive("count", "with-secret-backend")
+
+	return cmd
+}
+
+func parseAccountListColumns(raw string) ([]accountListColumn, error) {
+	byName := make(map[string]accountListColumn, len(accountListColumns))
+	valid := make([]string, 0, len(accountListColumns))
+	for _, column := range accountListColumns {
+		byName[column.name] = column
+		valid = append(valid, column.name)
+	}
+
+	selected := make([]accountListColumn, 0, len(accountListColumns))
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		column, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (valid columns: %s)", name, strings.Join(valid, ", "))
+		}
+		selected = append(selected, column)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("at least one column is required (valid columns: %s)", strings.Join(valid, ", "))
+	}
+
+	return selected, nil
+}
+
+func filterStatusesByAccount(statuses []application.Status, accountID domain.AccountID) []application.Status {
+	filtered := make([]application.Status, 0, 1)
+	for _, status := range statuses {
+		if status.Account.ID == accountID {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered
+}
+
+// filterExhaustedStatuses keeps accounts with a used-up weekly window or a
+// used-up 5-hour window that has not reset yet.
+func filterExhaustedStatuses(statuses []application.Status, now time.Time) []application.Status {
+	filtered := make([]application.Status, 0, len(statuses))
+	for _, status := range statuses {
+		if isStatusLimitExhausted(status.WeeklyLimit, now) || isStatusLimitExhausted(status.DailyLimit, now) {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered
+}
+
+func isStatusLimitExhausted(limit *application.StatusLimit, now time.Time) bool {
+	if limit == nil || limit.Percent < 100 {
+		return false
+	}
+	return limit.ResetsAt.IsZero() || limit.ResetsAt.After(now)
+}
+
+func newAccountListEntry(status application.Status, activeAccountID domain.AccountID) accountListEntry {
+	entry := accountListEntry{
+		ID:       status.Account.ID,
+		Name:     status.Account.Name,
+		Provider: status.Account.Metadata.Provider,
+		PlanType: status.Account.Metadata.PlanType,
+		Tags:     status.Account.Metadata.Tags,
+		Active:   activeAccountID != "" && status.Account.ID == activeAccountID,
+		Disabled: status.Account.Disabled,
+	}
+	if status.WeeklyLimit != nil {
+		percent := status.WeeklyLimit.Percent
+		entry.WeeklyPercent = &percent
+	}
+	if status.DailyLimit != nil {
+		percent := status.DailyLimit.Percent
+		entry.DailyPercent = &percent
+	}
+	if status.Subscription != nil && !status.Subscription.ActiveUntil.IsZero() {
+		activeUntil := status.Subscription.ActiveUntil.UTC()
+		entry.ActiveUntil = &activeUntil
+	}
+	if sub := status.Subscription; sub != nil {
+		entry.Subscription = &accountListSubscription{
+			ActiveStart:     sub.ActiveStart.UTC(),
+			ActiveUntil:     sub.ActiveUntil.UTC(),
+			WillRenew:       sub.WillRenew,
+			BillingPeriod:   sub.BillingPeriod,
+			BillingCurrency: sub.BillingCurrency,
+			IsDelinquent:    sub.IsDelinquent,
+		}
+	}
+	if fetchedAt := lastFetchedAt(status); !fetchedAt.IsZero() {
+		fetchedAt = fetchedAt.UTC()
+		entry.LastFetchedAt = &fetchedAt
+	}
+	return entry
+}
+
+// lastFetchedAt is the newest CapturedAt across the account's persisted
+// snapshots, or zero when usage was never fetched.
+func lastFetchedAt(status application.Status) time.Time {
+	var latest time.Time
+	consider := func(capturedAt time.Time) {
+		if capturedAt.After(latest) {
+			latest = capturedAt
+		}
+	}
+	limits := []*application.StatusLimit{status.DailyLimit, status.WeeklyLimit}
+	for _, feature := range status.FeatureLimits {
+		limits = append(limits, feature.DailyLimit, feature.WeeklyLimit)
+	}
+	for _, limit := range limits {
+		if limit != nil {
+			consider(limit.CapturedAt)
+		}
+	}
+	if status.Subscription != nil {
+		consider(status.Subscription.CapturedAt)
+	}
+	return latest
+}
+
+func lastFetchedCell(status application.Status, now time.Time) string {
+	fetchedAt := lastFetchedAt(status)
+	if fetchedAt.IsZero() {
+		return "never"
+	}
+	return formatAgo(now.Sub(fetchedAt))
+}
+
+func formatAgo(elapsed time.Duration) string {
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed/time.Minute))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(elapsed/(24*time.Hour)))
+	}
+}
+
+// sortStatusesByLastFetched orders the most recently fetched accounts first
+// and keeps never-fetched accounts at the end in their configured order.
+func sortStatusesByLastFetched(statuses []application.Status) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return lastFetchedAt(statuses[i]).After(lastFetchedAt(statuses[j]))
+	})
+}
+
+var plaintextBackendStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("203"))
+
+func secretBackendCell(ctx context.Context, app *app, account domain.Account) string {
+	backend := secretBackendName(ctx, app, account)
+	if backend == "file" {
+		return plaintextBackendStyle.Render("file (plaintext)")
+	}
+	return valueOrDash(backend)
+}
+
+func secretBackendName(ctx context.Context, app *app, account domain.Account) string {
+	secretRef := strings.TrimSpace(account.Auth.SecretRef)
+	if secretRef == "" {
+		return ""
+	}
+
+	locator, ok := app.secretStore.(interface {
+		WhichBackend(ctx context.Context, key string) (string, error)
+	})
+	if !ok {
+		return "unknown"
+	}
+
+	backend, err := locator.WhichBackend(ctx, secretRef)
+	if err != nil {
+		return "missing"
+	}
+	return backend
+}
+
+// secretMissing reports whether the account references a secret that no
+// backend holds. It checks presence only, so gpg-backed secrets are not
+// decrypted. cmd:// refs are not run: oa does not hold those secrets.
+func secretMissing(ctx context.Context, app *app, account domain.Account) (bool, error) {
+	secretRef := strings.TrimSpace(account.Auth.SecretRef)
+	if secretRef == "" || domain.IsCommandSecretRef(secretRef) {
+		return false, nil
+	}
+
+	found, err := app.secretStore.Has(ctx, secretRef)
+	if errors.Is(err, domain.ErrSecretNotFound) || errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check secret of account %s: %w", account.ID, err)
+	}
+	return !found, nil
+}
+
+func limitPercentCell(limit *application.StatusLimit) string {
+	if limit == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", limit.Percent)
+}
+
+func subscriptionExpiryCell(status application.Status) string {
+	if status.Subscription == nil || status.Subscription.ActiveUntil.IsZero() {
+		return "-"
+	}
+	return status.Subscription.ActiveUntil.UTC().Format("2006-01-02")
+}
+
+func valueOrDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountSetNameCmd(app *app) *cobra.Command {
+	var accountID string
+	var fromToken bool
+	var nameFlag string
+
+	cmd := &cobra.Command{
+		Use:     "set-name [name]",
+		Aliases: []string{"rename"},
+		Short:   "Rename an account, or take the name from its stored id_token email",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("name") {
+				if len(args) > 0 {
+					return fmt.Errorf("--name cannot be combined with a name argument")
+				}
+				args = []string{nameFlag}
+			}
+
+			var name string
+			switch {
+			case fromToken && len(args) > 0:
+				return fmt.Errorf("--from-token cannot be combined with a name")
+			case !fromToken && len(args) == 0:
+				return fmt.Errorf("a name or --from-token is required")
+			case fromToken:
+				email, err := storedTokenEmail(cmd.Context(), app, domain.AccountID(accountID))
+				if err != nil {
+					return err
+				}
+				name = email
+			default:
+				name = strings.TrimSpace(args[0])
+				if name == "" {
+					return fmt.Errorf("account name must not be empty")
+				}
+			}
+
+			if err := app.service.SetAccountName(cmd.Context(), domain.AccountID(accountID), name); err != nil {
+				if errors.Is(err, domain.ErrAccountNotFound) {
+					return accountNotFoundError{ref: accountID}
+				}
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Account %s name: %s\n", accountID, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	cmd.Flags().StringVar(&nameFlag, "name", "", "New account name (same as the name argument)")
+	cmd.Flags().BoolVar(&fromToken, "from-token", false, "Use the email from the stored id_token (no network call)")
+	cmd.MarkFlagsMutuallyExclusive("name", "from-token")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
+
+// storedTokenEmail decodes the email claim of the account's stored id_token.
+func storedTokenEmail(ctx context.Context, app *app, accountID domain.AccountID) (string, error) {
+	status, err := app.service.GetStatus(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+
+	secretRef := strings.TrimSpace(status.Account.Auth.SecretRef)
+	if status.Account.Auth.Method != domain.AuthMethodChatGPT || secretRef == "" {
+		return "", fmt.Errorf("account %s has no stored chatgpt tokens", accountID)
+	}
+
+	secretValue, err := app.secretStore.Get(ctx, secretRef)
+	if err != nil {
+		return "", fmt.Errorf("account %s: load auth secret: %w", accountID, err)
+	}
+	tokens, err := decodeOAuthTokens(secretValue)
+	if err != nil {
+		return "", fmt.Errorf("account %s: %w", accountID, err)
+	}
+
+	email := strings.TrimSpace(parseTokenClaims(tokens.IDToken).Email)
+	if email == "" {
+		return "", fmt.Errorf("account %s: stored id_token carries no email", accountID)
+	}
+	return email, nil
+}
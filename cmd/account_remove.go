@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountRemoveCmd(app *app) *cobra.Command {
+	var accountID string
+
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Delete an account and its stored secrets, pool memberships and sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := app.service.RemoveAccount(cmd.Context(), domain.AccountID(accountID)); err != nil {
+				if errors.Is(err, domain.ErrAccountNotFound) {
+					return accountNotFoundError{ref: accountID}
+				}
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Removed account %s\n", accountID)
+
+			pools, err := app.poolService.RemoveMember(cmd.Context(), domain.AccountID(accountID))
+			for _, poolID := range pools {
+				_, _ = fmt.Fprintf(out, "Updated pool %s\n", poolID)
+			}
+			if err != nil {
+				return fmt.Errorf("update pool members: %w", err)
+			}
+
+			runtimes, err := app.continuityService.ForgetAccount(cmd.Context(), domain.AccountID(accountID))
+			for _, poolID := range runtimes {
+				_, _ = fmt.Fprintf(out, "Updated runtime of pool %s\n", poolID)
+			}
+			if err != nil {
+				return fmt.Errorf("update pool runtime: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
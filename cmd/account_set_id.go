@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountSetIDCmd(app *app) *cobra.Command {
+	var from string
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "set-id",
+		Short: "Change an account's ID, moving its secrets, pool memberships and sessions along",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fromID := domain.AccountID(strings.TrimSpace(from))
+			toID := domain.AccountID(strings.TrimSpace(to))
+			if toID == "" {
+				return fmt.Errorf("--to must not be empty")
+			}
+			if n, err := strconv.Atoi(string(toID)); err == nil && n <= 0 {
+				return fmt.Errorf("--to must be a positive number or a non-numeric ID")
+			}
+			if fromID == toID {
+				return fmt.Errorf("account %s already has ID %s", fromID, toID)
+			}
+
+			renames, err := app.service.ChangeAccountID(cmd.Context(), fromID, toID, accountSecretRefRenames(app, fromID, toID))
+			if err != nil {
+				if errors.Is(err, domain.ErrAccountNotFound) {
+					return accountNotFoundError{ref: string(fromID)}
+				}
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Account %s is now %s\n", fromID, toID)
+			for _, rename := range renames {
+				_, _ = fmt.Fprintf(out, "Moved secret %s to %s\n", rename.From, rename.To)
+				if rename.DeleteErr != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v (remove it by hand)\n", rename.DeleteErr)
+				}
+			}
+
+			pools, err := app.poolService.RenameMember(cmd.Context(), fromID, toID)
+			for _, poolID := range pools {
+				_, _ = fmt.Fprintf(out, "Updated pool %s\n", poolID)
+			}
+			if err != nil {
+				return fmt.Errorf("update pool members: %w", err)
+			}
+
+			runtimes, err := app.continuityService.RenameAccount(cmd.Context(), fromID, toID)
+			for _, poolID := range runtimes {
+				_, _ = fmt.Fprintf(out, "Updated runtime of pool %s\n", poolID)
+			}
+			if err != nil {
+				return fmt.Errorf("update pool runtime: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Current account ID")
+	cmd.Flags().StringVar(&to, "to", "", "New account ID (must not exist yet)")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// accountSecretRefRenames maps the secret keys oa derives for from to the
+// ones it derives for to, under both the configured and the default key
+// template. Secrets stored under any other key keep their ref.
+func accountSecretRefRenames(app *app, from, to domain.AccountID) map[string]string {
+	renames := make(map[string]string, 4)
+	for _, template := range []secretKeyTemplate{app.secretKeys, defaultSecretKeyTemplate} {
+		if template == "" {
+			continue
+		}
+		for _, kind := range []string{secretKindOAuthTokens, secretKindAPIKey} {
+			source := template.key(from, kind)
+			if _, ok := renames[source]; !ok {
+				renames[source] = template.key(to, kind)
+			}
+		}
+	}
+	return renames
+}
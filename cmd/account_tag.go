@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newAccountTagCmd(app *app) *cobra.Command {
+	var (
+		accountID string
+		add       []string
+		remove    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Add or remove account tags",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if len(add) == 0 && len(remove) == 0 {
+				return errors.New("at least one of --add or --remove is required")
+			}
+
+			account, err := app.service.TagAccount(cmd.Context(), domain.AccountID(accountID), add, remove)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Account %s tags: %s\n", account.ID, valueOrDash(strings.Join(account.Metadata.Tags, ", ")))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	cmd.Flags().StringSliceVar(&add, "add", nil, "Tags to add (comma-separated)")
+	cmd.Flags().StringSliceVar(&remove, "remove", nil, "Tags to remove (comma-separated)")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
 
+	cmdstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/cmd"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +19,7 @@ func newAuthCmd(app *app) *cobra.Command {
 		Short: "Manage account authentication",
 	}
 
-	cmd.AddCommand(newAuthSetCmd(app), newAuthRemoveCmd(app), newLoginCmd(app))
+	cmd.AddCommand(newAuthSetCmd(app), newAuthRemoveCmd(app), newAuthImportCodexCmd(app), newLoginCmd(app))
 
 	return cmd
 }
@@ -23,6 +29,8 @@ func newAuthSetCmd(app *app) *cobra.Command {
 	var method string
 	var secretKey string
 	var secretValue string
+	var provider string
+	var expiresIn int64
 
 	cmd := &cobra.Command{
 		Use:   "set",
@@ -32,49 +40,171 @@ func newAuthSetCmd(app *app) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if expiresIn < 0 {
+				return fmt.Errorf("--expires-in must not be negative, got %d", expiresIn)
+			}
+			if cmdstore.IsRef(secretKey) {
+				// The command supplies the secret on every read; only the
+				// ref is stored.
+				if _, err := cmdstore.ParseRef(secretKey); err != nil {
+					return err
+				}
+				if authMethod == "" {
+					return errors.New("--method is required with a cmd:// --secret-key")
+				}
+				if authMethod == domain.AuthMethodChatGPT {
+					// Refreshing rotates the refresh token, which could not
+					// be written back to a read-only ref.
+					return errors.New("--method chatgpt cannot use a cmd:// --secret-key: refreshed tokens could not be saved (use --method api_key)")
+				}
+				if cmd.Flags().Changed("secret-value") || expiresIn > 0 {
+					return errors.New("--secret-value and --expires-in cannot be combined with a cmd:// --secret-key")
+				}
+			} else {
+				if !cmd.Flags().Changed("secret-value") {
+					return errors.New(`required flag(s) "secret-value" not set (or pass a cmd:// --secret-key)`)
+				}
+				if authMethod == "" {
+					authMethod, err = detectAuthMethod(secretValue)
+					if err != nil {
+						return err
+					}
+				}
+				if expiresIn > 0 && authMethod != domain.AuthMethodChatGPT {
+					return errors.New("--expires-in only applies to --method chatgpt")
+				}
+				if err := validateSecretValue(authMethod, secretValue); err != nil {
+					return err
+				}
+				if authMethod == domain.AuthMethodChatGPT {
+					secretValue, err = stampTokenExpiry(secretValue, expiresIn, app.clock.Now())
+					if err != nil {
+						return err
+					}
+				}
+			}
+			resolvedProvider, err := parseProvider(cmd, provider)
+			if err != nil {
+				return err
+			}
 			resolvedAccountID, err := resolveAccountID(cmd.Context(), app, accountID)
 			if err != nil {
 				return err
 			}
+			if strings.TrimSpace(secretKey) == "" {
+				secretKey = app.secretKeys.key(resolvedAccountID, secretKindForMethod(authMethod))
+			}
 
-			return app.service.SetAuth(
+			if err := app.service.SetAuth(
 				cmd.Context(),
 				resolvedAccountID,
 				authMethod,
 				secretKey,
 				secretValue,
-			)
+			); err != nil {
+				return err
+			}
+
+			return applyAccountProvider(cmd.Context(), app, resolvedAccountID, resolvedProvider)
 		},
 	}
 
 	cmd.Flags().StringVar(&accountID, "account", "0", "Account ID (0 or empty auto-assigns next: 1,2,...)")
-	cmd.Flags().StringVar(&method, "method", "", "Auth method (api_key|chatgpt)")
-	cmd.Flags().StringVar(&secretKey, "secret-key", "", "Secret-store key")
-	cmd.Flags().StringVar(&secretValue, "secret-value", "", "Secret value")
-	_ = cmd.MarkFlagRequired("method")
-	_ = cmd.MarkFlagRequired("secret-key")
-	_ = cmd.MarkFlagRequired("secret-value")
+	cmd.Flags().StringVar(&method, "method", authMethodAuto, "Auth method (api_key|chatgpt|auto: token JSON is chatgpt, an sk- key is api_key)")
+	cmd.Flags().StringVar(&secretKey, "secret-key", "", "Secret-store key (default: from OA_SECRET_KEY_TEMPLATE, openai://{account}/{kind}); cmd://<command> reads the secret from the command's stdout instead")
+	cmd.Flags().StringVar(&secretValue, "secret-value", "", "Secret value (required unless --secret-key is a cmd:// ref)")
+	cmd.Flags().StringVar(&provider, "provider", string(domain.ProviderOpenAI), "Account provider")
+	cmd.Flags().Int64Var(&expiresIn, "expires-in", 0, "Access token lifetime in seconds, stamped as expires_at so proactive refresh works (chatgpt only)")
 
 	return cmd
 }
 
 func newAuthRemoveCmd(app *app) *cobra.Command {
 	var accountID string
+	var keepSecret bool
 
 	cmd := &cobra.Command{
 		Use:   "remove",
 		Short: "Remove account authentication",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if keepSecret {
+				return app.service.DetachAuth(cmd.Context(), domain.AccountID(accountID))
+			}
 			return app.service.RemoveAuth(cmd.Context(), domain.AccountID(accountID))
 		},
 	}
 
 	cmd.Flags().StringVar(&accountID, "account", "", "Account ID")
+	cmd.Flags().BoolVar(&keepSecret, "keep-secret", false, "Unlink the auth method but leave the stored secret in the secret store")
 	_ = cmd.MarkFlagRequired("account")
 
 	return cmd
 }
 
+// parseProvider validates --provider. It returns "" when the flag was not
+// given so applyAccountProvider leaves an existing provider alone.
+func parseProvider(cmd *cobra.Command, raw string) (string, error) {
+	if !cmd.Flags().Changed("provider") {
+		return "", nil
+	}
+	provider := strings.ToLower(strings.TrimSpace(raw))
+	if provider == "" {
+		return "", errors.New("--provider must not be empty")
+	}
+	if !domain.Provider(provider).Supported() {
+		return "", fmt.Errorf("unsupported provider %q (valid: %s, %s)", provider, domain.ProviderOpenAI, domain.ProviderAnthropic)
+	}
+	return provider, nil
+}
+
+// applyAccountProvider sets an explicit --provider, or tags the account with
+// the openai default when it has no provider yet.
+func applyAccountProvider(ctx context.Context, app *app, accountID domain.AccountID, provider string) error {
+	if provider == "" {
+		return app.service.DefaultAccountProvider(ctx, accountID, string(domain.ProviderOpenAI))
+	}
+	return app.service.SetAccountProvider(ctx, accountID, provider)
+}
+
+// stampTokenExpiry sets expires_at on pasted chatgpt tokens from expiresIn or,
+// when that is zero, from the JSON's own expires_in. Tokens that already carry
+// expires_at, or no lifetime at all, are returned unchanged.
+func stampTokenExpiry(secretValue string, expiresIn int64, now time.Time) (string, error) {
+	tokens, err := decodeOAuthTokens(secretValue)
+	if err != nil {
+		return "", err
+	}
+	if expiresIn > 0 {
+		tokens.ExpiresIn = expiresIn
+	} else if tokens.ExpiresAt > 0 || tokens.ExpiresIn <= 0 {
+		return secretValue, nil
+	}
+
+	return encodeOAuthTokens(withCalculatedExpiry(tokens, now))
+}
+
+func validateSecretValue(method domain.AuthMethod, value string) error {
+	switch method {
+	case domain.AuthMethodChatGPT:
+		if _, err := decodeOAuthTokens(value); err != nil {
+			return fmt.Errorf("invalid chatgpt secret value (expected token JSON with access_token): %w", err)
+		}
+	case domain.AuthMethodAPIKey:
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			return errors.New("api_key secret value must not be empty")
+		}
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			return errors.New("api_key secret value looks like JSON; use --method chatgpt for oauth token JSON")
+		}
+	}
+	return nil
+}
+
+// authMethodAuto asks auth set to infer the method from the secret value.
+const authMethodAuto = "auto"
+
+// parseAuthMethod returns an empty method for auto.
 func parseAuthMethod(raw string) (domain.AuthMethod, error) {
 	method := domain.AuthMethod(raw)
 	switch method {
@@ -82,7 +212,25 @@ func parseAuthMethod(raw string) (domain.AuthMethod, error) {
 		return method, nil
 	case domain.AuthMethodChatGPT:
 		return method, nil
+	case authMethodAuto:
+		return "", nil
 	default:
 		return "", fmt.Errorf("unsupported auth method %q", raw)
 	}
 }
+
+// detectAuthMethod infers the method from the shape of a secret value: token
+// JSON with an access_token is chatgpt, a single sk- word is an api key.
+func detectAuthMethod(value string) (domain.AuthMethod, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{") {
+		if _, err := decodeOAuthTokens(trimmed); err != nil {
+			return "", fmt.Errorf("cannot infer --method: secret value is JSON but not chatgpt tokens: %w", err)
+		}
+		return domain.AuthMethodChatGPT, nil
+	}
+	if strings.HasPrefix(trimmed, "sk-") && !strings.ContainsFunc(trimmed, unicode.IsSpace) {
+		return domain.AuthMethodAPIKey, nil
+	}
+	return "", errors.New("cannot infer --method from the secret value; pass --method api_key or --method chatgpt")
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// codexAuthFile mirrors ~/.codex/auth.json. Codex writes a single top-level
+// tokens object; multi-account setups keep additional entries under accounts.
+type codexAuthFile struct {
+	Tokens   *codexTokens               `json:"tokens"`
+	Accounts map[string]codexAuthRecord `json:"accounts"`
+}
+
+type codexAuthRecord struct {
+	Tokens *codexTokens `json:"tokens"`
+}
+
+type codexTokens struct {
+	IDToken      string `json:"id_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	AccountID    string `json:"account_id"`
+}
+
+type codexAuthEntry struct {
+	name   string
+	tokens codexTokens
+}
+
+func newAuthImportCodexCmd(app *app) *cobra.Command {
+	var accountID string
+	var codexAccount string
+	var path string
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "import-codex",
+		Short: "Import ChatGPT tokens from Codex auth.json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			resolvedProvider, err := parseProvider(cmd, provider)
+			if err != nil {
+				return err
+			}
+
+			if path == "" {
+				path, err = codexAuthPath()
+				if err != nil {
+					return err
+				}
+			}
+
+			entries, err := readCodexAuthEntries(path)
+			if err != nil {
+				return err
+			}
+			entry, err := selectCodexAuthEntry(entries, codexAccount)
+			if err != nil {
+				return err
+			}
+
+			resolvedAccountID, err := resolveAccountID(cmd.Context(), app, accountID)
+			if err != nil {
+				return err
+			}
+
+			secretValue, err := encodeOAuthTokens(oauthTokens{
+				AccessToken:  entry.tokens.AccessToken,
+				RefreshToken: entry.tokens.RefreshToken,
+				IDToken:      entry.tokens.IDToken,
+			})
+			if err != nil {
+				return err
+			}
+
+			secretKey := app.secretKeys.key(resolvedAccountID, secretKindOAuthTokens)
+			if err := app.service.SetAuth(cmd.Context(), resolvedAccountID, domain.AuthMethodChatGPT, secretKey, secretValue); err != nil {
+				return fmt.Errorf("save imported codex auth: %w", err)
+			}
+			if err := applyAccountProvider(cmd.Context(), app, resolvedAccountID, resolvedProvider); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Imported Codex account %s as account %s\n", entry.name, resolvedAccountID)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&accountID, "account", "0", "Account ID (0 or empty auto-assigns next: 1,2,...)")
+	cmd.Flags().StringVar(&codexAccount, "codex-account", "", "Codex account to import when auth.json holds several (name, account ID or email)")
+	cmd.Flags().StringVar(&path, "file", "", "Path to Codex auth.json (default: ~/.codex/auth.json)")
+	cmd.Flags().StringVar(&provider, "provider", string(domain.ProviderOpenAI), "Account provider")
+
+	return cmd
+}
+
+func codexAuthPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".codex", "auth.json"), nil
+}
+
+func readCodexAuthEntries(path string) ([]codexAuthEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read codex auth file: %w", err)
+	}
+
+	var file codexAuthFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode codex auth file: %w", err)
+	}
+
+	entries := make([]codexAuthEntry, 0, 1+len(file.Accounts))
+	if file.Tokens != nil && strings.TrimSpace(file.Tokens.AccessToken) != "" {
+		entries = append(entries, codexAuthEntry{name: codexEntryName("", *file.Tokens), tokens: *file.Tokens})
+	}
+
+	names := make([]string, 0, len(file.Accounts))
+	for name := range file.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		record := file.Accounts[name]
+		if record.Tokens == nil || strings.TrimSpace(record.Tokens.AccessToken) == "" {
+			continue
+		}
+		if isDuplicateCodexEntry(entries, *record.Tokens) {
+			continue
+		}
+		entries = append(entries, codexAuthEntry{name: codexEntryName(name, *record.Tokens), tokens: *record.Tokens})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no chatgpt tokens found in %s", path)
+	}
+	return entries, nil
+}
+
+func selectCodexAuthEntry(entries []codexAuthEntry, selector string) (codexAuthEntry, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		if len(entries) == 1 {
+			return entries[0], nil
+		}
+		return codexAuthEntry{}, fmt.Errorf("codex auth file holds %d accounts; choose one with --codex-account (%s)", len(entries), strings.Join(codexEntryNames(entries), ", "))
+	}
+
+	for _, entry := range entries {
+		if strings.EqualFold(entry.name, selector) ||
+			strings.EqualFold(codexTokenAccountID(entry.tokens), selector) ||
+			strings.EqualFold(parseTokenClaims(entry.tokens.IDToken).Email, selector) {
+			return entry, nil
+		}
+	}
+
+	return codexAuthEntry{}, fmt.Errorf("codex account %q not found (available: %s)", selector, strings.Join(codexEntryNames(entries), ", "))
+}
+
+func codexEntryName(key string, tokens codexTokens) string {
+	if key = strings.TrimSpace(key); key != "" {
+		return key
+	}
+	if email := parseTokenClaims(tokens.IDToken).Email; email != "" {
+		return email
+	}
+	if id := codexTokenAccountID(tokens); id != "" {
+		return id
+	}
+	return "default"
+}
+
+func codexTokenAccountID(tokens codexTokens) string {
+	if id := strings.TrimSpace(tokens.AccountID); id != "" {
+		return id
+	}
+	return accountIDFromToken(tokens.IDToken)
+}
+
+func isDuplicateCodexEntry(entries []codexAuthEntry, tokens codexTokens) bool {
+	for _, entry := range entries {
+		if entry.tokens.AccessToken == tokens.AccessToken {
+			return true
+		}
+	}
+	return false
+}
+
+func codexEntryNames(entries []codexAuthEntry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.name)
+	}
+	return names
+}
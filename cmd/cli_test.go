@@ -1,20 +1,33 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	tomlrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/toml"
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestAuthSetRequiresSecretValueFlag(t *testing.T) {
@@ -31,385 +44,679 @@ func TestAuthSetRequiresSecretValueFlag(t *testing.T) {
 	assert.Contains(t, err.Error(), "required flag(s) \"secret-value\" not set")
 }
 
-func TestStatusByAccountHappyPath(t *testing.T) {
+func TestAuthSetPersistsProviderAndJoinsDefaultPool(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
 
-	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "7",
+		"--method", "api_key",
+		"--secret-key", "openai://7/api_key",
+		"--secret-value", "sk-test",
+		"--provider", "OpenAI",
+	)
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "accounts: 1")
-	assert.Contains(t, stdout, "Primary (acc-1)")
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "provider = 'openai'")
+
+	stdout, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "members: 1")
 }
 
-func TestStatusByAccountJSONOutput(t *testing.T) {
+func TestAuthSetRejectsEmptyProvider(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
 
-	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1", "--json")
-	require.NoError(t, err)
-	assert.True(t, json.Valid([]byte(stdout)))
-	assert.Contains(t, stdout, "\"Account\"")
-	assert.Contains(t, stdout, "\"ID\": \"acc-1\"")
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "7",
+		"--method", "api_key",
+		"--secret-key", "openai://7/api_key",
+		"--secret-value", "sk-test",
+		"--provider", " ",
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--provider must not be empty")
 }
 
-func TestAuthSetThenStatusShowsAuthMethod(t *testing.T) {
+func TestAuthSetKeepsExistingProviderWithoutProviderFlag(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
 
 	_, _, err := executeCLI(t, home,
 		"auth", "set",
-		"--account", "acc-1",
+		"--account", "7",
 		"--method", "api_key",
-		"--secret-key", "openai://acc-1/api_key",
-		"--secret-value", "test-secret-value",
+		"--secret-key", "anthropic://7/api_key",
+		"--secret-value", "sk-ant-123",
+		"--provider", "anthropic",
 	)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
+	_, _, err = executeCLI(t, home,
+		"auth", "set",
+		"--account", "7",
+		"--method", "api_key",
+		"--secret-key", "anthropic://7/api_key",
+		"--secret-value", "sk-ant-456",
+	)
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Primary (acc-1)")
-}
 
-func TestAuthSetAutoAssignsNextNumericAccountID(t *testing.T) {
-	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "provider = 'anthropic'")
 
-	_, _, err := executeCLI(t, home,
+	_, _, err = executeCLI(t, home,
 		"auth", "set",
+		"--account", "8",
 		"--method", "api_key",
-		"--secret-key", "openai://1/api_key",
-		"--secret-value", "secret-1",
+		"--secret-key", "openai://8/api_key",
+		"--secret-value", "sk-test",
 	)
 	require.NoError(t, err)
 
+	stdout, _, err := executeCLI(t, home, "account", "list", "--columns", "id,provider")
+	require.NoError(t, err)
+	assert.Regexp(t, `7\s+anthropic`, stdout)
+	assert.Regexp(t, `8\s+openai`, stdout)
+
 	_, _, err = executeCLI(t, home,
 		"auth", "set",
+		"--account", "8",
 		"--method", "api_key",
-		"--secret-key", "openai://2/api_key",
-		"--secret-value", "secret-2",
+		"--secret-key", "openai://8/api_key",
+		"--secret-value", "sk-test",
+		"--provider", "gemini",
 	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported provider "gemini"`)
+}
+
+func TestSecretMigrateMovesFileSecretsIntoPass(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user1@example.com", "acct-1"))
+
+	passDir := filepath.Join(home, "fake-pass")
+	binsDir := filepath.Join(home, "bin")
+	require.NoError(t, os.MkdirAll(binsDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(binsDir, "pass"), []byte(strings.Join([]string{
+		"#!/bin/sh",
+		"store=" + passDir,
+		"case \"$1\" in",
+		"insert) mkdir -p \"$(dirname \"$store/$4\")\"; cat > \"$store/$4\" ;;",
+		"show) [ -f \"$store/$2\" ] || { echo \"$2 is not in the password store.\" >&2; exit 1; }; cat \"$store/$2\" ;;",
+		"rm) rm -f \"$store/$3\" ;;",
+		"esac",
+		"",
+	}, "\n")), 0o755))
+	t.Setenv("PATH", binsDir+":"+os.Getenv("PATH"))
+
+	filePath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://acc-1/oauth_tokens"))
+	original, err := os.ReadFile(filePath)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "status")
+	stdout, _, err := executeCLI(t, home, "secrets", "migrate", "--to", "pass")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Account 1 (1)")
-	assert.Contains(t, stdout, "Account 2 (2)")
+	assert.Contains(t, stdout, "Migrated openai://acc-1/oauth_tokens to pass")
+	assert.Contains(t, stdout, "Migrated 1 secret(s) to pass")
+
+	_, statErr := os.Stat(filePath)
+	assert.ErrorIs(t, statErr, os.ErrNotExist)
+
+	migrated, err := os.ReadFile(filepath.Join(passDir, "openai://acc-1/oauth_tokens"))
+	require.NoError(t, err)
+	assert.Equal(t, string(original)+"\n", string(migrated))
 }
 
-func TestLoginDeviceReturnsNotImplemented(t *testing.T) {
+func TestPlaintextFallbackWarningGoesToCommandStderr(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	_, _, err := executeCLI(t, home, "auth", "login", "device")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "not implemented yet")
+	binsDir := filepath.Join(home, "bin")
+	require.NoError(t, os.MkdirAll(binsDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(binsDir, "pass"), []byte(strings.Join([]string{
+		"#!/bin/sh",
+		"echo 'Error: password store is empty. Try \"pass init\".' >&2",
+		"exit 1",
+		"",
+	}, "\n")), 0o755))
+	t.Setenv("PATH", binsDir+":"+os.Getenv("PATH"))
+
+	_, stderr, err := executeCLI(t, home, "auth", "set", "--account", "7", "--method", "api_key", "--secret-value", "sk-test")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "warning: pass is installed but its store is not initialized")
 }
 
-func TestLimitCommandIsRemoved(t *testing.T) {
+func TestSecretMigrateRejectsUnknownBackend(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	_, _, err := executeCLI(t, home, "limit")
+	_, _, err := executeCLI(t, home, "secret", "migrate", "--to", "vault")
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "unknown command \"limit\"")
+	assert.Contains(t, err.Error(), "unknown secret backend \"vault\" (valid backends: pass, file)")
 }
 
-func TestAccountListShowsConfiguredAccounts(t *testing.T) {
+func TestSecretListReportsRefStatusWithoutValues(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	for _, id := range []string{"1", "2"} {
+		_, _, err := executeCLI(t, home, "auth", "set", "--account", id, "--method", "api_key", "--secret-value", "sk-value-"+id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Remove(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/api_key"))))
 
-	stdout, _, err := executeCLI(t, home, "account", "list")
+	stdout, _, err := executeCLI(t, home, "secret", "list")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "acc-1")
-	assert.Contains(t, stdout, "Primary")
+	assert.Contains(t, stdout, "ACCOUNT\tFIELD\tREF\tSTATUS\n")
+	assert.Contains(t, stdout, "1\tauth\topenai://1/api_key\tOK\n")
+	assert.Contains(t, stdout, "2\tauth\topenai://2/api_key\tMISSING\n")
+	assert.NotContains(t, stdout, "sk-value-")
+
+	stdout, _, err = executeCLI(t, home, "secret", "list", "--json")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "sk-value-")
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "1", entries[0]["account_id"])
+	assert.Equal(t, "auth", entries[0]["field"])
+	assert.Equal(t, "OK", entries[0]["status"])
+	assert.Equal(t, "openai://2/api_key", entries[1]["secret_ref"])
+	assert.Equal(t, "MISSING", entries[1]["status"])
+
+	_, _, err = executeCLI(t, home, "secret", "list", "--json", "--format", "yaml")
+	require.Error(t, err)
 }
 
-func TestUsageSetSubcommandIsRemoved(t *testing.T) {
+func TestSecretMigrateFromValidatesBackends(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	_, _, err := executeCLI(t, home, "usage", "set", "--account", "acc-1")
+	_, _, err := executeCLI(t, home, "secret", "migrate", "--from", "file", "--to", "file")
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "unknown command \"set\"")
-}
+	assert.Contains(t, err.Error(), "--from and --to are both file")
 
-func TestUsageCommandFetchesLimitsAndRendersStatus(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case r.URL.Path == "/wham/usage":
-			assert.Equal(t, "Bearer access-token-123", r.Header.Get("Authorization"))
-			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-		case r.URL.Path == "/subscriptions":
-			w.WriteHeader(http.StatusNotFound)
-		default:
-			w.WriteHeader(http.StatusNotFound)
-		}
-	}))
-	defer server.Close()
+	_, _, err = executeCLI(t, home, "secret", "migrate", "--to", "pass", "--delete-source")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--delete-source requires --from")
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	_, _, err = executeCLI(t, home, "secret", "migrate", "--from", "vault", "--to", "pass")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown secret backend \"vault\"")
+}
 
+func TestStatusByAccountHappyPath(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	_, _, err := executeCLI(t, home,
-		"auth", "set",
-		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
-	)
-	require.NoError(t, err)
-
-	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "5hours limit:")
-	assert.Contains(t, stdout, "weekly limit:")
-	assert.Contains(t, stdout, "79% left")
-	assert.Contains(t, stdout, "53% left")
+	assert.Contains(t, stdout, "accounts: 1")
+	assert.Contains(t, stdout, "Primary (acc-1)")
 }
 
-func TestStatusAliasFetchesLimitsAndRendersStatus(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case r.URL.Path == "/wham/usage":
-			assert.Equal(t, "Bearer access-token-123", r.Header.Get("Authorization"))
-			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-		case r.URL.Path == "/subscriptions":
-			w.WriteHeader(http.StatusNotFound)
-		default:
-			w.WriteHeader(http.StatusNotFound)
-		}
-	}))
-	defer server.Close()
+func TestStatusByAccountJSONOutput(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1", "--json")
+	require.NoError(t, err)
+	var view struct {
+		SchemaVersion int              `json:"schemaVersion"`
+		Accounts      []map[string]any `json:"accounts"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	assert.Equal(t, 1, view.SchemaVersion)
+	require.Len(t, view.Accounts, 1)
+	assert.Equal(t, "acc-1", view.Accounts[0]["id"])
+	assert.Contains(t, view.Accounts[0], "limits")
+	assert.NotContains(t, stdout, "\"Account\"")
+
+	stdout, _, err = executeCLI(t, home, "status", "--account", "acc-1", "--json-legacy")
+	require.NoError(t, err)
+	var legacy []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &legacy))
+	require.Len(t, legacy, 1)
+	assert.Equal(t, "acc-1", legacy[0]["Account"].(map[string]any)["ID"])
+}
 
+func TestAuthImportCodexCreatesChatGPTAccount(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, writeCodexAuthFixture(home))
 
-	_, _, err := executeCLI(t, home,
-		"auth", "set",
-		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
-	)
+	_, _, err := executeCLI(t, home, "auth", "import-codex")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "codex auth file holds 2 accounts")
+	assert.Contains(t, err.Error(), "personal@example.com, work")
+
+	stdout, _, err := executeCLI(t, home, "auth", "import-codex", "--account", "5", "--codex-account", "work@example.com")
 	require.NoError(t, err)
+	assert.Contains(t, stdout, "Imported Codex account work as account 5")
 
-	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "5hours limit:")
-	assert.Contains(t, stdout, "weekly limit:")
-	assert.Contains(t, stdout, "79% left")
-	assert.Contains(t, stdout, "53% left")
-}
+	assert.Contains(t, string(accounts), "openai://5/oauth_tokens")
+	assert.Contains(t, string(accounts), "chatgpt")
 
-func TestUsageCommandJSONOutput(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-	}))
-	defer server.Close()
+	secret, err := os.ReadFile(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://5/oauth_tokens")))
+	require.NoError(t, err)
+	tokens, err := decodeOAuthTokens(string(secret))
+	require.NoError(t, err)
+	assert.Equal(t, "access-work", tokens.AccessToken)
+	assert.Equal(t, "refresh-work", tokens.RefreshToken)
+}
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+func TestSecretKeyTemplateShapesStoredKeysAndRoundTrips(t *testing.T) {
+	t.Setenv("OA_SECRET_KEY_TEMPLATE", "codex/oa/accounts/{account}/{kind}")
 
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, writeCodexAuthFixture(home))
 
-	_, _, err := executeCLI(t, home,
+	_, _, err := executeCLI(t, home, "auth", "import-codex", "--account", "5", "--codex-account", "work@example.com")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home,
 		"auth", "set",
 		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+		"--method", "api_key",
+		"--secret-value", "sk-test-value",
 	)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--json")
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
 	require.NoError(t, err)
-	assert.True(t, json.Valid([]byte(stdout)))
-	assert.Contains(t, stdout, "\"DailyLimit\"")
-	assert.Contains(t, stdout, "\"WeeklyLimit\"")
-}
+	assert.Contains(t, string(accounts), "codex/oa/accounts/5/oauth_tokens")
+	assert.Contains(t, string(accounts), "codex/oa/accounts/acc-1/api_key")
+	assert.FileExists(t, filepath.Join(home, ".codex", "secrets", "codex", "oa", "accounts", "5", "oauth_tokens"))
 
-func TestUsageCommandShowsFetchingSpinnerMessage(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(200 * time.Millisecond)
-		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-	}))
-	defer server.Close()
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	secret, err := app.secretStore.Get(context.Background(), "codex/oa/accounts/5/oauth_tokens")
+	require.NoError(t, err)
+	tokens, err := decodeOAuthTokens(secret)
+	require.NoError(t, err)
+	assert.Equal(t, "access-work", tokens.AccessToken)
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	apiKey, err := app.secretStore.Get(context.Background(), "codex/oa/accounts/acc-1/api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-test-value", apiKey)
+}
 
+func TestDoctorFlagsAndFixesWorldReadableSecrets(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	require.NoError(t, os.Chmod(accountsPath, 0o600))
+	secretsDir := filepath.Join(home, ".codex", "secrets")
+	secretPath := filepath.Join(secretsDir, "openai:", "acc-1", "api_key")
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretPath), 0o700))
+	require.NoError(t, os.Chmod(secretsDir, 0o700))
+	require.NoError(t, os.WriteFile(secretPath, []byte("sk-test"), 0o600))
+	require.NoError(t, os.Chmod(secretPath, 0o644))
+
+	stdout, _, err := executeCLI(t, home, "doctor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 1 file(s) readable by other users")
+	assert.Contains(t, stdout, "insecure: "+secretPath+" has mode 0644 (expected 0600)")
+	assert.NotContains(t, stdout, accountsPath)
 
-	_, _, err := executeCLI(t, home,
-		"auth", "set",
-		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
-	)
+	stdout, _, err = executeCLI(t, home, "doctor", "--fix")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "fixed: "+secretPath+" mode 0644 -> 0600")
+	info, err := os.Stat(secretPath)
 	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
 
-	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	stdout, _, err = executeCLI(t, home, "doctor")
 	require.NoError(t, err)
-	assert.Contains(t, stderr, "Fetching usage limits")
+	assert.Contains(t, stdout, "ok: secret and config files are private")
 }
 
-func TestUsageCommandReturnsFetchError(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
-	}))
-	defer server.Close()
+func TestAccountListAndDoctorFlagRemovedSecret(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	for _, id := range []string{"1", "2"} {
+		_, _, err := executeCLI(t, home, "auth", "set", "--account", id, "--method", "api_key", "--secret-value", "sk-"+id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Remove(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/api_key"))))
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	stdout, _, err := executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1\tuser1@example.com\n")
+	assert.Contains(t, stdout, "2\tuser+alt@example.com\t(secret missing)\n")
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--format", "json")
+	require.NoError(t, err)
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0], "secret_missing")
+	assert.Equal(t, true, entries[1]["secret_missing"])
+
+	stdout, _, err = executeCLI(t, home, "doctor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 1 account(s) whose secret is missing")
+	assert.Contains(t, stdout, "secret missing: account 2 references openai://2/api_key")
+	assert.NotContains(t, stdout, "account 1 ")
+}
+
+func TestAccountListAndDoctorReportUnreadableSecretBackend(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	for _, id := range []string{"1", "2"} {
+		_, _, err := executeCLI(t, home, "auth", "set", "--account", id, "--method", "api_key", "--secret-value", "sk-"+id)
+		require.NoError(t, err)
+	}
+	accountDir := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2"))
+	require.NoError(t, os.RemoveAll(accountDir))
+	require.NoError(t, os.WriteFile(accountDir, []byte("not a directory"), 0o600))
+
+	_, _, err := executeCLI(t, home, "account", "list")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check secret of account 2")
+
+	stdout, _, err := executeCLI(t, home, "doctor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check secret of account 2")
+	assert.NotContains(t, stdout, "secret missing")
+}
 
+func TestGlobalDryRunAuthSetWritesNoFiles(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	before, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
 
-	_, _, err := executeCLI(t, home,
+	_, stderr, err := executeCLI(t, home,
+		"--dry-run",
 		"auth", "set",
 		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"bad-token","id_token":""}`,
+		"--method", "api_key",
+		"--secret-value", "sk-test-value",
 	)
 	require.NoError(t, err)
+	assert.Contains(t, stderr, "dry-run: would store secret openai://acc-1/api_key")
+	assert.Contains(t, stderr, "dry-run: would save account(s) acc-1 to "+accountsPath)
 
-	_, _, err = executeCLI(t, home, "usage", "--account", "acc-1")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "session expired")
-	assert.Contains(t, err.Error(), "oa auth login browser --account acc-1")
+	after, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.NoDirExists(t, filepath.Join(home, ".codex", "secrets"))
+	entries, err := os.ReadDir(filepath.Join(home, ".codex"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "dry-run must not create files next to accounts.toml")
 }
 
-func TestUsageCommandUpdatesAccountNameFromTokenEmail(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _ = fmt.Fprint(w, `{"plan_type":"team","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":30,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":10,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-	}))
-	defer server.Close()
-
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
-
+func TestAuthSetStoresCmdSecretRefWithoutValue(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	idToken := fakeJWT(`{"email":"email@adress.com"}`)
 	_, _, err := executeCLI(t, home,
 		"auth", "set",
 		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", fmt.Sprintf(`{"access_token":"ok-token","id_token":"%s"}`, idToken),
+		"--method", "api_key",
+		"--secret-key", "cmd://printf sk-from-cmd",
 	)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Account: email@adress.com (Team)")
-}
+	assert.Contains(t, string(accounts), "secret_ref = 'cmd://printf sk-from-cmd'")
+	assert.NoDirExists(t, filepath.Join(home, ".codex", "secrets"))
 
-func TestUsageCommandRefreshesExpiredAccessTokenAndRetries(t *testing.T) {
-	var oldTokenCalls int
-	var newTokenCalls int
-	var refreshCalls int
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case r.URL.Path == "/oauth/token":
-			refreshCalls++
-			require.Equal(t, http.MethodPost, r.Method)
-			require.NoError(t, r.ParseForm())
-			assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
-			assert.Equal(t, "test-client-id", r.Form.Get("client_id"))
-			assert.Equal(t, "refresh-token-123", r.Form.Get("refresh_token"))
-			_, _ = fmt.Fprint(w, `{"access_token":"new-token","refresh_token":"refresh-token-456","id_token":"","token_type":"Bearer","expires_in":3600}`)
-		case r.URL.Path == "/wham/usage":
-			authz := r.Header.Get("Authorization")
-			if authz == "Bearer old-token" {
-				oldTokenCalls++
-				w.WriteHeader(http.StatusUnauthorized)
-				_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
+	stdout, _, err := executeCLI(t, home, "account", "list", "--with-secret-backend")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "cmd")
+
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	secret, err := app.secretStore.Get(context.Background(), "cmd://printf sk-from-cmd")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-from-cmd", secret)
+
+	_, _, err = executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "api_key",
+		"--secret-key", "cmd://pass show key | cat",
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shell syntax")
+
+	_, _, err = executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "api_key",
+		"--secret-key", "cmd://printf sk-from-cmd",
+		"--secret-value", "sk-other",
+	)
+	require.Error(t, err)
+}
+
+func TestSecretKeyTemplateRequiresAccountPlaceholder(t *testing.T) {
+	t.Setenv("OA_SECRET_KEY_TEMPLATE", "codex/oa/{kind}")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "account", "list")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must contain {account}")
+}
+
+func TestMigrateCopiesConfigDirIntactWithPermissions(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	require.NoError(t, writePoolRuntimeFixture(home, "migrating"))
+	_, _, err = executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "api_key",
+		"--secret-key", "openai://acc-1/api_key",
+		"--secret-value", "sk-test-value",
+	)
+	require.NoError(t, err)
+
+	source := filepath.Join(home, ".codex")
+	target := filepath.Join(home, "config", "oa")
+	stdout, _, err := executeCLI(t, home, "migrate", "--to", target)
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Copied 4 file(s)")
+	assert.Contains(t, stdout, "export OA_CONFIG_DIR="+target)
+
+	for _, rel := range []string{
+		"accounts.toml",
+		"pools.toml",
+		"pool_runtime.toml",
+		filepath.Join("secrets", filepath.Clean("openai://acc-1/api_key")),
+	} {
+		want, err := os.ReadFile(filepath.Join(source, rel))
+		require.NoError(t, err, rel)
+		got, err := os.ReadFile(filepath.Join(target, rel))
+		require.NoError(t, err, rel)
+		assert.Equal(t, string(want), string(got), rel)
+
+		wantInfo, err := os.Stat(filepath.Join(source, rel))
+		require.NoError(t, err)
+		gotInfo, err := os.Stat(filepath.Join(target, rel))
+		require.NoError(t, err)
+		assert.Equal(t, wantInfo.Mode().Perm(), gotInfo.Mode().Perm(), rel)
+	}
+	entries, err := os.ReadDir(filepath.Dir(target))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "staging directory left behind")
+
+	t.Setenv("OA_CONFIG_DIR", target)
+	stdout, _, err = executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "acc-1")
+
+	_, _, err = executeCLI(t, home, "migrate", "--to", target)
+	require.Error(t, err)
+}
+
+func TestMigrateRefusesNonEmptyTarget(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	target := filepath.Join(home, "occupied")
+	require.NoError(t, os.MkdirAll(target, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(target, "keep.txt"), []byte("x"), 0o600))
+
+	_, _, err := executeCLI(t, home, "migrate", "--to", target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not empty")
+	data, err := os.ReadFile(filepath.Join(target, "keep.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(data))
+}
+
+func TestAuthSetValidatesSecretValueForMethod(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	tests := []struct {
+		name    string
+		method  string
+		value   string
+		wantErr string
+	}{
+		{name: "chatgpt token json", method: "chatgpt", value: `{"access_token":"access-token","refresh_token":"refresh"}`},
+		{name: "chatgpt bare token", method: "chatgpt", value: "eyJhbGciOi.bare.token", wantErr: "invalid chatgpt secret value"},
+		{name: "chatgpt json without access token", method: "chatgpt", value: `{"refresh_token":"refresh"}`, wantErr: "missing access_token"},
+		{name: "api key", method: "api_key", value: "sk-test-123"},
+		{name: "api key blank", method: "api_key", value: "   ", wantErr: "api_key secret value must not be empty"},
+		{name: "api key json", method: "api_key", value: `{"access_token":"x"}`, wantErr: "looks like JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := executeCLI(t, home,
+				"auth", "set",
+				"--account", "acc-1",
+				"--method", tt.method,
+				"--secret-key", "openai://acc-1/"+tt.method,
+				"--secret-value", tt.value,
+			)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
 				return
 			}
-			assert.Equal(t, "Bearer new-token", authz)
-			newTokenCalls++
-			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}}}`)
-		case r.URL.Path == "/subscriptions":
-			w.WriteHeader(http.StatusNotFound)
-		default:
-			w.WriteHeader(http.StatusNotFound)
-		}
-	}))
-	defer server.Close()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
 
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
-	t.Setenv("OA_AUTH_ISSUER", server.URL)
-	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+func TestConfigEditKeepsOriginalWhenEditorWritesInvalidTOML(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	original, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\nprintf 'version = [broken' > \"$1\"\n"), 0o755))
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	_, _, err = executeCLI(t, home, "config", "edit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "edited accounts file is invalid")
+	assert.Contains(t, err.Error(), "kept the previous version")
+
+	after, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(after))
+	leftovers, err := filepath.Glob(filepath.Join(home, ".codex", ".accounts-edit-*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\nprintf 'version = 2\\n' > \"$1\"\n"), 0o755))
+	_, _, err = executeCLI(t, home, "config", "edit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported accounts schema version 2")
+
+	after, err = os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(after))
+}
+
+func TestConfigEditKeepsValidEdits(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte(strings.Join([]string{
+		"#!/bin/sh",
+		"grep -q Primary \"$1\" || exit 1",
+		"printf 'version = 1\\n\\n[[accounts]]\\nid = \"acc-1\"\\nname = \"Renamed\"\\n' > \"$1\"",
+		"",
+	}, "\n")), 0o755))
+	t.Setenv("VISUAL", editor)
+
+	stdout, _, err := executeCLI(t, home, "config", "edit")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Saved "+accountsPath)
 
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Renamed")
+}
+
+func TestAuthSetThenStatusShowsAuthMethod(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
 	_, _, err := executeCLI(t, home,
 		"auth", "set",
 		"--account", "acc-1",
-		"--method", "chatgpt",
-		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"old-token","refresh_token":"refresh-token-123","id_token":"","expires_at":1}`,
+		"--method", "api_key",
+		"--secret-key", "openai://acc-1/api_key",
+		"--secret-value", "test-secret-value",
 	)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
 	require.NoError(t, err)
-	assert.LessOrEqual(t, oldTokenCalls, 1)
-	assert.GreaterOrEqual(t, refreshCalls, 1)
-	assert.GreaterOrEqual(t, newTokenCalls, 1)
-	assert.Contains(t, stdout, "5hours limit:")
+	assert.Contains(t, stdout, "Primary (acc-1)")
 }
 
-func TestUsageCommandExpiredErrorIncludesEmailAndType(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
-	}))
-	defer server.Close()
-
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+func TestAuthSetExpiresInStampsTokenExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pinClock(t, now)
 
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	idToken := fakeJWT(`{"email":"email@adress.com"}`)
 	_, _, err := executeCLI(t, home,
 		"auth", "set",
 		"--account", "acc-1",
 		"--method", "chatgpt",
 		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", fmt.Sprintf(`{"access_token":"bad-token","id_token":"%s"}`, idToken),
+		"--secret-value", `{"access_token":"access-token-123","refresh_token":"refresh-123"}`,
+		"--expires-in", "3600",
 	)
 	require.NoError(t, err)
 
-	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "account acc-1 (email@adress.com, Unknown): session expired")
-	assert.Contains(t, stderr, "account acc-1 (email@adress.com, Unknown): session expired")
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://acc-1/oauth_tokens")))
+	require.NoError(t, err)
+	tokens, err := decodeOAuthTokens(string(data))
+	require.NoError(t, err)
+	assert.Equal(t, "access-token-123", tokens.AccessToken)
+	assert.Equal(t, now.Add(time.Hour).Unix(), tokens.ExpiresAt)
 }
 
-func TestUsageCommandFetchesSubscriptionAndRendersRenewal(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case r.URL.Path == "/wham/usage":
-			_, _ = fmt.Fprint(w, `{"plan_type":"plus","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
-		case r.URL.Path == "/subscriptions":
-			_, _ = fmt.Fprint(w, `{"plan_type":"plus","active_start":"2026-02-14T07:41:19Z","active_until":"2026-03-14T07:41:19Z","will_renew":true,"billing_period":"monthly","billing_currency":"EUR","is_delinquent":false}`)
-		default:
-			w.WriteHeader(http.StatusNotFound)
-		}
-	}))
-	defer server.Close()
-
-	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+func TestAuthSetStampsExpiryFromPastedExpiresIn(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pinClock(t, now)
 
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
@@ -419,169 +726,2830 @@ func TestUsageCommandFetchesSubscriptionAndRendersRenewal(t *testing.T) {
 		"--account", "acc-1",
 		"--method", "chatgpt",
 		"--secret-key", "openai://acc-1/oauth_tokens",
-		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+		"--secret-value", `{"access_token":"access-token-123","expires_in":600}`,
 	)
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://acc-1/oauth_tokens")))
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "renewal:")
-	assert.Contains(t, stdout, "renews in")
-	assert.Contains(t, stdout, "14 Mar")
+	tokens, err := decodeOAuthTokens(string(data))
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(10*time.Minute).Unix(), tokens.ExpiresAt)
 }
 
-func TestRootAndRunHelpStayConcise(t *testing.T) {
+func TestAuthSetRejectsExpiresInForAPIKey(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
 
-	rootOut, _, err := executeCLI(t, home, "--help")
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "api_key",
+		"--secret-key", "openai://acc-1/api_key",
+		"--secret-value", "test-secret-value",
+		"--expires-in", "3600",
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--expires-in only applies to --method chatgpt")
+}
+
+func TestAuthSetInfersMethodFromSecretValue(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "auth", "set", "--account", "1", "--secret-value", `{"access_token":"access-1"}`)
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "auth", "set", "--account", "2", "--method", "auto", "--secret-value", "sk-test-value")
 	require.NoError(t, err)
-	assert.Contains(t, rootOut, "oa (OpenAI Accounts CLI) helps you store account auth references")
-	assert.NotContains(t, rootOut, "pool-selected account/session environment")
 
-	runOut, _, err := executeCLI(t, home, "run", "--help")
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
 	require.NoError(t, err)
-	assert.Contains(t, runOut, "Run a command with pool-selected account env")
-	assert.NotContains(t, runOut, "OA_LOGICAL_SESSION_ID")
+	assert.Contains(t, string(accounts), "method = 'chatgpt'\nsecret_ref = 'openai://1/oauth_tokens'")
+	assert.Contains(t, string(accounts), "method = 'api_key'\nsecret_ref = 'openai://2/api_key'")
+
+	// An explicit method wins over the value's shape.
+	_, _, err = executeCLI(t, home, "auth", "set", "--account", "2", "--method", "api_key", "--secret-value", "legacy-key")
+	require.NoError(t, err)
+
+	for _, value := range []string{"legacy-key", `{"token":"x"}`, "sk-two words"} {
+		_, _, err = executeCLI(t, home, "auth", "set", "--account", "2", "--secret-value", value)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), "cannot infer --method", value)
+	}
+
+	_, _, err = executeCLI(t, home, "auth", "set", "--account", "2", "--secret-key", "cmd://printf sk-from-cmd")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--method is required with a cmd:// --secret-key")
+
+	_, _, err = executeCLI(t, home, "auth", "set", "--account", "2", "--method", "chatgpt", "--secret-key", "cmd://pass show openai/tokens")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--method chatgpt cannot use a cmd:// --secret-key")
 }
 
-func TestPoolActivateCreatesDefaultPool(t *testing.T) {
+func TestAuthSetAutoAssignsNextNumericAccountID(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--method", "api_key",
+		"--secret-key", "openai://1/api_key",
+		"--secret-value", "secret-1",
+	)
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home,
+		"auth", "set",
+		"--method", "api_key",
+		"--secret-key", "openai://2/api_key",
+		"--secret-value", "secret-2",
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "status")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account 1 (1)")
+	assert.Contains(t, stdout, "Account 2 (2)")
+}
+
+func TestLoginDeviceStoresPolledTokens(t *testing.T) {
+	pinned := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pinClock(t, pinned)
+	idToken := fakeJWT(`{"email":"user1@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct-1"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-1", r.PostForm.Get("client_id"))
+		switch r.URL.Path {
+		case "/device/code":
+			_, _ = fmt.Fprint(w, `{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"https://auth.example/device","interval":1}`)
+		case "/device/token":
+			assert.Equal(t, "device-1", r.PostForm.Get("device_code"))
+			_, _ = fmt.Fprintf(w, `{"access_token":"access-1","refresh_token":"refresh-1","id_token":%q,"token_type":"Bearer","expires_in":3600}`, idToken)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "client-1")
+	t.Setenv("OA_AUTH_DEVICE_CODE_PATH", "/device/code")
+	t.Setenv("OA_AUTH_TOKEN_PATH", "/device/token")
+
+	stdout, _, err := executeCLI(t, home, "auth", "login", "device", "--timeout", "5s")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "https://auth.example/device")
+	assert.Contains(t, stdout, "enter code: ABCD-EFGH")
+	assert.Contains(t, stdout, "Authenticated account 1")
+
+	secret, err := os.ReadFile(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://1/oauth_tokens")))
+	require.NoError(t, err)
+	var stored map[string]any
+	require.NoError(t, json.Unmarshal(secret, &stored))
+	assert.Equal(t, "access-1", stored["access_token"])
+	assert.Equal(t, idToken, stored["id_token"])
+	assert.Equal(t, float64(pinned.Add(time.Hour).Unix()), stored["expires_at"])
+
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(accounts), "method = 'chatgpt'")
+	assert.Contains(t, string(accounts), "provider = 'openai'")
+}
+
+func TestLoginDeviceReportsRejectedAuthorization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/device/code" {
+			_, _ = fmt.Fprint(w, `{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"https://auth.example/device"}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, `{"error":"access_denied"}`)
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+
+	_, _, err := executeCLI(t, home, "auth", "login", "device", "--account", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "poll device token: request token: access_denied")
+}
+
+func TestLoginBrowserTimeoutReportsMissingCallback(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("OA_AUTH_LISTEN", "127.0.0.1:0")
+
+	stdout, _, err := executeCLI(t, home, "auth", "login", "browser", "--account", "acc-1", "--timeout", "50ms")
+	require.Error(t, err)
+	assert.Contains(t, stdout, "Open this URL to authenticate account acc-1")
+	assert.Contains(t, err.Error(), "no login callback received within 50ms")
+	assert.Contains(t, err.Error(), "timed out waiting for oauth callback")
+}
+
+func TestLoginBrowserRejectsSecondConcurrentLoginForSameAccount(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("HOME", home)
+	t.Setenv("OA_AUTH_LISTEN", "127.0.0.1:0")
+
+	first, release := newRootCmd(testClock(t))
+	defer release()
+	outReader, outWriter := io.Pipe()
+	first.SetOut(outWriter)
+	first.SetErr(io.Discard)
+	first.SetArgs([]string{"auth", "login", "browser", "--account", "acc-1", "--timeout", "500ms"})
+
+	done := make(chan error, 1)
+	go func() {
+		err := first.Execute()
+		_ = outWriter.Close()
+		done <- err
+	}()
+
+	// The first login prints the URL only after taking the lock.
+	scanner := bufio.NewScanner(outReader)
+	require.True(t, scanner.Scan())
+	go func() {
+		_, _ = io.Copy(io.Discard, outReader)
+	}()
+
+	_, _, err := executeCLI(t, home, "auth", "login", "browser", "--account", "acc-1", "--timeout", "50ms")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "login already in progress for acc-1")
+
+	firstErr := <-done
+	require.Error(t, firstErr)
+	assert.Contains(t, firstErr.Error(), "no login callback received")
+
+	_, _, err = executeCLI(t, home, "auth", "login", "browser", "--account", "acc-1", "--timeout", "50ms")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "already in progress")
+}
+
+func TestLoginBrowserAdvertisesConfiguredRedirectURI(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("OA_AUTH_LISTEN", "127.0.0.1:0")
+	t.Setenv("OA_AUTH_REDIRECT_HOST", "127.0.0.1")
+	t.Setenv("OA_AUTH_REDIRECT_PATH", "/oauth/done")
+
+	stdout, _, err := executeCLI(t, home, "auth", "login", "browser", "--account", "acc-1", "--timeout", "50ms")
+	require.Error(t, err)
+
+	var redirectURI *url.URL
+	for _, line := range strings.Split(stdout, "\n") {
+		if parsed, parseErr := url.Parse(line); parseErr == nil && parsed.Query().Get("redirect_uri") != "" {
+			redirectURI, err = url.Parse(parsed.Query().Get("redirect_uri"))
+			require.NoError(t, err)
+		}
+	}
+	require.NotNil(t, redirectURI)
+	assert.Equal(t, "127.0.0.1", redirectURI.Hostname())
+	assert.Equal(t, "/oauth/done", redirectURI.Path)
+}
+
+func TestLoginAndOpencodeSyncShareThePinnedClockForExpiry(t *testing.T) {
+	pinned := time.Date(2031, 5, 6, 7, 8, 9, 0, time.UTC)
+	pinClock(t, pinned)
+
+	idToken := fakeJWT(`{"email":"user1@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct-1"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"access_token":"access-1","refresh_token":"refresh-1","id_token":%q,"token_type":"Bearer","expires_in":3600}`, idToken)
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_LISTEN", "127.0.0.1:0")
+
+	root, release := newRootCmd(testClock(t))
+	defer release()
+	outReader, outWriter := io.Pipe()
+	root.SetOut(outWriter)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"auth", "login", "browser", "--timeout", "5s"})
+
+	done := make(chan error, 1)
+	go func() {
+		err := root.Execute()
+		_ = outWriter.Close()
+		done <- err
+	}()
+
+	scanner := bufio.NewScanner(outReader)
+	var authURL *url.URL
+	for authURL == nil && scanner.Scan() {
+		if parsed, err := url.Parse(scanner.Text()); err == nil && parsed.Query().Get("redirect_uri") != "" {
+			authURL = parsed
+		}
+	}
+	require.NotNil(t, authURL)
+	go func() {
+		_, _ = io.Copy(io.Discard, outReader)
+	}()
+
+	callback := authURL.Query().Get("redirect_uri") + "?code=code-1&state=" + url.QueryEscape(authURL.Query().Get("state"))
+	resp, err := http.Get(callback)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.NoError(t, <-done)
+
+	wantExpiresAt := pinned.Add(time.Hour).Unix()
+	secret, err := os.ReadFile(filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://1/oauth_tokens")))
+	require.NoError(t, err)
+	var stored map[string]any
+	require.NoError(t, json.Unmarshal(secret, &stored))
+	assert.Equal(t, float64(wantExpiresAt), stored["expires_at"])
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
+
+	auth := readOpencodeAuthFixture(t, home)
+	assert.Equal(t, float64(wantExpiresAt*1000), auth["openai"].(map[string]any)["expires"])
+}
+
+func TestLoginBrowserRejectsNonPositiveTimeout(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "auth", "login", "browser", "--timeout", "0s")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--timeout must be positive")
+
+	_, _, err = executeCLI(t, home, "auth", "login", "browser", "--timeout", "2h")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--timeout must be less than 1h0m0s")
+}
+
+func TestLimitCommandIsRemoved(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "limit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown command \"limit\"")
+}
+
+func TestAccountListShowsConfiguredAccounts(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	stdout, _, err := executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "acc-1")
+	assert.Contains(t, stdout, "Primary")
+}
+
+func TestAccountRenameSetsFriendlyName(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	stdout, _, err := executeCLI(t, home, "account", "rename", "--account", "acc-1", "--name", "Work key")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account acc-1 name: Work key")
+
+	stdout, _, err = executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "acc-1\tWork key")
+
+	_, _, err = executeCLI(t, home, "account", "rename", "--account", "acc-1", "--name", "  ")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account name must not be empty")
+
+	_, _, err = executeCLI(t, home, "account", "rename", "--account", "acc-1", "--name", "Work", "Other")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--name cannot be combined with a name argument")
+
+	_, _, err = executeCLI(t, home, "account", "rename", "--account", "missing", "--name", "Work")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	assert.Equal(t, "account 'missing' not found (run oa account list)", err.Error())
+	assert.Equal(t, exitCodeAccountNotFound, ExitCode(err))
+}
+
+func TestAccountSetIDMovesSecretsPoolsAndRuntime(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+	require.NoError(t, writePoolRuntimeFixture(home, "kept-memory"))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "pools.toml"), []byte(strings.Join([]string{
+		"version = 1",
+		"",
+		"[[pools]]",
+		"id = \"team\"",
+		"name = \"team\"",
+		"provider = \"openai\"",
+		"strategy = \"least_weekly_used\"",
+		"active = true",
+		"auto_sync_members = false",
+		"members = [\"2\", \"1\"]",
+		"",
+	}, "\n")), 0o600))
+	secretPath := func(ref string) string {
+		return filepath.Join(home, ".codex", "secrets", filepath.Clean(ref))
+	}
+	before, err := os.ReadFile(secretPath("openai://1/oauth_tokens"))
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "account", "set-id", "--from", "1", "--to", "work")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account 1 is now work")
+	assert.Contains(t, stdout, "Moved secret openai://1/oauth_tokens to openai://work/oauth_tokens")
+	assert.Contains(t, stdout, "Updated pool team")
+	assert.Contains(t, stdout, "Updated runtime of pool default-openai")
+
+	after, err := os.ReadFile(secretPath("openai://work/oauth_tokens"))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.NoFileExists(t, secretPath("openai://1/oauth_tokens"))
+
+	stdout, _, err = executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "work\tuser1@example.com"), lines[0])
+
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(accounts), "secret_ref = 'openai://work/oauth_tokens'")
+	assert.NotContains(t, string(accounts), "openai://1/")
+
+	pools, err := os.ReadFile(filepath.Join(home, ".codex", "pools.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(pools), "members = ['2', 'work']")
+
+	runtime, err := os.ReadFile(filepath.Join(home, ".codex", "pool_runtime.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(runtime), "active_account_id = 'work'")
+	assert.Contains(t, string(runtime), "account_id = 'work'")
+	assert.Contains(t, string(runtime), "session_id = 'sess-1'")
+	assert.Contains(t, string(runtime), "kept-memory")
+
+	_, _, err = executeCLI(t, home, "account", "set-id", "--from", "work", "--to", "2")
+	require.ErrorIs(t, err, domain.ErrAccountExists)
+	assert.FileExists(t, secretPath("openai://work/oauth_tokens"))
+
+	_, _, err = executeCLI(t, home, "account", "set-id", "--from", "missing", "--to", "3")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	assert.Equal(t, exitCodeAccountNotFound, ExitCode(err))
+}
+
+func TestAccountRemoveDeletesAccountAndSecret(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	_, _, err := executeCLI(t, home, "auth", "set", "--account", "2", "--method", "api_key", "--secret-value", "sk-2")
+	require.NoError(t, err)
+	secretPath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/api_key"))
+	require.FileExists(t, secretPath)
+
+	stdout, _, err := executeCLI(t, home, "account", "remove", "--account", "2")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Removed account 2")
+	assert.NoFileExists(t, secretPath)
+
+	stdout, _, err = executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	assert.Equal(t, "1\tuser1@example.com\n", stdout)
+
+	_, _, err = executeCLI(t, home, "account", "remove", "--account", "2")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	assert.Equal(t, "account '2' not found (run oa account list)", err.Error())
+}
+
+func TestAccountRemoveDropsPoolMembershipsAndRuntime(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writePoolRuntimeFixture(home, "kept-memory"))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "pools.toml"), []byte(strings.Join([]string{
+		"version = 1",
+		"",
+		"[[pools]]",
+		"id = \"team\"",
+		"name = \"team\"",
+		"provider = \"openai\"",
+		"strategy = \"least_weekly_used\"",
+		"active = true",
+		"auto_sync_members = false",
+		"members = [\"2\", \"1\"]",
+		"",
+	}, "\n")), 0o600))
+
+	stdout, _, err := executeCLI(t, home, "account", "remove", "--account", "1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Removed account 1")
+	assert.Contains(t, stdout, "Updated pool team")
+	assert.Contains(t, stdout, "Updated runtime of pool default-openai")
+
+	pools, err := os.ReadFile(filepath.Join(home, ".codex", "pools.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(pools), "members = ['2']")
+
+	runtime, err := os.ReadFile(filepath.Join(home, ".codex", "pool_runtime.toml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(runtime), "active_account_id = '1'")
+	assert.NotContains(t, string(runtime), "sess-1")
+	assert.Contains(t, string(runtime), "kept-memory")
+}
+
+func TestAccountListSelectsColumns(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--columns", "name,id,weekly")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "NAME\tID\tWEEKLY", lines[0])
+	assert.Equal(t, "user1@example.com\t1\t-", lines[1])
+	assert.Equal(t, "user+alt@example.com\t2\t-", lines[2])
+}
+
+func TestAccountListJSONIncludesStoredSubscription(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	path := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	subscription := `[accounts.subscription]
+active_start = "2026-02-14T07:41:19Z"
+active_until = "2026-03-14T07:41:19Z"
+will_renew = true
+billing_period = "monthly"
+billing_currency = "EUR"
+is_delinquent = false
+captured_at = "2026-03-01T09:00:00Z"
+
+[[accounts]]
+id = "2"`
+	updated := strings.Replace(string(data), "[[accounts]]\nid = \"2\"", subscription, 1)
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0o600))
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--format", "json")
+	require.NoError(t, err)
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]any{
+		"active_start":     "2026-02-14T07:41:19Z",
+		"active_until":     "2026-03-14T07:41:19Z",
+		"will_renew":       true,
+		"billing_period":   "monthly",
+		"billing_currency": "EUR",
+		"is_delinquent":    false,
+	}, entries[0]["subscription"])
+	assert.NotContains(t, entries[1], "subscription")
+}
+
+func TestAccountListEmailOnlyPrintsOnlyEmails(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--email-only")
+	require.NoError(t, err)
+	assert.Equal(t, "user1@example.com\nuser+alt@example.com\n", stdout)
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--email-only", "--account", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "user+alt@example.com\n", stdout)
+}
+
+func TestAccountListCountRespectsFilters(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", now.Add(-time.Hour)))
+	path := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "percent = 40.0", "percent = 100.0", 1)), 0o600))
+	pinClock(t, now)
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--count")
+	require.NoError(t, err)
+	assert.Equal(t, "2\n", stdout)
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--count", "--exhausted")
+	require.NoError(t, err)
+	assert.Equal(t, "1\n", stdout)
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--count", "--account", "1", "--exhausted")
+	require.NoError(t, err)
+	assert.Equal(t, "0\n", stdout)
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--exhausted")
+	require.NoError(t, err)
+	assert.Equal(t, "2\tuser+alt@example.com\n", stdout)
+
+	_, _, err = executeCLI(t, home, "account", "list", "--count", "--format", "json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--count cannot be combined")
+}
+
+func TestAccountListShowsAndSortsByLastFetched(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", now.Add(-2*time.Hour)))
+	pinClock(t, now)
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--columns", "id,last-fetched")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "ID\tLAST FETCHED", lines[0])
+	assert.Equal(t, "1\tnever", lines[1])
+	assert.Equal(t, "2\t2h ago", lines[2])
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--columns", "id,last-fetched", "--sort", "last-fetched")
+	require.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "2\t2h ago", lines[1])
+	assert.Equal(t, "1\tnever", lines[2])
+
+	_, _, err = executeCLI(t, home, "account", "list", "--sort", "weekly")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported sort \"weekly\"")
+}
+
+func TestAccountListWithSecretBackendFlagsPlaintextFileSecrets(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user@example.com", "acct-1"))
+	t.Setenv("PATH", t.TempDir())
+
+	stdout, _, err := executeCLI(t, home, "account", "list", "--columns", "id", "--with-secret-backend")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "ID\tBACKEND")
+	assert.Contains(t, stdout, "acc-1\tfile (plaintext)")
+
+	stdout, _, err = executeCLI(t, home, "--dry-run", "account", "list", "--columns", "id", "--with-secret-backend")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "acc-1\tfile (plaintext)")
+}
+
+func TestAccountListRejectsUnknownColumn(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "account", "list", "--columns", "id,email")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown column \"email\"")
+	assert.Contains(t, err.Error(), "id, name, plan, weekly, daily, expiry")
+}
+
+func TestAccountListMarksAndFiltersActiveAccount(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "account", "list")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "1\tuser1@example.com", lines[0])
+	assert.Equal(t, "2\tuser+alt@example.com\t(active)", lines[1])
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--active")
+	require.NoError(t, err)
+	assert.Equal(t, "2\tuser+alt@example.com\t(active)", strings.TrimSpace(stdout))
+}
+
+func TestAccountListActiveFailsWithoutActiveAccount(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "account", "list", "--active")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no active account in pool default-openai")
+}
+
+func TestUsageSetSubcommandIsRemoved(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "usage", "set", "--account", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown command \"set\"")
+}
+
+func TestUsageHistorySinceKeepsOnlyEntriesInWindow(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, writeAccountsFixture(home))
+
+	now := time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	for day := 13; day >= 0; day-- {
+		capturedAt := now.AddDate(0, 0, -day)
+		require.NoError(t, app.service.SetLimit(t.Context(), "acc-1", application.LimitWindowWeekly, float64(50-day), capturedAt.Add(72*time.Hour), capturedAt))
+	}
+	pinClock(t, now)
+
+	stdout, _, err := executeCLI(t, home, "usage", "history", "--account", "acc-1", "--since", "7d")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, "2026-03-08 09:00 UTC  weekly   43% used", lines[0])
+	assert.Equal(t, "2026-03-15 09:00 UTC  weekly   50% used", lines[7])
+
+	stdout, _, err = executeCLI(t, home, "usage", "history", "--account", "acc-1", "--until", "2026-03-03", "--format", "json")
+	require.NoError(t, err)
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "2026-03-02T09:00:00Z", entries[0]["captured_at"])
+
+	_, _, err = executeCLI(t, home, "usage", "history", "--account", "acc-1", "--since", "last week")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --since")
+}
+
+func TestUsageCommandFetchesLimitsAndRendersStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wham/usage":
+			assert.Equal(t, "Bearer access-token-123", r.Header.Get("Authorization"))
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+		case r.URL.Path == "/subscriptions":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "5hours limit:")
+	assert.Contains(t, stdout, "weekly limit:")
+	assert.Contains(t, stdout, "79% left")
+	assert.Contains(t, stdout, "53% left")
+}
+
+func TestStatusAliasFetchesLimitsAndRendersStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wham/usage":
+			assert.Equal(t, "Bearer access-token-123", r.Header.Get("Authorization"))
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+		case r.URL.Path == "/subscriptions":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "status", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "5hours limit:")
+	assert.Contains(t, stdout, "weekly limit:")
+	assert.Contains(t, stdout, "79% left")
+	assert.Contains(t, stdout, "53% left")
+}
+
+func TestUsageCommandJSONOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--json")
+	require.NoError(t, err)
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 1)
+	require.NotNil(t, view.Accounts[0].Limits.Daily)
+	require.NotNil(t, view.Accounts[0].Limits.Weekly)
+	assert.Equal(t, 21.0, view.Accounts[0].Limits.Daily.PercentUsed)
+	assert.Equal(t, 47.0, view.Accounts[0].Limits.Weekly.PercentUsed)
+}
+
+func TestUsageCommandShowsFetchingSpinnerMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Fetching usage limits")
+}
+
+func TestUsageSpinnerLabelAndDisable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1", "--fetch", "--spinner-label", "Checking quotas", "--spinner-style", "line")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Checking quotas")
+	assert.NotContains(t, stderr, "Fetching usage limits")
+
+	stdout, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1", "--fetch", "--no-spinner")
+	require.NoError(t, err)
+	assert.Empty(t, stderr)
+	assert.Contains(t, stdout, "acc-1")
+
+	t.Setenv("OA_NO_SPINNER", "1")
+	_, stderr, err = executeCLI(t, home, "usage", "--account", "acc-1", "--fetch")
+	require.NoError(t, err)
+	assert.Empty(t, stderr)
+
+	_, _, err = executeCLI(t, home, "usage", "--spinner-style", "bouncy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported spinner style "bouncy"`)
+}
+
+func TestUsageCommandReturnsFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"bad-token","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "usage", "--account", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session expired")
+	assert.Contains(t, err.Error(), "oa auth login browser --account acc-1")
+}
+
+func TestUsageAllFailedErrorCategorizesReasons(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	accounts, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	third := "\n[[accounts]]\nid = \"3\"\nname = \"user3@example.com\"\n\n[accounts.metadata]\nprovider = \"\"\nmodel = \"gpt-5\"\n\n[accounts.auth]\nmethod = \"chatgpt\"\nsecret_ref = \"openai://3/oauth_tokens\"\n"
+	require.NoError(t, os.WriteFile(accountsPath, append(accounts, third...), 0o600))
+
+	// 1: server error, 2: expired token without refresh token, 3: unreachable host.
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	secretPath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/oauth_tokens"))
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretPath), 0o755))
+	require.NoError(t, os.WriteFile(secretPath, []byte(`{"access_token":"stale","id_token":"","expires_at":1}`), 0o600))
+	require.NoError(t, writeOAuthSecretFixture(home, "3", "user3@example.com", "acct-3"))
+	_, _, err = executeCLI(t, home, "account", "set-base-url", "--account", "3", "http://127.0.0.1:1")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "usage", "--retries", "0")
+	require.Error(t, err)
+	assert.Equal(t, "all 3 accounts failed: 1 HTTP 500, 1 network error, 1 session expired", err.Error())
+	assert.ErrorIs(t, err, errSessionExpired)
+}
+
+func TestUsageCommandUpdatesAccountNameFromTokenEmail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"plan_type":"team","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":30,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":10,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	idToken := fakeJWT(`{"email":"email@adress.com"}`)
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", fmt.Sprintf(`{"access_token":"ok-token","id_token":"%s"}`, idToken),
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account: email@adress.com (Team)")
+}
+
+func TestAccountSetNameFromTokenUsesStoredEmailWithoutNetwork(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	idToken := fakeJWT(`{"email":"fresh@example.com"}`)
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", fmt.Sprintf(`{"access_token":"ok-token","id_token":"%s"}`, idToken),
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "account", "set-name", "--account", "acc-1", "--from-token")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account acc-1 name: fresh@example.com")
+	assert.Zero(t, requests.Load())
+
+	accounts, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(accounts), "name = 'fresh@example.com'")
+
+	_, _, err = executeCLI(t, home, "account", "set-name", "--account", "acc-1", "--from-token", "Other")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--from-token cannot be combined with a name")
+}
+
+func TestGlobalTimeoutAbortsSlowSecretStore(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "api_key",
+		"--secret-key", "cmd://sleep 10",
+	)
+	require.NoError(t, err)
+
+	started := time.Now()
+	_, _, err = executeCLI(t, home, "secret", "list", "--timeout", "200ms")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), 5*time.Second)
+
+	_, _, err = executeCLI(t, home, "account", "list", "--timeout", "-1s")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--timeout must not be negative")
+}
+
+func TestUsageCommandRefreshesExpiredAccessTokenAndRetries(t *testing.T) {
+	var oldTokenCalls int
+	var newTokenCalls int
+	var refreshCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/oauth/token":
+			refreshCalls++
+			require.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+			assert.Equal(t, "test-client-id", r.Form.Get("client_id"))
+			assert.Equal(t, "refresh-token-123", r.Form.Get("refresh_token"))
+			_, _ = fmt.Fprint(w, `{"access_token":"new-token","refresh_token":"refresh-token-456","id_token":"","token_type":"Bearer","expires_in":3600}`)
+		case r.URL.Path == "/wham/usage":
+			authz := r.Header.Get("Authorization")
+			if authz == "Bearer old-token" {
+				oldTokenCalls++
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
+				return
+			}
+			assert.Equal(t, "Bearer new-token", authz)
+			newTokenCalls++
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}}}`)
+		case r.URL.Path == "/subscriptions":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"old-token","refresh_token":"refresh-token-123","id_token":"","expires_at":1}`,
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, oldTokenCalls, 1)
+	assert.GreaterOrEqual(t, refreshCalls, 1)
+	assert.GreaterOrEqual(t, newTokenCalls, 1)
+	assert.Contains(t, stdout, "5hours limit:")
+}
+
+func TestUsageCommandExpiredErrorIncludesEmailAndType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	idToken := fakeJWT(`{"email":"email@adress.com"}`)
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", fmt.Sprintf(`{"access_token":"bad-token","id_token":"%s"}`, idToken),
+	)
+	require.NoError(t, err)
+
+	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account acc-1 (email@adress.com, Unknown): session expired")
+	assert.Contains(t, stderr, "account acc-1 (email@adress.com, Unknown): session expired")
+}
+
+func TestUsageCommandFetchesSubscriptionAndRendersRenewal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wham/usage":
+			_, _ = fmt.Fprint(w, `{"plan_type":"plus","rate_limit":{"allowed":true,"limit_reached":false,"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_after_seconds":120,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_after_seconds":3600,"reset_at":1893888000}}}`)
+		case r.URL.Path == "/subscriptions":
+			_, _ = fmt.Fprint(w, `{"plan_type":"plus","active_start":"2036-02-14T07:41:19Z","active_until":"2036-03-14T07:41:19Z","will_renew":true,"billing_period":"monthly","billing_currency":"EUR","is_delinquent":false}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "acc-1",
+		"--method", "chatgpt",
+		"--secret-key", "openai://acc-1/oauth_tokens",
+		"--secret-value", `{"access_token":"access-token-123","id_token":""}`,
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "renewal:")
+	assert.Contains(t, stdout, "renews in")
+	assert.Contains(t, stdout, "14 Mar")
+}
+
+func TestUsagePlanFilterFetchesOnlyRequestedTier(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			mu.Lock()
+			fetched = append(fetched, r.Header.Get("ChatGPT-Account-Id"))
+			mu.Unlock()
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	withPlans := strings.Replace(string(data), "model = \"gpt-5\"\n", "model = \"gpt-5\"\nplan_type = \"plus\"\n", 1)
+	withPlans = strings.Replace(withPlans, "model = \"gpt-5\"\n\n", "model = \"gpt-5\"\nplan_type = \"pro\"\n\n", 1)
+	require.NoError(t, os.WriteFile(accountsPath, []byte(withPlans), 0o600))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	_, _, err = executeCLI(t, home, "usage", "--plan", "pro")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acct-2"}, fetched)
+
+	fetched = nil
+	_, _, err = executeCLI(t, home, "usage", "--plan", "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, fetched)
+}
+
+func TestUsageUsesPerAccountBaseURL(t *testing.T) {
+	newUsageServer := func(hits *[]string, mu *sync.Mutex) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			*hits = append(*hits, r.URL.Path+" "+r.Header.Get("ChatGPT-Account-Id"))
+			mu.Unlock()
+			if r.URL.Path != "/wham/usage" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000}}}`)
+		}))
+	}
+
+	var mu sync.Mutex
+	var globalHits, proxyHits []string
+	global := newUsageServer(&globalHits, &mu)
+	defer global.Close()
+	proxy := newUsageServer(&proxyHits, &mu)
+	defer proxy.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", global.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	stdout, _, err := executeCLI(t, home, "account", "set-base-url", "--account", "2", proxy.URL+"/")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account 2 base URL: "+proxy.URL)
+
+	_, _, err = executeCLI(t, home, "usage")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/wham/usage acct-1", "/subscriptions "}, globalHits)
+	assert.Equal(t, []string{"/wham/usage acct-2", "/subscriptions "}, proxyHits)
+
+	_, _, err = executeCLI(t, home, "account", "set-base-url", "--account", "2", "ftp://proxy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base url must use http or https")
+
+	_, _, err = executeCLI(t, home, "account", "set-base-url", "--account", "2", "--clear")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "base_url")
+}
+
+func TestUsageOutputDeltaPrintsOnlyChangedAccounts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			weekly := 40
+			if r.Header.Get("ChatGPT-Account-Id") == "acct-1" {
+				weekly = 55
+			}
+			_, _ = fmt.Fprintf(w, `{"plan_type":"pro","rate_limit":{"secondary_window":{"used_percent":%d,"limit_window_seconds":604800,"reset_at":1893888000}}}`, weekly)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--output-delta")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "(1) weekly: 40% -> 55% used")
+	assert.Equal(t, 1, strings.Count(stdout, "\n"))
+	assert.NotContains(t, stdout, "(2)")
+
+	stdout, _, err = executeCLI(t, home, "usage", "--output-delta")
+	require.NoError(t, err)
+	assert.Equal(t, "no changes\n", stdout)
+}
+
+func TestUsageRefreshIfStaleFetchesOnlyStaleAccounts(t *testing.T) {
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			fetched = append(fetched, r.Header.Get("Authorization"))
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-10*time.Minute)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-7*time.Hour)))
+
+	_, _, err := executeCLI(t, home, "usage", "--refresh-if-stale", "--json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer access-2"}, fetched)
+}
+
+func TestUsageRefreshIfStaleSkipsNetworkWhenAllFresh(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-time.Hour)))
+
+	stdout, stderr, err := executeCLI(t, home, "usage", "--refresh-if-stale")
+	require.NoError(t, err)
+	assert.NotContains(t, stderr, "Fetching usage limits")
+	assert.Contains(t, stdout, "weekly limit:")
+}
+
+func TestStatusFailOnStaleExitsNonZeroForStaleAccount(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-7*time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "status", "--no-fetch", "--fail-on-stale")
+	require.Error(t, err)
+	assert.Equal(t, exitCodeStaleData, ExitCode(err))
+	assert.Contains(t, err.Error(), "account(s): 2")
+	assert.Contains(t, stdout, "[stale]")
+}
+
+func TestStatusFailOnStaleExitsZeroWhenAllFresh(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-time.Hour)))
+
+	_, _, err := executeCLI(t, home, "status", "--no-fetch", "--fail-on-stale")
+	require.NoError(t, err)
+	assert.Equal(t, 0, ExitCode(err))
+}
+
+func TestStatusNoFetchRendersCachedDataWithoutNetwork(t *testing.T) {
+	var requests atomic.Int32
+	var mu sync.Mutex
+	usageBearers := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/wham/usage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		usageBearers[r.Header.Get("Authorization")]++
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Minute)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", time.Now().Add(-48*time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "status", "--no-fetch", "--json")
+	require.NoError(t, err)
+	assert.Zero(t, requests.Load())
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 2)
+	require.NotNil(t, view.Accounts[0].Limits.Weekly)
+	assert.Equal(t, 40.0, view.Accounts[0].Limits.Weekly.PercentUsed)
+
+	// Without --fetch, account 1's minute-old snapshot is served from cache.
+	_, _, err = executeCLI(t, home, "status", "--json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Bearer access-2": 1}, usageBearers)
+
+	_, _, err = executeCLI(t, home, "status", "--fetch", "--json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Bearer access-1": 1, "Bearer access-2": 2}, usageBearers)
+
+	_, _, err = executeCLI(t, home, "status", "--fetch", "--no-fetch")
+	require.Error(t, err)
+}
+
+func TestStatusParallelPoolsFetchesSharedAccountOnce(t *testing.T) {
+	var mu sync.Mutex
+	usageBearers := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wham/usage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		usageBearers[r.Header.Get("Authorization")]++
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}}}`)
+	}))
+	defer server.Close()
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "account", "tag", "--account", "2", "--add", "work")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "create-from-tag", "work")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "status", "--parallel-pools", "--fetch", "--json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Bearer access-1": 1, "Bearer access-2": 1}, usageBearers)
+
+	var view poolsStatusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Pools, 2)
+	assert.Equal(t, "default-openai", view.Pools[0].Pool)
+	require.Len(t, view.Pools[0].Accounts, 2)
+	assert.Equal(t, "work", view.Pools[1].Pool)
+	require.Len(t, view.Pools[1].Accounts, 1)
+	assert.Equal(t, "2", view.Pools[1].Accounts[0].ID)
+	require.NotNil(t, view.Pools[1].Accounts[0].Limits.Weekly)
+	assert.Equal(t, 47.0, view.Pools[1].Accounts[0].Limits.Weekly.PercentUsed)
+
+	stdout, _, err = executeCLI(t, home, "status", "--parallel-pools", "--no-fetch")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "pool: default-openai\n")
+	assert.Contains(t, stdout, "\npool: work\n")
+
+	_, _, err = executeCLI(t, home, "status", "--parallel-pools", "--account", "1")
+	require.Error(t, err)
+}
+
+func TestStatusGroupByPoolRendersAccountsUnderPoolHeaders(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	path := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	third := "\n[[accounts]]\nid = \"3\"\nname = \"solo@example.com\"\n\n[accounts.metadata]\nprovider = \"anthropic\"\n"
+	require.NoError(t, os.WriteFile(path, append(data, third...), 0o600))
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "account", "tag", "--account", "1", "--add", "work")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "create-from-tag", "work")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "status", "--group-by", "pool")
+	require.NoError(t, err)
+	defaultAt := strings.Index(stdout, "pool: default-openai\n")
+	workAt := strings.Index(stdout, "pool: work\n")
+	unpooledAt := strings.Index(stdout, "pool: unpooled\n")
+	require.True(t, defaultAt >= 0 && workAt > defaultAt && unpooledAt > workAt, stdout)
+	defaultSection := stdout[defaultAt:workAt]
+	assert.Contains(t, defaultSection, "user1@example.com")
+	assert.Contains(t, defaultSection, "user+alt@example.com (Unknown, Active)")
+	workSection := stdout[workAt:unpooledAt]
+	assert.Contains(t, workSection, "user1@example.com")
+	assert.NotContains(t, workSection, "user+alt@example.com")
+	assert.NotContains(t, workSection, "Active")
+	unpooledSection := stdout[unpooledAt:]
+	assert.Contains(t, unpooledSection, "solo@example.com")
+	assert.NotContains(t, unpooledSection, "user1@example.com")
+
+	stdout, _, err = executeCLI(t, home, "status", "--group-by", "pool", "--json")
+	require.NoError(t, err)
+	var view poolsStatusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Pools, 3)
+	assert.Equal(t, []string{"default-openai", "work", "unpooled"}, []string{view.Pools[0].Pool, view.Pools[1].Pool, view.Pools[2].Pool})
+	require.Len(t, view.Pools[2].Accounts, 1)
+	assert.Equal(t, "3", view.Pools[2].Accounts[0].ID)
+
+	_, _, err = executeCLI(t, home, "status", "--group-by", "plan")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported --group-by "plan" (valid: pool)`)
+}
+
+func TestUsageRendersNamedAdditionalRateLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro",
+				"rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000},"secondary_window":{"used_percent":47,"limit_window_seconds":604800,"reset_at":1893888000}},
+				"additional_rate_limits":[
+					{"limit_name":"gpt-5","rate_limit":{"primary_window":{"used_percent":10,"limit_window_seconds":18000,"reset_at":1893456000}}},
+					{"metered_feature":"codex","rate_limit":{"secondary_window":{"used_percent":65,"limit_window_seconds":604800,"reset_at":1893888000}}}
+				]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user1@example.com", "acct-1"))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "79% left")
+	assert.Contains(t, stdout, "53% left")
+	assert.Contains(t, stdout, "gpt-5 5hours limit:")
+	assert.Contains(t, stdout, "90% left")
+	assert.Contains(t, stdout, "codex weekly limit:")
+	assert.Contains(t, stdout, "35% left")
+}
+
+func TestUsagePersistsAdditionalLimitsWhenRateLimitIsNull(t *testing.T) {
+	now := time.Date(2029, 12, 31, 12, 0, 0, 0, time.UTC)
+	pinClock(t, now)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":null,
+				"additional_rate_limits":[
+					{"limit_name":"codex","rate_limit":{
+						"primary_window":{"used_percent":30,"limit_window_seconds":18000,"reset_at":1893456000},
+						"secondary_window":{"used_percent":55,"limit_window_seconds":604800,"reset_after_seconds":259200}}}
+				]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user1@example.com", "acct-1"))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "70% left")
+	assert.Contains(t, stdout, "45% left")
+	assert.Contains(t, stdout, "codex weekly limit:")
+
+	stdout, _, err = executeCLI(t, home, "usage", "--no-fetch", "--json")
+	require.NoError(t, err)
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 1)
+	limits := view.Accounts[0].Limits
+	require.NotNil(t, limits.Daily)
+	assert.Equal(t, 30.0, limits.Daily.PercentUsed)
+	assert.Equal(t, time.Unix(1893456000, 0).UTC(), limits.Daily.ResetsAt.UTC())
+	require.NotNil(t, limits.Weekly)
+	assert.Equal(t, 55.0, limits.Weekly.PercentUsed)
+	assert.Equal(t, now.Add(72*time.Hour), limits.Weekly.ResetsAt.UTC())
+	require.Len(t, limits.Features, 1)
+	assert.Equal(t, "codex", limits.Features[0].Feature)
+	require.NotNil(t, limits.Features[0].Weekly)
+	assert.Equal(t, 55.0, limits.Features[0].Weekly.PercentUsed)
+}
+
+func TestUsageReportsOversizedResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	t.Setenv("OA_MAX_RESPONSE_BYTES", "32")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user1@example.com", "acct-1"))
+
+	_, stderr, err := executeCLI(t, home, "usage", "--account", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error()+stderr, "response exceeded 32 bytes")
+	assert.NotContains(t, err.Error()+stderr, "decode payload")
+}
+
+func TestUsageRetriesTransientFailuresUpToConfiguredCount(t *testing.T) {
+	var usageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wham/usage":
+			if usageRequests.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "acc-1", "user1@example.com", "acct-1"))
+
+	_, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--retries", "1", "--retry-backoff", "1ms")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+	assert.Equal(t, int32(2), usageRequests.Load())
+
+	usageRequests.Store(0)
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--retries", "2", "--retry-backoff", "1ms")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), usageRequests.Load())
+	assert.Contains(t, stdout, "79% left")
+}
+
+func TestUsageRetriesTokenRefreshOnlyOnTransientFailures(t *testing.T) {
+	var refreshCalls atomic.Int32
+	var failures atomic.Int32
+	var failStatus atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			refreshCalls.Add(1)
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(int(failStatus.Load()))
+				_, _ = fmt.Fprint(w, `{"error":"invalid_request"}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"access_token":"new-token","refresh_token":"refresh-token-456","id_token":"","token_type":"Bearer","expires_in":3600}`)
+		case "/wham/usage":
+			_, _ = fmt.Fprint(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":21,"limit_window_seconds":18000,"reset_at":1893456000}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	setExpiredTokens := func() {
+		_, _, err := executeCLI(t, home,
+			"auth", "set",
+			"--account", "acc-1",
+			"--method", "chatgpt",
+			"--secret-key", "openai://acc-1/oauth_tokens",
+			"--secret-value", `{"access_token":"old-token","refresh_token":"refresh-token-123","id_token":"","expires_at":1}`,
+		)
+		require.NoError(t, err)
+	}
+
+	setExpiredTokens()
+	failStatus.Store(http.StatusBadRequest)
+	failures.Store(5)
+	_, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--retries", "2", "--retry-backoff", "1ms")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Equal(t, int32(1), refreshCalls.Load(), "a 4xx refresh is not retried")
+
+	setExpiredTokens()
+	refreshCalls.Store(0)
+	failStatus.Store(http.StatusServiceUnavailable)
+	failures.Store(2)
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "acc-1", "--retries", "2", "--retry-backoff", "1ms")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), refreshCalls.Load())
+	assert.Contains(t, stdout, "79% left")
+}
+
+func TestUsageFetchShowsEveryAccountAndWritesAccountsFileOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wham/usage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		used := map[string]int{"acct-1": 21, "acct-2": 64}[r.Header.Get("ChatGPT-Account-Id")]
+		_, _ = fmt.Fprintf(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":%d,"limit_window_seconds":18000,"reset_at":1893456000}}}`, used)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+	t.Setenv("OA_BACKUP", "true")
+	t.Setenv("OA_BACKUP_KEEP", "10")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	stdout, _, err := executeCLI(t, home, "usage")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "user1@example.com")
+	assert.Contains(t, stdout, "79% left")
+	assert.Contains(t, stdout, "user2@example.com")
+	assert.Contains(t, stdout, "36% left")
+
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "percent = 21.0")
+	assert.Contains(t, string(data), "percent = 64.0")
+
+	// Every write rotates in a backup, so one backup means one write.
+	backups, err := filepath.Glob(accountsPath + ".bak.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{accountsPath + ".bak.1"}, backups)
+}
+
+func TestUsageOutOfRangePercentFailsOnlyThatAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wham/usage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		used := map[string]int{"acct-1": 21, "acct-2": 140}[r.Header.Get("ChatGPT-Account-Id")]
+		_, _ = fmt.Fprintf(w, `{"plan_type":"pro","rate_limit":{"primary_window":{"used_percent":%d,"limit_window_seconds":18000,"reset_at":1893456000}}}`, used)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_USAGE_BASE_URL", server.URL)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	_, stderr, err := executeCLI(t, home, "usage")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "account 2: usage payload: limit percent must be between 0 and 100: got 140")
+	assert.Contains(t, stderr, "1/2 accounts updated successfully")
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "percent = 21.0")
+	assert.NotContains(t, string(data), "percent = 140.0")
+}
+
+func TestUsageRejectsNegativeRetries(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "usage", "--retries", "-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--retries must not be negative")
+}
+
+func TestUsageFormatYAMLMatchesJSONFields(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	jsonOut, _, err := executeCLI(t, home, "usage", "--format", "json")
+	require.NoError(t, err)
+	yamlOut, _, err := executeCLI(t, home, "status", "--format", "yaml")
+	require.NoError(t, err)
+
+	var fromJSON map[string]any
+	require.NoError(t, json.Unmarshal([]byte(jsonOut), &fromJSON))
+	var fromYAML map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(yamlOut), &fromYAML))
+
+	require.Len(t, fromYAML["accounts"], 1)
+	require.Len(t, fromJSON["accounts"], 1)
+	for key := range fromJSON {
+		assert.Contains(t, fromYAML, key)
+	}
+	jsonAccount := fromJSON["accounts"].([]any)[0].(map[string]any)
+	yamlAccount := fromYAML["accounts"].([]any)[0].(map[string]any)
+	for key := range jsonAccount {
+		assert.Contains(t, yamlAccount, key)
+	}
+	assert.Equal(t, "acc-1", yamlAccount["id"])
+	assert.NotContains(t, yamlOut, "{")
+
+	_, _, err = executeCLI(t, home, "usage", "--json", "--format", "yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json cannot be combined with --format yaml")
+}
+
+func TestPoolStatusResolvesManyMemberNamesWithOneRepositoryRead(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	var fixture strings.Builder
+	fixture.WriteString("version = 1\n")
+	for i := 1; i <= 25; i++ {
+		fmt.Fprintf(&fixture, "\n[[accounts]]\nid = \"%d\"\nname = \"member%d@example.com\"\n\n[accounts.metadata]\nprovider = \"openai\"\nmodel = \"gpt-5\"\n", i, i)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".codex"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "accounts.toml"), []byte(fixture.String()), 0o600))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	repo, err := tomlrepo.NewRepository(viper.New())
+	require.NoError(t, err)
+	counting := &countingAccountRepo{AccountRepository: repo}
+	app.service = application.NewService(counting, app.secretStore, app.clock)
+
+	cmd := newPoolStatusCmd(app)
+	stdout := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"--format", "json"})
+	require.NoError(t, cmd.Execute())
+
+	var view poolStatusView
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &view))
+	require.Len(t, view.Members, 25)
+	for i := 1; i <= 25; i++ {
+		assert.Contains(t, view.Members, fmt.Sprintf("member%d@example.com", i))
+	}
+	assert.Equal(t, 1, counting.lists)
+	assert.Zero(t, counting.gets)
+}
+
+type countingAccountRepo struct {
+	ports.AccountRepository
+	lists int
+	gets  int
+}
+
+func (r *countingAccountRepo) List(ctx context.Context) ([]domain.Account, error) {
+	r.lists++
+	return r.AccountRepository.List(ctx)
+}
+
+func (r *countingAccountRepo) GetByID(ctx context.Context, id domain.AccountID) (domain.Account, error) {
+	r.gets++
+	return r.AccountRepository.GetByID(ctx, id)
+}
+
+func TestAccountListAndPoolStatusSupportYAMLFormat(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	listOut, _, err := executeCLI(t, home, "account", "list", "--format", "yaml")
+	require.NoError(t, err)
+	var entries []map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(listOut), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "1", entries[0]["id"])
+	assert.Equal(t, "user1@example.com", entries[0]["name"])
+
+	poolOut, _, err := executeCLI(t, home, "pool", "status", "--format", "yaml")
+	require.NoError(t, err)
+	var pool map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(poolOut), &pool))
+	assert.Equal(t, "default-openai", pool["pool"])
+	assert.Equal(t, true, pool["active"])
+	assert.Len(t, pool["members"], 2)
+
+	_, _, err = executeCLI(t, home, "pool", "status", "--format", "csv")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported output format "csv"`)
+}
+
+func TestUsageMinWeeklyFiltersRenderedAccounts(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--min-weekly", "50", "--json")
+	require.NoError(t, err)
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 1)
+	assert.Equal(t, "1", view.Accounts[0].ID)
+
+	stdout, _, err = executeCLI(t, home, "usage", "--max-weekly", "50", "--json")
+	require.NoError(t, err)
+	view = statusesView{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	assert.Empty(t, view.Accounts)
+
+	_, _, err = executeCLI(t, home, "usage", "--min-weekly", "80", "--max-weekly", "20")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--min-weekly (80) must not exceed --max-weekly (20)")
+}
+
+func TestUsagePrecisionShowsDecimalPercentLeft(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "1", "--precision", "1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "60.0% left")
+
+	_, _, err = executeCLI(t, home, "usage", "--precision", "7")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--precision must be between 0 and 3, got 7")
+}
+
+func TestUsageRecommendationModes(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "details: weekly 60% left")
+
+	stdout, _, err = executeCLI(t, home, "usage", "--account", "1", "--recommendation", "compact")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "recommendation: use user1@example.com")
+	assert.Contains(t, stdout, ", 60% weekly left")
+	assert.NotContains(t, stdout, "details:")
+
+	stdout, _, err = executeCLI(t, home, "usage", "--account", "1", "--recommendation", "off")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "recommendation:")
+
+	_, _, err = executeCLI(t, home, "usage", "--recommendation", "quiet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported recommendation mode "quiet"`)
+}
+
+func TestUsageShowUsedPercent(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", time.Now().Add(-time.Hour)))
+
+	stdout, _, err := executeCLI(t, home, "status", "--account", "1", "--show", "used")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "40% used")
+
+	stdout, _, err = executeCLI(t, home, "status", "--account", "1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "60% left")
+	assert.NotContains(t, stdout, "% used")
+
+	_, _, err = executeCLI(t, home, "status", "--show", "remaining")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported percent mode "remaining"`)
+}
+
+func TestUsageJSONIncludesResetCountdownAndLeftPercent(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	capturedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	pinClock(t, capturedAt.Add(90*time.Minute))
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", capturedAt))
+
+	stdout, _, err := executeCLI(t, home, "usage", "--account", "1", "--json")
+	require.NoError(t, err)
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 1)
+	weekly := view.Accounts[0].Limits.Weekly
+	require.NotNil(t, weekly)
+	assert.Equal(t, 60.0, weekly.LeftPercent)
+	assert.Equal(t, int64((72*time.Hour-90*time.Minute)/time.Second), weekly.SecondsUntilReset)
+	assert.Contains(t, stdout, `"secondsUntilReset": 253800`)
+}
+
+func TestStatusAccountResolvesUniqueNamePrefix(t *testing.T) {
+	t.Setenv("OA_USAGE_BASE_URL", "http://127.0.0.1:1")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	stdout, _, err := executeCLI(t, home, "status", "--account", "user1", "--json")
+	require.NoError(t, err)
+	var view statusesView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &view))
+	require.Len(t, view.Accounts, 1)
+	assert.Equal(t, "1", view.Accounts[0].ID)
+
+	_, _, err = executeCLI(t, home, "status", "--account", "user")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `account "user" is ambiguous`)
+	assert.Contains(t, err.Error(), "user1@example.com (1)")
+	assert.Contains(t, err.Error(), "user+alt@example.com (2)")
+
+	_, _, err = executeCLI(t, home, "status", "--account", "nobody")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	assert.Contains(t, err.Error(), "account 'nobody' not found")
+}
+
+func TestUsageUnknownAccountReportsFriendlyErrorAndExitCode(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "usage", "--account", "does-not-exist")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	assert.Equal(t, "account 'does-not-exist' not found (run oa account list)", err.Error())
+	assert.NotContains(t, err.Error(), "get account by id")
+	assert.Equal(t, exitCodeAccountNotFound, ExitCode(err))
+
+	_, _, err = executeCLI(t, home, "usage", "--reset-format", "bogus")
+	require.Error(t, err)
+	assert.Equal(t, exitCodeFailure, ExitCode(err))
+	assert.Equal(t, 0, ExitCode(nil))
+}
+
+func TestUsageRejectsUnknownResetFormat(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "usage", "--reset-format", "iso")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported reset format "iso"`)
+}
+
+func TestRootAndRunHelpStayConcise(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	rootOut, _, err := executeCLI(t, home, "--help")
+	require.NoError(t, err)
+	assert.Contains(t, rootOut, "oa (OpenAI Accounts CLI) helps you store account auth references")
+	assert.NotContains(t, rootOut, "pool-selected account/session environment")
+
+	runOut, _, err := executeCLI(t, home, "run", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, runOut, "Run a command with pool-selected account env")
+	assert.NotContains(t, runOut, "OA_LOGICAL_SESSION_ID")
+}
+
+func TestPoolActivateCreatesDefaultPool(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	stdout, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Activated pool default-openai")
+	assert.Contains(t, stdout, "members: 1")
+}
+
+func TestPoolStatusReportsPoolState(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "status")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "pool: default-openai")
+	assert.Contains(t, stdout, "active: true")
+	assert.Contains(t, stdout, "members: user1@example.com")
+	assert.Contains(t, stdout, "members: user1@example.com, user+alt@example.com")
+	assert.Contains(t, stdout, "strategy: least_weekly_used\n")
+	assert.NotContains(t, stdout, "warning:")
+}
+
+func TestPoolStatusWarnsWhenStrategyIsUnset(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "pools.toml"), []byte(strings.Join([]string{
+		"version = 1",
+		"",
+		"[[pools]]",
+		"id = \"default-openai\"",
+		"name = \"default\"",
+		"provider = \"openai\"",
+		"active = true",
+		"members = [\"acc-1\"]",
+		"",
+	}, "\n")), 0o600))
+
+	stdout, _, err := executeCLI(t, home, "pool", "status")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "strategy: none\n")
+	assert.Contains(t, stdout, "warning: pool default-openai has no strategy")
+
+	stdout, _, err = executeCLI(t, home, "pool", "status", "--format", "json")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "strategy")
+}
+
+func TestPoolDeactivateDisablesDefaultPool(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "deactivate")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Deactivated pool default-openai")
+
+	statusOut, _, err := executeCLI(t, home, "pool", "status")
+	require.NoError(t, err)
+	assert.Contains(t, statusOut, "active: false")
+}
+
+func TestPoolDeactivateTargetsPoolFlag(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate", "--provider", "anthropic")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "deactivate", "--pool", "default-anthropic")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Deactivated pool default-anthropic")
+
+	statusOut, _, err := executeCLI(t, home, "pool", "status", "--pool", "default-anthropic")
+	require.NoError(t, err)
+	assert.Contains(t, statusOut, "active: false")
+	statusOut, _, err = executeCLI(t, home, "pool", "status")
+	require.NoError(t, err)
+	assert.Contains(t, statusOut, "active: true")
+
+	_, _, err = executeCLI(t, home, "pool", "deactivate", "--pool", "team", "--all")
+	require.Error(t, err)
+}
+
+func TestPoolActivateAndDeactivateAllTogglesEveryPool(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "pools.toml"), []byte(strings.Join([]string{
+		"version = 1",
+		"",
+		"[[pools]]",
+		"id = \"default-openai\"",
+		"name = \"default\"",
+		"provider = \"openai\"",
+		"strategy = \"least_weekly_used\"",
+		"active = false",
+		"auto_sync_members = true",
+		"members = []",
+		"",
+		"[[pools]]",
+		"id = \"team\"",
+		"name = \"team\"",
+		"provider = \"openai\"",
+		"strategy = \"least_weekly_used\"",
+		"active = false",
+		"auto_sync_members = false",
+		"members = [\"1\"]",
+		"",
+	}, "\n")), 0o600))
+
+	stdout, _, err := executeCLI(t, home, "pool", "activate", "--all")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Activated pool default-openai")
+	assert.Contains(t, stdout, "Activated pool team")
+	assert.Contains(t, stdout, "Activated 2 pool(s)")
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "pools.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "active = true"))
+
+	stdout, _, err = executeCLI(t, home, "pool", "deactivate", "--all")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Deactivated 2 pool(s)")
+
+	data, err = os.ReadFile(filepath.Join(home, ".codex", "pools.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "active = false"))
+	assert.NotContains(t, string(data), "active = true")
+}
+
+func TestPoolActivateDryRunShowsMemberDiffWithoutSaving(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	poolsPath := filepath.Join(home, ".codex", "pools.toml")
+	pools := strings.Join([]string{
+		"version = 1",
+		"",
+		"[[pools]]",
+		"id = \"default-openai\"",
+		"name = \"default\"",
+		"provider = \"openai\"",
+		"strategy = \"least_weekly_used\"",
+		"active = false",
+		"auto_sync_members = true",
+		"members = [\"1\"]",
+		"",
+	}, "\n")
+	require.NoError(t, os.WriteFile(poolsPath, []byte(pools), 0o600))
+
+	stdout, _, err := executeCLI(t, home, "pool", "activate", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Would activate pool default-openai (members: 2)")
+	assert.Contains(t, stdout, "+2")
+	assert.NotContains(t, stdout, "-1")
+
+	data, err := os.ReadFile(poolsPath)
+	require.NoError(t, err)
+	assert.Equal(t, pools, string(data))
+}
+
+func TestPoolCreateFromTagSyncsTaggedAccounts(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+
+	stdout, _, err := executeCLI(t, home, "account", "tag", "--account", "2", "--add", "Work")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Account 2 tags: work")
+
+	stdout, _, err = executeCLI(t, home, "pool", "create-from-tag", "work")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Created pool work for tag work (members: 1)")
+
+	stdout, _, err = executeCLI(t, home, "run", "--pool", "work", "--", "sh", "-c", "printf '%s' \"$OA_ACTIVE_ACCOUNT\"")
+	require.NoError(t, err)
+	assert.Equal(t, "2", strings.TrimSpace(stdout))
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--columns", "id,tags")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1\t-")
+	assert.Contains(t, stdout, "2\twork")
+}
+
+func TestPoolCreateAcceptsPoolFlagOnStatusSwitchAndNext(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	stdout, _, err := executeCLI(t, home, "pool", "create", "--id", "team", "--name", "Team", "--strategy", "least_weekly_used", "--members", "2,1")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Created pool team (members: 2)")
+
+	stdout, _, err = executeCLI(t, home, "pool", "status", "--pool", "team")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "pool: team")
+	assert.Contains(t, stdout, "active: true")
+	assert.Contains(t, stdout, "members: user+alt@example.com, user1@example.com")
+
+	stdout, _, err = executeCLI(t, home, "pool", "switch", "--pool", "team", "--account", "1", "--no-sync")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Switched to account 1")
+
+	stdout, _, err = executeCLI(t, home, "pool", "next", "--pool", "team", "--no-sync")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Switched to account 2")
+
+	_, _, err = executeCLI(t, home, "pool", "create", "--id", "team")
+	require.ErrorContains(t, err, "pool team already exists")
+
+	_, _, err = executeCLI(t, home, "pool", "create", "--id", "odd", "--strategy", "round_robin")
+	require.ErrorContains(t, err, `unsupported strategy "round_robin"`)
+
+	_, _, err = executeCLI(t, home, "pool", "status", "--pool", "missing")
+	require.ErrorContains(t, err, "pool missing not found")
+}
+
+func TestPoolStatusAllTreeShowsSharedAccountUnderEachPool(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+
+	_, _, err := executeCLI(t, home, "account", "tag", "--account", "1", "--add", "work")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "account", "tag", "--account", "2", "--add", "work", "--add", "team")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "create-from-tag", "work")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "create-from-tag", "team")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--pool", "team", "--account", "2", "--no-sync")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "status", "--all", "--tree")
+	require.NoError(t, err)
+
+	teamAt := strings.Index(stdout, "team (active)")
+	workAt := strings.Index(stdout, "work (active)")
+	require.GreaterOrEqual(t, teamAt, 0, stdout)
+	require.Greater(t, workAt, teamAt, stdout)
+
+	teamBranch := stdout[teamAt:workAt]
+	workBranch := stdout[workAt:]
+	assert.Contains(t, teamBranch, "(2)  [active, shared]")
+	assert.NotContains(t, teamBranch, "(1)")
+	assert.Contains(t, workBranch, "(2)  [shared]")
+	assert.Contains(t, workBranch, "(1)")
+	assert.NotContains(t, workBranch, "(1)  [")
+}
+
+func TestPoolStatusTreeRequiresAll(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "pool", "status", "--tree")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--tree requires --all")
+}
+
+func TestRunFailsWhenPoolIsDeactivated(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "pool", "deactivate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "run", "--pool", "default-openai", "--", "sh", "-c", "echo ok")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pool is deactivated")
+}
+
+func TestPoolStatusSanitizesControlCharactersInMemberNames(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithControlChars(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "status")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "\x1b")
+	assert.NotContains(t, stdout, "\a")
+	assert.Contains(t, stdout, "members: user1@example.comred")
+}
+
+func TestPoolSwitchInteractiveSelectsNumberedAccount(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLIWithInput(t, home, "2\n", "pool", "switch")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1) user1@example.com")
+	assert.Contains(t, stdout, "2) user+alt@example.com")
+	assert.Contains(t, stdout, "Switched to account 2")
+}
+
+func TestPoolNextRotatesFromCurrentAccount(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "next")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Switched to account 2")
+}
+
+func TestPoolSwitchRoundCyclesThroughEveryAccount(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	third := `
+[[accounts]]
+id = "3"
+name = "user3@example.com"
+
+[accounts.metadata]
+provider = "openai"
+model = "gpt-5"
+
+[accounts.auth]
+method = ""
+secret_ref = ""
+`
+	require.NoError(t, os.WriteFile(accountsPath, append(data, third...), 0o600))
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	visited := make([]string, 0, 4)
+	for range 4 {
+		stdout, _, err := executeCLI(t, home, "pool", "switch", "--round", "--no-sync")
+		require.NoError(t, err)
+		visited = append(visited, strings.TrimSpace(stdout))
+	}
+	assert.Equal(t, []string{
+		"Switched to account 1",
+		"Switched to account 2",
+		"Switched to account 3",
+		"Switched to account 1",
+	}, visited)
+
+	_, _, err = executeCLI(t, home, "pool", "switch", "--round", "--account", "2")
+	require.Error(t, err)
+}
+
+func TestPoolSwitchSyncsOpencodeAuthImmediately(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+
+	auth := readOpencodeAuthFixture(t, home)
+	openai := auth["openai"].(map[string]any)
+	assert.Equal(t, "oauth", openai["type"])
+	assert.Equal(t, "acct-2", openai["accountId"])
+}
+
+func TestPoolSwitchRefreshesExpiredTokenBeforeOpencodeSync(t *testing.T) {
+	var refreshCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		refreshCalls.Add(1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh-stale", r.Form.Get("refresh_token"))
+		_, _ = fmt.Fprint(w, `{"access_token":"fresh-access","refresh_token":"fresh-refresh","id_token":"","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+
+	secretPath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/oauth_tokens"))
+	idToken := fakeJWT(`{"https://api.openai.com/auth":{"chatgpt_account_id":"acct-2"}}`)
+	expired := fmt.Sprintf(`{"access_token":"stale-access","refresh_token":"refresh-stale","id_token":%q,"expires_at":1}`, idToken)
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretPath), 0o755))
+	require.NoError(t, os.WriteFile(secretPath, []byte(expired), 0o600))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), refreshCalls.Load())
+	openai := readOpencodeAuthFixture(t, home)["openai"].(map[string]any)
+	assert.Equal(t, "fresh-access", openai["access"])
+	assert.Equal(t, "fresh-refresh", openai["refresh"])
+	assert.Equal(t, "acct-2", openai["accountId"])
+
+	stored, err := os.ReadFile(secretPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(stored), "fresh-access")
+	assert.NotContains(t, string(stored), "stale-access")
+}
+
+func TestPoolSwitchDryRunDoesNotRefreshExpiredToken(t *testing.T) {
+	var refreshCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshCalls.Add(1)
+		_, _ = fmt.Fprint(w, `{"access_token":"fresh-access","refresh_token":"fresh-refresh","id_token":"","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+
+	secretPath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/oauth_tokens"))
+	expired := `{"access_token":"stale-access","refresh_token":"refresh-stale","id_token":"","expires_at":1}`
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretPath), 0o755))
+	require.NoError(t, os.WriteFile(secretPath, []byte(expired), 0o600))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, stderr, err := executeCLI(t, home, "--dry-run", "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "would refresh oauth tokens for account 2")
+
+	assert.Zero(t, refreshCalls.Load())
+	stored, err := os.ReadFile(secretPath)
+	require.NoError(t, err)
+	assert.Equal(t, expired, string(stored))
+}
+
+func TestReconcileTokenExpiryMakesExpiresInUsable(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tokens, err := decodeOAuthTokens(`{"access_token":"opaque-access","expires_in":3600}`)
+	require.NoError(t, err)
+	require.Zero(t, tokens.ExpiresAt)
+	assert.False(t, tokenExpiringSoon(tokens, now.Add(2*time.Hour), time.Minute), "without expires_at the token never looks stale")
+
+	reconciled := reconcileTokenExpiry(tokens, now)
+	assert.Equal(t, now.Add(time.Hour).Unix(), reconciled.ExpiresAt)
+	assert.False(t, tokenExpiringSoon(reconciled, now, 2*time.Minute))
+	assert.True(t, tokenExpiringSoon(reconciled, now.Add(59*time.Minute), 2*time.Minute))
+
+	issuedAt := now.Add(-3 * time.Hour)
+	jwt := oauthTokens{AccessToken: fakeJWT(fmt.Sprintf(`{"iat":%d}`, issuedAt.Unix())), ExpiresIn: 3600}
+	assert.Equal(t, issuedAt.Add(time.Hour).Unix(), reconcileTokenExpiry(jwt, now).ExpiresAt, "iat anchors expires_in")
+
+	pinned := oauthTokens{AccessToken: "opaque-access", ExpiresIn: 3600, ExpiresAt: 42}
+	assert.Equal(t, int64(42), reconcileTokenExpiry(pinned, now).ExpiresAt)
+}
+
+func TestPoolSwitchRefreshesTokenWithOnlyExpiresIn(t *testing.T) {
+	var refreshCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		refreshCalls.Add(1)
+		_, _ = fmt.Fprint(w, `{"access_token":"fresh-access","refresh_token":"fresh-refresh","id_token":"","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("OA_AUTH_ISSUER", server.URL)
+	t.Setenv("OA_AUTH_CLIENT_ID", "test-client-id")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pinClock(t, now)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+
+	secretPath := filepath.Join(home, ".codex", "secrets", filepath.Clean("openai://2/oauth_tokens"))
+	idToken := fakeJWT(`{"https://api.openai.com/auth":{"chatgpt_account_id":"acct-2"}}`)
+	accessToken := fakeJWT(fmt.Sprintf(`{"iat":%d}`, now.Add(-2*time.Hour).Unix()))
+	pasted := fmt.Sprintf(`{"access_token":%q,"refresh_token":"refresh-stale","id_token":%q,"expires_in":3600}`, accessToken, idToken)
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretPath), 0o755))
+	require.NoError(t, os.WriteFile(secretPath, []byte(pasted), 0o600))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), refreshCalls.Load())
+	stored, err := os.ReadFile(secretPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(stored), "fresh-access")
+}
+
+func TestPoolSwitchWarnsWhenComputedExpiryCannotBeStored(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pinClock(t, now)
+
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+
+	tokensPath := filepath.Join(home, "tokens.json")
+	idToken := fakeJWT(`{"https://api.openai.com/auth":{"chatgpt_account_id":"acct-2"}}`)
+	accessToken := fakeJWT(fmt.Sprintf(`{"iat":%d}`, now.Unix()))
+	pasted := fmt.Sprintf(`{"access_token":%q,"refresh_token":"refresh-2","id_token":%q,"expires_in":3600}`, accessToken, idToken)
+	require.NoError(t, os.WriteFile(tokensPath, []byte(pasted), 0o600))
+
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), `secret_ref = "openai://2/oauth_tokens"`, fmt.Sprintf(`secret_ref = "cmd://cat %s"`, tokensPath), 1))
+	require.NoError(t, os.WriteFile(accountsPath, data, 0o600))
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, stderr, err := executeCLI(t, home, "pool", "switch", "--account", "2")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "warning: account 2: store computed token expiry:")
+	assert.Contains(t, stderr, "read-only")
+}
+
+func TestPoolNextSyncsOpencodeAuthImmediately(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "pool", "activate")
+	_, _, err = executeCLI(t, home, "pool", "next")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Activated pool default-openai")
-	assert.Contains(t, stdout, "members: 1")
+
+	auth := readOpencodeAuthFixture(t, home)
+	openai := auth["openai"].(map[string]any)
+	assert.Equal(t, "acct-2", openai["accountId"])
 }
 
-func TestPoolStatusReportsPoolState(t *testing.T) {
+func TestPoolNextHonorsSwitchCooldown(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	pinClock(t, start)
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
+	stdout, _, err := executeCLI(t, home, "pool", "cooldown", "10m")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Set switch cooldown for pool default-openai to 10m0s")
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "pool", "status")
+	pinClock(t, start.Add(time.Minute))
+	stdout, _, err = executeCLI(t, home, "pool", "next", "--no-sync")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "pool: default-openai")
-	assert.Contains(t, stdout, "active: true")
-	assert.Contains(t, stdout, "members: user1@example.com")
-	assert.Contains(t, stdout, "members: user1@example.com, user+alt@example.com")
+	assert.Contains(t, stdout, "Staying on account 1 (switch cooldown: 9m0s left)")
+
+	pinClock(t, start.Add(11*time.Minute))
+	stdout, _, err = executeCLI(t, home, "pool", "next", "--no-sync")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Switched to account 2")
+
+	pinClock(t, start.Add(12*time.Minute))
+	stdout, _, err = executeCLI(t, home, "pool", "next", "--no-sync")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Staying on account 2")
 }
 
-func TestPoolDeactivateDisablesDefaultPool(t *testing.T) {
+func TestPoolSwitchAndNextWithNoSyncLeaveOpencodeAuthUntouched(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "pool", "deactivate")
+	authPath := filepath.Join(home, ".local", "share", "opencode", "auth.json")
+	before, err := os.ReadFile(authPath)
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Deactivated pool default-openai")
 
-	statusOut, _, err := executeCLI(t, home, "pool", "status")
+	stdout, _, err := executeCLI(t, home, "pool", "switch", "--account", "2", "--no-sync")
 	require.NoError(t, err)
-	assert.Contains(t, statusOut, "active: false")
+	assert.Contains(t, stdout, "2")
+	after, err := os.ReadFile(authPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	stdout, _, err = executeCLI(t, home, "pool", "next", "--no-sync")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Switched to account 1")
+	after, err = os.ReadFile(authPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.Equal(t, "acct-1", readOpencodeAuthFixture(t, home)["openai"].(map[string]any)["accountId"])
 }
 
-func TestRunFailsWhenPoolIsDeactivated(t *testing.T) {
+func TestConcurrentOpencodeAuthSyncsLeaveOneCoherentEntry(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("HOME", home)
+	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
+	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
+	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
 
-	_, _, err := executeCLI(t, home, "pool", "activate")
-	require.NoError(t, err)
+	authPath := filepath.Join(home, ".local", "share", "opencode", "auth.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(authPath), 0o700))
+	require.NoError(t, os.WriteFile(authPath, []byte(`{"anthropic":{"type":"api","key":"keep-me"}}`), 0o600))
 
-	_, _, err = executeCLI(t, home, "pool", "deactivate")
+	app, err := wireApp(wireOptions{clock: testClock(t)})
 	require.NoError(t, err)
 
-	_, _, err = executeCLI(t, home, "run", "--pool", "default-openai", "--", "sh", "-c", "echo ok")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "pool is deactivated")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, id := range []string{"1", "2"} {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				assert.NoError(t, syncOpencodeAuthForAccount(t.Context(), app, domain.AccountID(id)))
+			}(id)
+		}
+	}
+	wg.Wait()
+
+	auth := readOpencodeAuthFixture(t, home)
+	assert.Equal(t, "keep-me", auth["anthropic"].(map[string]any)["key"])
+
+	entry := auth["openai"].(map[string]any)
+	switch entry["accountId"] {
+	case "acct-1":
+		assert.Equal(t, "access-1", entry["access"])
+		assert.Equal(t, "refresh-1", entry["refresh"])
+	case "acct-2":
+		assert.Equal(t, "access-2", entry["access"])
+		assert.Equal(t, "refresh-2", entry["refresh"])
+	default:
+		t.Fatalf("unexpected opencode account id %v", entry["accountId"])
+	}
+	assert.NoFileExists(t, authPath+".lock")
 }
 
-func TestPoolStatusSanitizesControlCharactersInMemberNames(t *testing.T) {
+func TestRunUsesSwitchedAccountWhenSet(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixtureWithControlChars(home))
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "pool", "status")
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
 	require.NoError(t, err)
-	assert.NotContains(t, stdout, "\x1b")
-	assert.NotContains(t, stdout, "\a")
-	assert.Contains(t, stdout, "members: user1@example.comred")
+
+	stdout, _, err := executeCLI(t, home, "run", "--pool", "default-openai", "--", "sh", "-c", "printf '%s' \"$OA_ACTIVE_ACCOUNT\"")
+	require.NoError(t, err)
+	assert.Equal(t, "2", strings.TrimSpace(stdout))
 }
 
-func TestPoolSwitchInteractiveSelectsNumberedAccount(t *testing.T) {
+func TestRunWatchRerunsOnNextAccountAfterRateLimit(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
+	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, err)
 
-	stdout, _, err := executeCLIWithInput(t, home, "2\n", "pool", "switch")
+	script := `printf '%s,' "$OA_ACTIVE_ACCOUNT"; [ "$OA_ACTIVE_ACCOUNT" = 1 ] && exit 75; exit 0`
+
+	_, _, err = executeCLI(t, home, "run", "--", "sh", "-c", script)
+	require.Error(t, err)
+	assert.Equal(t, 75, ExitCode(err))
+
+	stdout, stderr, err := executeCLI(t, home, "run", "--watch", "--rate-limit-exit-code", "75", "--", "sh", "-c", script)
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "1) user1@example.com")
-	assert.Contains(t, stdout, "2) user+alt@example.com")
-	assert.Contains(t, stdout, "Switched to account 2")
+	assert.Equal(t, "1,2,", stdout)
+	assert.Contains(t, stderr, "oa: account 1 is rate limited, re-running on account 2")
+
+	stdout, _, err = executeCLI(t, home, "run", "--", "sh", "-c", `printf '%s' "$OA_ACTIVE_ACCOUNT"`)
+	require.NoError(t, err)
+	assert.Equal(t, "2", stdout, "the failover account stays active")
 }
 
-func TestPoolNextRotatesFromCurrentAccount(t *testing.T) {
+func TestAccountDisableExcludesAccountFromPoolUntilEnabled(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
-
 	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "pool", "next")
+	stdout, _, err := executeCLI(t, home, "account", "disable", "--account", "1")
 	require.NoError(t, err)
-	assert.Contains(t, stdout, "Switched to account 2")
+	assert.Equal(t, "Account 1 disabled\n", stdout)
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "disabled = true")
+
+	stdout, _, err = executeCLI(t, home, "run", "--", "sh", "-c", `printf '%s' "$OA_ACTIVE_ACCOUNT"`)
+	require.NoError(t, err)
+	assert.Equal(t, "2", stdout)
+
+	_, _, err = executeCLI(t, home, "account", "enable", "--account", "1")
+	require.NoError(t, err)
+	data, err = os.ReadFile(filepath.Join(home, ".codex", "accounts.toml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "disabled")
 }
 
-func TestPoolSwitchSyncsOpencodeAuthImmediately(t *testing.T) {
+func TestRunRecordsLastSyncedAtFromPinnedClock(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
-	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
-	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
+	require.NoError(t, writeAccountsFixture(home))
+	pinClock(t, time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC))
 
 	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
 
-	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
+	_, _, err = executeCLI(t, home, "run", "--pool", "default-openai", "--", "true")
 	require.NoError(t, err)
 
-	auth := readOpencodeAuthFixture(t, home)
-	openai := auth["openai"].(map[string]any)
-	assert.Equal(t, "oauth", openai["type"])
-	assert.Equal(t, "acct-2", openai["accountId"])
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "pool_runtime.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "last_synced_at = '2026-03-01T09:30:00Z'")
 }
 
-func TestPoolNextSyncsOpencodeAuthImmediately(t *testing.T) {
+func TestRunExplainsExhaustedPoolWithSoonestReset(t *testing.T) {
 	home := t.TempDir()
-	require.NoError(t, writeAccountsFixtureWithTwoChatGPTAuth(home))
-	require.NoError(t, writeOAuthSecretFixture(home, "1", "user1@example.com", "acct-1"))
-	require.NoError(t, writeOAuthSecretFixture(home, "2", "user2@example.com", "acct-2"))
-
-	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	pinClock(t, now)
+	// Weekly snapshots reset 72h after capture: account 2 frees up first.
+	require.NoError(t, appendWeeklyLimitFixture(home, "1", now.Add(-24*time.Hour)))
+	require.NoError(t, appendWeeklyLimitFixture(home, "2", now.Add(-60*time.Hour)))
+	accountsPath := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(accountsPath)
 	require.NoError(t, err)
-	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "1")
+	require.NoError(t, os.WriteFile(accountsPath, []byte(strings.ReplaceAll(string(data), "percent = 40.0", "percent = 100.0")), 0o600))
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
 
-	_, _, err = executeCLI(t, home, "pool", "next")
+	_, _, err = executeCLI(t, home, "run", "--", "true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no eligible accounts in pool default-openai")
+	assert.Contains(t, err.Error(), "1 (weekly, resets 2026-03-03 09:00 UTC)")
+	assert.Contains(t, err.Error(), "2 (weekly, resets 2026-03-01 21:00 UTC)")
+	assert.Contains(t, err.Error(), "soonest reset: account 2 at 2026-03-01 21:00 UTC (in 12h0m0s)")
+}
+
+func TestRunExplainsExhaustedPoolWithDailyResets(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	pinClock(t, now)
+	// Account 1 has both windows used up and waits for the later weekly
+	// reset; account 2 only waits for its 5-hour window.
+	require.NoError(t, appendLimitFixture(home, "1", "weekly", 100, now.Add(48*time.Hour), now))
+	require.NoError(t, appendLimitFixture(home, "1", "daily", 100, now.Add(3*time.Hour), now))
+	require.NoError(t, appendLimitFixture(home, "2", "weekly", 40, now.Add(48*time.Hour), now))
+	require.NoError(t, appendLimitFixture(home, "2", "daily", 100, now.Add(2*time.Hour), now))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
 	require.NoError(t, err)
 
-	auth := readOpencodeAuthFixture(t, home)
-	openai := auth["openai"].(map[string]any)
-	assert.Equal(t, "acct-2", openai["accountId"])
+	_, _, err = executeCLI(t, home, "run", "--respect-daily", "--", "true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 (weekly and 5hours, resets 2026-03-03 09:00 UTC)")
+	assert.Contains(t, err.Error(), "2 (5hours, resets 2026-03-01 11:00 UTC)")
+	assert.Contains(t, err.Error(), "soonest reset: account 2 at 2026-03-01 11:00 UTC (in 2h0m0s)")
 }
 
-func TestRunUsesSwitchedAccountWhenSet(t *testing.T) {
+func TestRunDryRunJSONReportsSelectionWithoutRunningChild(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
 
@@ -591,9 +3559,28 @@ func TestRunUsesSwitchedAccountWhenSet(t *testing.T) {
 	_, _, err = executeCLI(t, home, "pool", "switch", "--account", "2")
 	require.NoError(t, err)
 
-	stdout, _, err := executeCLI(t, home, "run", "--pool", "default-openai", "--", "sh", "-c", "printf '%s' \"$OA_ACTIVE_ACCOUNT\"")
+	marker := filepath.Join(home, "child-ran")
+	stdout, _, err := executeCLI(t, home, "run", "--dry-run", "--json", "--", "sh", "-c", "touch "+marker)
 	require.NoError(t, err)
-	assert.Equal(t, "2", strings.TrimSpace(stdout))
+
+	var selection map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &selection))
+	assert.Equal(t, "2", selection["account_id"])
+	assert.Equal(t, "default-openai", selection["pool_id"])
+	assert.NotEmpty(t, selection["logical_session_id"])
+	assert.Equal(t, []any{"sh", "-c", "touch " + marker}, selection["command"])
+
+	_, statErr := os.Stat(marker)
+	assert.ErrorIs(t, statErr, os.ErrNotExist)
+}
+
+func TestRunJSONRequiresDryRun(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "run", "--json", "--", "sh", "-c", "exit 0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json requires --dry-run")
 }
 
 func TestRunOpencodeSyncsAuthButOtherCommandsDoNot(t *testing.T) {
@@ -643,6 +3630,65 @@ func TestPoolSwitchPreservesRuntimeMemoryLedger(t *testing.T) {
 	assert.Contains(t, string(data), "existing-memory")
 }
 
+func TestPoolRuntimePruneRemovesOnlyStaleSessions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+	require.NoError(t, writePoolRuntimeFixture(home, "old-memory"))
+
+	pinClock(t, time.Date(2026, 4, 15, 9, 0, 0, 0, time.UTC))
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	_, _, err = app.continuityService.GetOrAttachAccountSession(t.Context(), "other", "recent", "2")
+	require.NoError(t, err)
+
+	// The fixture ledger predates activity tracking; its pool last synced
+	// 46 days before the pinned clock.
+	stdout, _, err := executeCLI(t, home, "pool", "runtime", "prune", "--older-than", "60d")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Pruned 0 session(s) inactive for more than 60d")
+
+	stdout, _, err = executeCLI(t, home, "pool", "runtime", "prune", "--older-than", "30d")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Pruned 1 session(s) inactive for more than 30d")
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "pool_runtime.toml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "old-memory")
+	assert.Contains(t, string(data), "recent")
+
+	_, _, err = executeCLI(t, home, "pool", "runtime", "prune", "--older-than", "soon")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --older-than")
+}
+
+func TestPoolActivateAnthropicProviderUsesAnthropicAccounts(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
+
+	_, _, err := executeCLI(t, home,
+		"auth", "set",
+		"--account", "3",
+		"--method", "api_key",
+		"--secret-key", "anthropic://3/api_key",
+		"--secret-value", "sk-ant-123",
+		"--provider", "anthropic",
+	)
+	require.NoError(t, err)
+
+	stdout, _, err := executeCLI(t, home, "pool", "activate", "--provider", "anthropic")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Activated pool default-anthropic (members: 1)")
+
+	stdout, _, err = executeCLI(t, home, "account", "list", "--columns", "id,provider")
+	require.NoError(t, err)
+	assert.Regexp(t, `3\s+anthropic`, stdout)
+
+	_, _, err = executeCLI(t, home, "pool", "activate", "--provider", "gemini")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported provider "gemini"`)
+}
+
 func TestStatusMarksActiveAccountInTitle(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(home))
@@ -671,6 +3717,26 @@ func TestRunUsesSelectedPoolAccountInChildEnv(t *testing.T) {
 	assert.Contains(t, stdout, "default-openai:acc-1")
 }
 
+func TestRunRejectsChildThatResolvesToOaItself(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	self, err := os.Executable()
+	require.NoError(t, err)
+	binsDir := filepath.Join(home, "bin")
+	require.NoError(t, os.MkdirAll(binsDir, 0o755))
+	require.NoError(t, os.Symlink(self, filepath.Join(binsDir, "oa")))
+	t.Setenv("PATH", binsDir+":"+os.Getenv("PATH"))
+
+	_, _, err = executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "run", "--", "oa", "status")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resolves to this oa executable")
+	assert.Contains(t, err.Error(), "--allow-self")
+}
+
 func TestRunRequiresCommandAfterDoubleDash(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
@@ -680,6 +3746,44 @@ func TestRunRequiresCommandAfterDoubleDash(t *testing.T) {
 	assert.Contains(t, err.Error(), "requires a command after '--'")
 }
 
+func TestRunMemorySummaryUpdatesSessionAfterSuccess(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	t.Setenv("OA_WINDOW_FINGERPRINT", "window-a")
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	_, _, err = executeCLI(t, home, "run", "--memory-summary", "fixed the flaky test", "--", "false")
+	require.Error(t, err)
+
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+	workspaceRoot, err := os.Getwd()
+	require.NoError(t, err)
+	logicalSessionID := app.continuityService.ResolveLogicalSessionID(filepath.Clean(workspaceRoot), "window-a")
+	runtimeRepo, err := tomlrepo.NewPoolRuntimeRepository(viper.New())
+	require.NoError(t, err)
+	runtime, err := runtimeRepo.GetByPoolID(context.Background(), application.DefaultOpenAIPoolID)
+	require.NoError(t, err)
+	assert.Empty(t, runtime.Sessions[logicalSessionID].Memory.Summary)
+
+	_, _, err = executeCLI(t, home, "run", "--memory-summary", "fixed the flaky test", "--", "true")
+	require.NoError(t, err)
+
+	runtime, err = runtimeRepo.GetByPoolID(context.Background(), application.DefaultOpenAIPoolID)
+	require.NoError(t, err)
+	assert.Equal(t, "fixed the flaky test", runtime.Sessions[logicalSessionID].Memory.Summary)
+
+	summaryPath := filepath.Join(home, "summary.txt")
+	_, _, err = executeCLI(t, home, "run", "--memory-summary-file", summaryPath, "--", "sh", "-c", "printf 'written by the child\n' > "+summaryPath)
+	require.NoError(t, err)
+
+	runtime, err = runtimeRepo.GetByPoolID(context.Background(), application.DefaultOpenAIPoolID)
+	require.NoError(t, err)
+	assert.Equal(t, "written by the child", runtime.Sessions[logicalSessionID].Memory.Summary)
+}
+
 func TestRunKeepsLogicalSessionStableForSameWorkspaceAndWindow(t *testing.T) {
 	home := t.TempDir()
 	require.NoError(t, writeAccountsFixture(home))
@@ -698,11 +3802,79 @@ func TestRunKeepsLogicalSessionStableForSameWorkspaceAndWindow(t *testing.T) {
 	assert.NotEqual(t, "|", one)
 }
 
+func TestRunVerboseLogsBootstrappedThenReusedSession(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+
+	_, _, err := executeCLI(t, home, "pool", "activate")
+	require.NoError(t, err)
+
+	t.Setenv("OA_WINDOW_FINGERPRINT", "window-a")
+	sessionID, stderr, err := executeCLI(t, home, "run", "--verbose", "--", "sh", "-c", "printf '%s' \"$OA_PROVIDER_SESSION_ID\"")
+	require.NoError(t, err)
+	require.NotEmpty(t, sessionID)
+	assert.Contains(t, stderr, "oa: bootstrapped new session "+sessionID+" for account acc-1")
+
+	_, stderr, err = executeCLI(t, home, "run", "--verbose", "--", "true")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "oa: reusing session "+sessionID+" for account acc-1")
+	assert.NotContains(t, stderr, "bootstrapped")
+
+	_, stderr, err = executeCLI(t, home, "run", "--", "true")
+	require.NoError(t, err)
+	assert.NotContains(t, stderr, "session")
+}
+
+func TestAccountFlagCompletionUsesConfigFlagProfile(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, writeAccountsFixture(home))
+	profile := t.TempDir()
+	require.NoError(t, writeAccountsFixtureWithTwoNamedAccounts(profile))
+
+	stdout, _, err := executeCLI(t, home, "__complete", "--config", filepath.Join(profile, ".codex"), "usage", "--account", "")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1\tuser1@example.com\n")
+	assert.Contains(t, stdout, "2\tuser+alt@example.com\n")
+	assert.NotContains(t, stdout, "acc-1")
+	assert.Contains(t, stdout, ":4\n")
+
+	stdout, _, err = executeCLI(t, home, "__complete", "account", "set-name", "--account", "a")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "acc-1\tPrimary\n")
+	assert.NotContains(t, stdout, "user1@example.com")
+}
+
+type pinnedClock struct {
+	now time.Time
+}
+
+func (c pinnedClock) Now() time.Time {
+	return c.now
+}
+
+// pinnedClocks holds the time pinned by each test; commands run through
+// executeCLI read it via testClock.
+var pinnedClocks = map[*testing.T]ports.Clock{}
+
+func pinClock(t *testing.T, now time.Time) {
+	t.Helper()
+	pinnedClocks[t] = pinnedClock{now: now}
+	t.Cleanup(func() { delete(pinnedClocks, t) })
+}
+
+func testClock(t *testing.T) ports.Clock {
+	if clock, ok := pinnedClocks[t]; ok {
+		return clock
+	}
+	return ports.SystemClock{}
+}
+
 func executeCLI(t *testing.T, home string, args ...string) (string, string, error) {
 	t.Helper()
 	t.Setenv("HOME", home)
 
-	root := newRootCmd()
+	root, release := newRootCmd(testClock(t))
+	defer release()
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	stdin := bytes.NewBufferString("")
@@ -719,7 +3891,8 @@ func executeCLIWithInput(t *testing.T, home string, input string, args ...string
 	t.Helper()
 	t.Setenv("HOME", home)
 
-	root := newRootCmd()
+	root, release := newRootCmd(testClock(t))
+	defer release()
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	stdin := bytes.NewBufferString(input)
@@ -862,7 +4035,7 @@ func writeOAuthSecretFixture(home, accountID, email, chatgptAccountID string) er
 	}
 
 	idPayload := fmt.Sprintf(`{"https://api.openai.com/profile":{"email":%q},"https://api.openai.com/auth":{"chatgpt_account_id":%q}}`, email, chatgptAccountID)
-	tokens := fmt.Sprintf(`{"access_token":%q,"refresh_token":%q,"id_token":%q,"expires_at":1772916150}`,
+	tokens := fmt.Sprintf(`{"access_token":%q,"refresh_token":%q,"id_token":%q,"expires_at":4102444800}`,
 		"access-"+accountID,
 		"refresh-"+accountID,
 		fakeJWT(idPayload),
@@ -871,6 +4044,53 @@ func writeOAuthSecretFixture(home, accountID, email, chatgptAccountID string) er
 	return os.WriteFile(secretPath, []byte(tokens), 0o600)
 }
 
+func writeCodexAuthFixture(home string) error {
+	personal := fakeJWT(`{"email":"personal@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct-personal"}}`)
+	work := fakeJWT(`{"email":"work@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct-work"}}`)
+	auth := fmt.Sprintf(`{
+  "OPENAI_API_KEY": null,
+  "tokens": {"id_token": %q, "access_token": "access-personal", "refresh_token": "refresh-personal", "account_id": "acct-personal"},
+  "accounts": {
+    "work": {"tokens": {"id_token": %q, "access_token": "access-work", "refresh_token": "refresh-work", "account_id": "acct-work"}}
+  },
+  "last_refresh": "2026-02-28T17:54:13Z"
+}`, personal, work)
+
+	return os.WriteFile(filepath.Join(home, ".codex", "auth.json"), []byte(auth), 0o600)
+}
+
+func appendWeeklyLimitFixture(home, accountID string, capturedAt time.Time) error {
+	return appendLimitFixture(home, accountID, "weekly", 40, capturedAt.Add(72*time.Hour), capturedAt)
+}
+
+func appendLimitFixture(home, accountID, window string, percent float64, resetsAt, capturedAt time.Time) error {
+	path := filepath.Join(home, ".codex", "accounts.toml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	header := fmt.Sprintf("[[accounts]]\nid = %q\n", accountID)
+	start := strings.Index(string(data), header)
+	if start < 0 {
+		return fmt.Errorf("account %s not found in fixture", accountID)
+	}
+	end := len(data)
+	if next := strings.Index(string(data[start+len(header):]), "[[accounts]]"); next >= 0 {
+		end = start + len(header) + next
+	}
+
+	limit := fmt.Sprintf("\n[accounts.limits.%s]\npercent = %.1f\nresets_at = %q\ncaptured_at = %q\n\n",
+		window,
+		percent,
+		resetsAt.UTC().Format(time.RFC3339),
+		capturedAt.UTC().Format(time.RFC3339),
+	)
+	updated := string(data[:end]) + limit + string(data[end:])
+
+	return os.WriteFile(path, []byte(updated), 0o600)
+}
+
 func readOpencodeAuthFixture(t *testing.T, home string) map[string]any {
 	t.Helper()
 	data, err := os.ReadFile(filepath.Join(home, ".local", "share", "opencode", "auth.json"))
@@ -928,3 +4148,19 @@ func fakeJWT(payload string) string {
 	body := base64.RawURLEncoding.EncodeToString([]byte(payload))
 	return header + "." + body + ".sig"
 }
+
+func TestWireAppHTTPClientUsesProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	app, err := wireApp(wireOptions{clock: testClock(t)})
+	require.NoError(t, err)
+
+	transport, ok := app.httpClient.Transport.(*http.Transport)
+	require.True(t, ok, "expected *http.Transport, got %T", app.httpClient.Transport)
+	require.NotNil(t, transport.Proxy)
+	assert.Equal(t,
+		reflect.ValueOf(http.ProxyFromEnvironment).Pointer(),
+		reflect.ValueOf(transport.Proxy).Pointer(),
+		"usage requests must honor HTTP_PROXY, HTTPS_PROXY and NO_PROXY",
+	)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// registerAccountCompletion completes every --account flag under root with
+// the stored account IDs.
+func registerAccountCompletion(root *cobra.Command, wire func(*cobra.Command) (*app, error)) {
+	complete := accountIDCompletion(wire)
+	var walk func(*cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if cmd.LocalNonPersistentFlags().Lookup("account") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("account", complete)
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// accountIDCompletion suggests account IDs, described by their names.
+// Completion never runs PersistentPreRunE, so the app is wired here from the
+// already parsed global flags: a --config profile completes its own accounts.
+func accountIDCompletion(wire func(*cobra.Command) (*app, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		wired, err := wire(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		statuses, err := wired.service.GetStatusAll(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			id := string(status.Account.ID)
+			if !strings.HasPrefix(id, toComplete) {
+				continue
+			}
+			if status.Account.Name != "" {
+				id += "\t" + status.Account.Name
+			}
+			completions = append(completions, id)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tomlrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/toml"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage oa configuration files",
+	}
+
+	cmd.AddCommand(newConfigEditCmd(app))
+
+	return cmd
+}
+
+func newConfigEditCmd(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit a copy of accounts.toml in $EDITOR and save it only if it is valid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if app.dryRun {
+				return errors.New("config edit cannot run with --dry-run")
+			}
+			editor := editorCommand()
+			if len(editor) == 0 {
+				return errors.New("no editor configured (set $VISUAL or $EDITOR)")
+			}
+
+			path := app.accountsFile.Path()
+			err := app.accountsFile.Edit(cmd.Context(), func(copyPath string) error {
+				child := exec.CommandContext(cmd.Context(), editor[0], append(editor[1:], copyPath)...)
+				child.Stdin = cmd.InOrStdin()
+				child.Stdout = cmd.OutOrStdout()
+				child.Stderr = cmd.ErrOrStderr()
+				if err := child.Run(); err != nil {
+					return fmt.Errorf("run editor: %w", err)
+				}
+				return nil
+			})
+			if errors.Is(err, tomlrepo.ErrInvalidEdit) {
+				return fmt.Errorf("%w; kept the previous version", err)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved %s\n", path)
+			return nil
+		},
+	}
+}
+
+func editorCommand() []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(key)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if path, err := exec.LookPath("vi"); err == nil {
+		return []string{path}
+	}
+	return nil
+}
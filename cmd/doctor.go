@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+const (
+	privateFileMode fs.FileMode = 0o600
+	privateDirMode  fs.FileMode = 0o700
+)
+
+// doctorConfigFiles sit next to accounts.toml and hold account or session
+// data; missing ones are skipped.
+var doctorConfigFiles = []string{"pools.toml", "pool_runtime.toml", "config.toml"}
+
+type permissionIssue struct {
+	path string
+	mode fs.FileMode
+	want fs.FileMode
+}
+
+func newDoctorCmd(app *app) *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that secret and config files are private and every referenced secret exists",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			issues, err := findPermissionIssues(app.configDir, app.accountsFile.Path())
+			if err != nil {
+				return err
+			}
+			missing, err := findMissingSecrets(cmd.Context(), app)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			var missingErr error
+			for _, account := range missing {
+				_, _ = fmt.Fprintf(out, "secret missing: account %s references %s, which no backend holds\n", account.ID, account.Auth.SecretRef)
+			}
+			if len(missing) > 0 {
+				missingErr = fmt.Errorf("found %d account(s) whose secret is missing (run oa auth login or oa auth set again)", len(missing))
+			}
+
+			if len(issues) == 0 {
+				_, _ = fmt.Fprintln(out, "ok: secret and config files are private")
+				return missingErr
+			}
+
+			for _, issue := range issues {
+				if !fix {
+					_, _ = fmt.Fprintf(out, "insecure: %s has mode %04o (expected %04o)\n", issue.path, issue.mode, issue.want)
+					continue
+				}
+				if app.dryRun {
+					app.dryRunLog.printf("would chmod %s from %04o to %04o", issue.path, issue.mode, issue.want)
+					continue
+				}
+				if err := os.Chmod(issue.path, issue.want); err != nil {
+					return fmt.Errorf("fix permissions of %s: %w", issue.path, err)
+				}
+				_, _ = fmt.Fprintf(out, "fixed: %s mode %04o -> %04o\n", issue.path, issue.mode, issue.want)
+			}
+
+			if !fix {
+				return errors.Join(fmt.Errorf("found %d file(s) readable by other users (run oa doctor --fix)", len(issues)), missingErr)
+			}
+			return missingErr
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Restrict flagged files to 0600 and directories to 0700")
+
+	return cmd
+}
+
+// findMissingSecrets returns the accounts whose stored secret no backend
+// holds. A backend that fails to answer is reported as an error.
+func findMissingSecrets(ctx context.Context, app *app) ([]domain.Account, error) {
+	statuses, err := app.service.GetStatusAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]domain.Account, 0)
+	for _, status := range statuses {
+		secretIsMissing, err := secretMissing(ctx, app, status.Account)
+		if err != nil {
+			return nil, err
+		}
+		if secretIsMissing {
+			missing = append(missing, status.Account)
+		}
+	}
+	return missing, nil
+}
+
+// findPermissionIssues flags the accounts file and its backups, the other
+// config files and everything under the file secret store when group or
+// other users have any access. Symlinks are not followed.
+func findPermissionIssues(configDir, accountsPath string) ([]permissionIssue, error) {
+	files := []string{accountsPath}
+	backups, err := filepath.Glob(accountsPath + ".bak.*")
+	if err != nil {
+		return nil, fmt.Errorf("list accounts backups: %w", err)
+	}
+	files = append(files, backups...)
+	for _, name := range doctorConfigFiles {
+		files = append(files, filepath.Join(configDir, name))
+	}
+
+	issues := make([]permissionIssue, 0)
+	for _, path := range files {
+		info, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", path, err)
+		}
+		if issue, ok := checkPermissions(path, info); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	secretsDir := filepath.Join(configDir, secretsDirName)
+	if _, err := os.Lstat(secretsDir); errors.Is(err, os.ErrNotExist) {
+		return issues, nil
+	}
+	err = filepath.WalkDir(secretsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if issue, ok := checkPermissions(path, info); ok {
+			issues = append(issues, issue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk secrets directory: %w", err)
+	}
+
+	return issues, nil
+}
+
+func checkPermissions(path string, info fs.FileInfo) (permissionIssue, bool) {
+	want := privateFileMode
+	switch {
+	case info.IsDir():
+		want = privateDirMode
+	case !info.Mode().IsRegular():
+		return permissionIssue{}, false
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0o077 == 0 {
+		return permissionIssue{}, false
+	}
+	return permissionIssue{path: path, mode: mode, want: want}, true
+}
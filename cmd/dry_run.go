@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+)
+
+// dryRunLog reports the writes skipped under the global --dry-run flag.
+type dryRunLog struct {
+	out io.Writer
+}
+
+func (l dryRunLog) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(l.out, "dry-run: "+format+"\n", args...)
+}
+
+// The dry-run decorators read through to the wrapped adapter and turn every
+// write into a log line, so a command can be rehearsed without touching disk.
+
+type dryRunAccountRepository struct {
+	ports.AccountRepository
+	path string
+	log  dryRunLog
+}
+
+func (r dryRunAccountRepository) Save(ctx context.Context, account domain.Account) error {
+	return r.SaveAll(ctx, []domain.Account{account})
+}
+
+func (r dryRunAccountRepository) SaveAll(_ context.Context, accounts []domain.Account) error {
+	ids := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		ids = append(ids, string(account.ID))
+	}
+	r.log.printf("would save account(s) %s to %s", strings.Join(ids, ", "), r.path)
+	return nil
+}
+
+func (r dryRunAccountRepository) Replace(_ context.Context, id domain.AccountID, account domain.Account) error {
+	r.log.printf("would replace account %s with %s in %s", id, account.ID, r.path)
+	return nil
+}
+
+func (r dryRunAccountRepository) Delete(_ context.Context, id domain.AccountID) error {
+	r.log.printf("would remove account %s from %s", id, r.path)
+	return nil
+}
+
+type dryRunPoolRepository struct {
+	ports.PoolRepository
+	path string
+	log  dryRunLog
+}
+
+func (r dryRunPoolRepository) Save(_ context.Context, pool domain.Pool) error {
+	r.log.printf("would save pool %s to %s", pool.ID, r.path)
+	return nil
+}
+
+type dryRunPoolRuntimeRepository struct {
+	ports.PoolRuntimeRepository
+	path string
+	log  dryRunLog
+}
+
+func (r dryRunPoolRuntimeRepository) Save(_ context.Context, runtime domain.PoolRuntime) error {
+	r.log.printf("would save runtime for pool %s to %s", runtime.PoolID, r.path)
+	return nil
+}
+
+type dryRunSecretStore struct {
+	ports.SecretStore
+	backend string
+	log     dryRunLog
+}
+
+// Name reports the wrapped backend's name, which the embedded port does not
+// expose.
+func (s dryRunSecretStore) Name() string {
+	if named, ok := s.SecretStore.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return s.backend
+}
+
+func (s dryRunSecretStore) Put(_ context.Context, key string, _ string) error {
+	s.log.printf("would store secret %s in %s", key, s.backend)
+	return nil
+}
+
+func (s dryRunSecretStore) Delete(_ context.Context, key string) error {
+	s.log.printf("would delete secret %s from %s", key, s.backend)
+	return nil
+}
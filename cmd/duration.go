@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseLookback parses a non-negative duration, accepting a whole number of
+// days ("30d") in addition to time.ParseDuration syntax.
+func parseLookback(raw string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(raw)
+	if days, ok := strings.CutSuffix(trimmed, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			if n < 0 {
+				return 0, fmt.Errorf("duration %q must not be negative", trimmed)
+			}
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+
+	lookback, err := time.ParseDuration(trimmed)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration (e.g. 30d, 12h)", trimmed)
+	}
+	if lookback < 0 {
+		return 0, fmt.Errorf("duration %q must not be negative", trimmed)
+	}
+	return lookback, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+)
+
+const (
+	exitCodeFailure         = 1
+	exitCodeAccountNotFound = 3
+	exitCodeStaleData       = 4
+)
+
+// ExitCode maps an error returned by Execute to the process exit status.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var coded interface{ ExitCode() int }
+	if errors.As(err, &coded) {
+		return coded.ExitCode()
+	}
+	return exitCodeFailure
+}
+
+// accountNotFoundError is returned when an --account selector matches no
+// account. It still satisfies errors.Is(err, domain.ErrAccountNotFound).
+type accountNotFoundError struct {
+	ref string
+}
+
+func (e accountNotFoundError) Error() string {
+	return fmt.Sprintf("account '%s' not found (run oa account list)", e.ref)
+}
+
+func (e accountNotFoundError) Unwrap() error {
+	return domain.ErrAccountNotFound
+}
+
+func (e accountNotFoundError) ExitCode() int {
+	return exitCodeAccountNotFound
+}
+
+// staleDataError is returned by --fail-on-stale once output has been written.
+type staleDataError struct {
+	accounts   []domain.AccountID
+	staleAfter time.Duration
+}
+
+func (e staleDataError) Error() string {
+	ids := make([]string, 0, len(e.accounts))
+	for _, id := range e.accounts {
+		ids = append(ids, string(id))
+	}
+	return fmt.Sprintf("stale usage data (older than %s) for account(s): %s", e.staleAfter, strings.Join(ids, ", "))
+}
+
+func (e staleDataError) ExitCode() int {
+	return exitCodeStaleData
+}
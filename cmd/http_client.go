@@ -0,0 +1,16 @@
+package cmd
+
+import "net/http"
+
+// newHTTPClient builds the client used for usage, subscription and token
+// requests. The transport routes through HTTP_PROXY/HTTPS_PROXY (http or
+// socks5 URLs) and honors NO_PROXY, so oa works behind corporate proxies.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: newHTTPTransport()}
+}
+
+func newHTTPTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return transport
+}
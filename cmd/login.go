@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
 
 	authadapter "github.com/bnema/openai-accounts-cli/internal/adapters/auth"
+	"github.com/bnema/openai-accounts-cli/internal/adapters/filelock"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/spf13/cobra"
 )
@@ -22,45 +29,83 @@ func newLoginCmd(app *app) *cobra.Command {
 
 func newLoginBrowserCmd(app *app) *cobra.Command {
 	var accountID string
+	var timeout time.Duration
+	var provider string
 
 	cmd := &cobra.Command{
 		Use:   "browser",
 		Short: "Start browser login flow",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := validateLoginTimeout(timeout); err != nil {
+				return err
+			}
+			resolvedProvider, err := parseProvider(cmd, provider)
+			if err != nil {
+				return err
+			}
 			resolvedAccountID, err := resolveAccountID(cmd.Context(), app, accountID)
 			if err != nil {
 				return err
 			}
-			return runBrowserLogin(cmd, app, resolvedAccountID)
+			return runBrowserLogin(cmd, app, resolvedAccountID, resolvedProvider, timeout)
 		},
 	}
 
 	cmd.Flags().StringVar(&accountID, "account", "0", "Account ID (0 or empty auto-assigns next: 1,2,...)")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultLoginTimeout, "How long to wait for the login to complete")
+	cmd.Flags().StringVar(&provider, "provider", string(domain.ProviderOpenAI), "Account provider")
 
 	return cmd
 }
 
 func newLoginDeviceCmd(app *app) *cobra.Command {
 	var accountID string
+	var timeout time.Duration
+	var provider string
 
 	cmd := &cobra.Command{
 		Use:   "device",
 		Short: "Start device login flow",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := validateLoginTimeout(timeout); err != nil {
+				return err
+			}
+			resolvedProvider, err := parseProvider(cmd, provider)
+			if err != nil {
+				return err
+			}
 			resolvedAccountID, err := resolveAccountID(cmd.Context(), app, accountID)
 			if err != nil {
 				return err
 			}
-			return fmt.Errorf("%s for account %s: %w", cmd.CommandPath(), resolvedAccountID, errNotImplementedYet)
+			return runDeviceLogin(cmd, app, resolvedAccountID, resolvedProvider, timeout)
 		},
 	}
 
 	cmd.Flags().StringVar(&accountID, "account", "0", "Account ID (0 or empty auto-assigns next: 1,2,...)")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultLoginTimeout, "How long to wait for the login to complete")
+	cmd.Flags().StringVar(&provider, "provider", string(domain.ProviderOpenAI), "Account provider")
 
 	return cmd
 }
 
-func runBrowserLogin(cmd *cobra.Command, app *app, accountID domain.AccountID) error {
+func validateLoginTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive, got %s", timeout)
+	}
+	if timeout >= loginLockStaleAfter {
+		return fmt.Errorf("--timeout must be less than %s, got %s", loginLockStaleAfter, timeout)
+	}
+	return nil
+}
+
+func runBrowserLogin(cmd *cobra.Command, app *app, accountID domain.AccountID, provider string, timeout time.Duration) error {
+	release, err := lockAccountLogin(app, accountID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = release() }()
+
 	pkce, err := authadapter.NewPKCEPair()
 	if err != nil {
 		return fmt.Errorf("generate pkce: %w", err)
@@ -70,7 +115,10 @@ func runBrowserLogin(cmd *cobra.Command, app *app, accountID domain.AccountID) e
 		return fmt.Errorf("generate oauth state: %w", err)
 	}
 
-	server, err := authadapter.StartCallbackServer(app.browserLogin.ListenAddr, state)
+	server, err := authadapter.StartCallbackServerWithRedirect(app.browserLogin.ListenAddr, state, authadapter.CallbackRedirect{
+		Host: app.browserLogin.RedirectHost,
+		Path: app.browserLogin.RedirectPath,
+	})
 	if err != nil {
 		return fmt.Errorf("start callback server: %w", err)
 	}
@@ -90,38 +138,127 @@ func runBrowserLogin(cmd *cobra.Command, app *app, accountID domain.AccountID) e
 
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Open this URL to authenticate account %s:\n%s\n", accountID, authURL)
 
-	code, err := server.WaitForCode(app.browserLogin.Timeout)
+	code, err := server.WaitForCode(cmd.Context(), timeout)
 	if err != nil {
+		if errors.Is(err, authadapter.ErrCallbackTimeout) {
+			return fmt.Errorf("no login callback received within %s (increase with --timeout): %w", timeout, err)
+		}
 		return fmt.Errorf("wait for oauth callback: %w", err)
 	}
 
-	tokens, err := authadapter.ExchangeCodeForTokens(http.DefaultClient, authadapter.TokenExchangeRequest{
-		Issuer:       app.browserLogin.Issuer,
-		ClientID:     app.browserLogin.ClientID,
-		RedirectURI:  server.RedirectURI(),
-		Code:         code,
-		CodeVerifier: pkce.Verifier,
+	tokens, err := authadapter.ExchangeCodeForTokens(app.httpClient, authadapter.TokenExchangeRequest{
+		Issuer:           app.browserLogin.Issuer,
+		ClientID:         app.browserLogin.ClientID,
+		RedirectURI:      server.RedirectURI(),
+		Code:             code,
+		CodeVerifier:     pkce.Verifier,
+		MaxResponseBytes: app.maxResponseBytes,
 	})
 	if err != nil {
 		return fmt.Errorf("exchange code for tokens: %w", err)
 	}
 
-	secretValue, err := encodeOAuthTokens(withCalculatedExpiry(oauthTokens{
+	return saveLoginTokens(cmd.Context(), cmd, app, accountID, provider, oauthTokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+		TokenType:    tokens.TokenType,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+func runDeviceLogin(cmd *cobra.Command, app *app, accountID domain.AccountID, provider string, timeout time.Duration) error {
+	// Ctrl-C cancels the poll instead of killing oa mid-write.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	release, err := lockAccountLogin(app, accountID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = release() }()
+
+	flow := authadapter.DeviceFlowAdapter{
+		API: authadapter.API{
+			BaseURL:        app.browserLogin.Issuer,
+			DeviceCodePath: app.browserLogin.DeviceCodePath,
+			TokenPath:      app.browserLogin.TokenPath,
+		},
+		HTTPClient:       app.httpClient,
+		MaxResponseBytes: app.maxResponseBytes,
+	}
+
+	code, err := flow.RequestDeviceCode(ctx, app.browserLogin.ClientID, []string{"openid", "profile", "email", "offline_access"})
+	if err != nil {
+		return fmt.Errorf("request device code: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "To authenticate account %s, open:\n%s\nand enter code: %s\n", accountID, code.VerificationURL, code.UserCode)
+
+	tokens, err := flow.PollToken(ctx, authadapter.DevicePollRequest{
+		ClientID:     app.browserLogin.ClientID,
+		DeviceAuthID: code.DeviceAuthID,
+		PollInterval: code.PollInterval,
+		Timeout:      timeout,
+	})
+	if err != nil {
+		if errors.Is(err, authadapter.ErrDeviceFlowTimeout) {
+			return fmt.Errorf("device not authorized within %s (increase with --timeout): %w", timeout, err)
+		}
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("device login canceled: %w", err)
+		}
+		return fmt.Errorf("poll device token: %w", err)
+	}
+
+	return saveLoginTokens(ctx, cmd, app, accountID, provider, oauthTokens{
 		AccessToken:  tokens.AccessToken,
 		RefreshToken: tokens.RefreshToken,
 		IDToken:      tokens.IDToken,
 		TokenType:    tokens.TokenType,
 		ExpiresIn:    tokens.ExpiresIn,
-	}, app.now()))
+	})
+}
+
+// saveLoginTokens stores the tokens a login flow obtained as the account's
+// ChatGPT auth and tags its provider.
+func saveLoginTokens(ctx context.Context, cmd *cobra.Command, app *app, accountID domain.AccountID, provider string, tokens oauthTokens) error {
+	secretValue, err := encodeOAuthTokens(withCalculatedExpiry(tokens, app.clock.Now()))
 	if err != nil {
 		return err
 	}
 
-	secretKey := fmt.Sprintf("openai://%s/oauth_tokens", accountID)
-	if err := app.service.SetAuth(cmd.Context(), accountID, domain.AuthMethodChatGPT, secretKey, secretValue); err != nil {
+	secretKey := app.secretKeys.key(accountID, secretKindOAuthTokens)
+	if err := app.service.SetAuth(ctx, accountID, domain.AuthMethodChatGPT, secretKey, secretValue); err != nil {
 		return fmt.Errorf("save account oauth auth: %w", err)
 	}
+	if err := applyAccountProvider(ctx, app, accountID, provider); err != nil {
+		return err
+	}
 
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Authenticated account %s\n", accountID)
 	return nil
 }
+
+// lockAccountLogin keeps a second login for the same account from racing the
+// first one on the callback and the stored secret. The lock lives next to the
+// accounts file and expires after loginLockStaleAfter.
+func lockAccountLogin(app *app, accountID domain.AccountID) (func() error, error) {
+	if app.dryRun {
+		return func() error { return nil }, nil
+	}
+	lockDir := filepath.Dir(app.accountsFile.Path())
+	if err := os.MkdirAll(lockDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create login lock directory: %w", err)
+	}
+
+	lockPath := filepath.Join(lockDir, "login-"+url.PathEscape(string(accountID)))
+	release, err := filelock.TryAcquire(lockPath, loginLockStaleAfter)
+	if err != nil {
+		if errors.Is(err, filelock.ErrLocked) {
+			return nil, fmt.Errorf("login already in progress for %s", accountID)
+		}
+		return nil, fmt.Errorf("lock account login: %w", err)
+	}
+	return release, nil
+}
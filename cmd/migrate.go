@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const secretsDirName = "secrets"
+
+// migratedConfigEntries are copied by `oa migrate`; missing ones are skipped.
+var migratedConfigEntries = []string{"accounts.toml", "pools.toml", "pool_runtime.toml", secretsDirName}
+
+func newMigrateCmd(app *app) *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy accounts, pools, runtime state and file secrets to a new config directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			to, err := filepath.Abs(strings.TrimSpace(target))
+			if err != nil {
+				return fmt.Errorf("resolve --to: %w", err)
+			}
+
+			if app.dryRun {
+				app.dryRunLog.printf("would copy %s from %s to %s", strings.Join(migratedConfigEntries, ", "), app.configDir, to)
+				return nil
+			}
+
+			var copied int
+			err = holdConfigStores(cmd.Context(), app.configStores, func() error {
+				var err error
+				copied, err = migrateConfigDir(app.configDir, to)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Copied %d file(s) from %s to %s\n", copied, app.configDir, to)
+			_, _ = fmt.Fprintln(out, "Set this in your shell profile to use the new location:")
+			_, _ = fmt.Fprintf(out, "  export OA_CONFIG_DIR=%s\n", to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "to", "", "New config directory (must not exist or be empty)")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// holdConfigStores runs fn while every store blocks its writers, so the copy
+// never catches a file halfway through a rewrite.
+func holdConfigStores(ctx context.Context, stores []configStore, fn func() error) error {
+	if len(stores) == 0 {
+		return fn()
+	}
+	return stores[0].Hold(ctx, func() error {
+		return holdConfigStores(ctx, stores[1:], fn)
+	})
+}
+
+// migrateConfigDir copies the config entries into a staging directory next to
+// to and renames it into place, so to never holds a partial copy. Sources are
+// left untouched.
+func migrateConfigDir(from, to string) (int, error) {
+	from = filepath.Clean(from)
+	if to == from || strings.HasPrefix(to, from+string(filepath.Separator)) {
+		return 0, fmt.Errorf("--to %s must be outside the current config directory %s", to, from)
+	}
+	if err := ensureEmptyTarget(to); err != nil {
+		return 0, err
+	}
+
+	parent := filepath.Dir(to)
+	if err := os.MkdirAll(parent, 0o700); err != nil {
+		return 0, fmt.Errorf("create parent directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(parent, ".oa-migrate-*")
+	if err != nil {
+		return 0, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	copied := 0
+	for _, name := range migratedConfigEntries {
+		n, err := copyConfigEntry(filepath.Join(from, name), filepath.Join(staging, name))
+		if err != nil {
+			return 0, err
+		}
+		copied += n
+	}
+	if copied == 0 {
+		return 0, fmt.Errorf("nothing to migrate in %s", from)
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return 0, fmt.Errorf("stat config directory: %w", err)
+	}
+	if err := os.Chmod(staging, info.Mode().Perm()); err != nil {
+		return 0, fmt.Errorf("set config directory permissions: %w", err)
+	}
+	// An empty target passed ensureEmptyTarget; rename cannot replace it.
+	_ = os.Remove(to)
+	if err := os.Rename(staging, to); err != nil {
+		return 0, fmt.Errorf("move staged config into place: %w", err)
+	}
+
+	return copied, nil
+}
+
+func ensureEmptyTarget(dir string) error {
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("inspect --to: %w", err)
+	case len(entries) > 0:
+		return fmt.Errorf("--to %s is not empty", dir)
+	}
+	return nil
+}
+
+// copyConfigEntry copies a file or directory tree, keeping permission bits.
+// It returns the number of regular files copied.
+func copyConfigEntry(src, dst string) (int, error) {
+	if _, err := os.Lstat(src); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	copied := 0
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			if err := copyFileWithMode(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			copied++
+			return nil
+		default:
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("copy %s: %w", src, err)
+	}
+	return copied, nil
+}
+
+func copyFileWithMode(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile applies the umask; restore the source bits exactly.
+	return os.Chmod(dst, mode)
+}
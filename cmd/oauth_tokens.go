@@ -42,6 +42,27 @@ func withCalculatedExpiry(tokens oauthTokens, now time.Time) oauthTokens {
 	return tokens
 }
 
+// reconcileTokenExpiry fills expires_at for tokens that only carry
+// expires_in, e.g. a raw OAuth response pasted into a secret. The access
+// token's exp claim wins; otherwise expires_in counts from its iat claim, or
+// from now when the token is opaque.
+func reconcileTokenExpiry(tokens oauthTokens, now time.Time) oauthTokens {
+	if tokens.ExpiresAt > 0 || tokens.ExpiresIn <= 0 {
+		return tokens
+	}
+
+	claims := parseTokenClaims(tokens.AccessToken)
+	switch {
+	case claims.ExpiresAt > 0:
+		tokens.ExpiresAt = claims.ExpiresAt
+	case claims.IssuedAt > 0:
+		tokens.ExpiresAt = claims.IssuedAt + tokens.ExpiresIn
+	default:
+		tokens = withCalculatedExpiry(tokens, now)
+	}
+	return tokens
+}
+
 func tokenExpiringSoon(tokens oauthTokens, now time.Time, skew time.Duration) bool {
 	if tokens.ExpiresAt <= 0 {
 		return false
@@ -9,9 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bnema/openai-accounts-cli/internal/adapters/filelock"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 )
 
+const opencodeAuthLockTimeout = 10 * time.Second
+
 type opencodeOAuthAuth struct {
 	Type      string `json:"type"`
 	Refresh   string `json:"refresh"`
@@ -45,11 +48,18 @@ func syncOpencodeAuthForAccount(ctx context.Context, app *app, accountID domain.
 		return fmt.Errorf("decode oauth secret for opencode auth sync: %w", err)
 	}
 
+	// Refresh an expired or expiring token first; ensureFreshTokens persists
+	// the rotation so oa and opencode share the same fresh token.
+	tokens, err = ensureFreshTokens(ctx, app, status.Account, tokens, false)
+	if err != nil {
+		return fmt.Errorf("refresh oauth tokens for opencode auth sync: %w", err)
+	}
+
 	entry := opencodeOAuthAuth{
 		Type:      "oauth",
 		Refresh:   tokens.RefreshToken,
 		Access:    tokens.AccessToken,
-		ExpiresMS: tokenExpiryMillis(tokens, app.now),
+		ExpiresMS: tokenExpiryMillis(tokens, app.clock.Now()),
 		AccountID: accountIDFromToken(tokens.IDToken),
 	}
 
@@ -57,6 +67,24 @@ func syncOpencodeAuthForAccount(ctx context.Context, app *app, accountID domain.
 	if err != nil {
 		return err
 	}
+	if app.dryRun {
+		app.dryRunLog.printf("would write openai auth for account %s to %s", status.Account.ID, path)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create opencode auth directory: %w", err)
+	}
+
+	// Concurrent runs may sync different accounts; hold the lock across the
+	// read-modify-write so one entry wins instead of interleaving writes.
+	lockCtx, cancel := context.WithTimeout(ctx, opencodeAuthLockTimeout)
+	defer cancel()
+	release, err := filelock.Acquire(lockCtx, path)
+	if err != nil {
+		return fmt.Errorf("lock opencode auth file: %w", err)
+	}
+	defer func() { _ = release() }()
 
 	content, err := readOpencodeAuthMap(path)
 	if err != nil {
@@ -147,12 +175,9 @@ func writeOpencodeAuthMap(path string, content map[string]any) error {
 	return nil
 }
 
-func tokenExpiryMillis(tokens oauthTokens, now func() time.Time) int64 {
-	if tokens.ExpiresAt > 0 {
-		return tokens.ExpiresAt * 1000
-	}
-	if tokens.ExpiresIn > 0 {
-		return now().Add(time.Duration(tokens.ExpiresIn) * time.Second).UnixMilli()
+func tokenExpiryMillis(tokens oauthTokens, now time.Time) int64 {
+	if tokens.ExpiresAt <= 0 {
+		tokens = reconcileTokenExpiry(tokens, now)
 	}
-	return 0
+	return tokens.ExpiresAt * 1000
 }
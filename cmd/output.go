@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputFormatText outputFormat = "text"
+	outputFormatJSON outputFormat = "json"
+	outputFormatYAML outputFormat = "yaml"
+)
+
+func parseOutputFormat(raw string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(strings.TrimSpace(raw))); format {
+	case "":
+		return outputFormatText, nil
+	case outputFormatText, outputFormatJSON, outputFormatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (valid formats: text, json, yaml)", raw)
+	}
+}
+
+func bindFormatFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVar(format, "format", string(outputFormatText), "Output format (text|json|yaml)")
+}
+
+// resolveOutputFormat combines --format with the older --json shortcut.
+func resolveOutputFormat(raw string, asJSON bool) (outputFormat, error) {
+	format, err := parseOutputFormat(raw)
+	if err != nil {
+		return "", err
+	}
+	if asJSON {
+		if format != outputFormatText && format != outputFormatJSON {
+			return "", fmt.Errorf("--json cannot be combined with --format %s", format)
+		}
+		return outputFormatJSON, nil
+	}
+	return format, nil
+}
+
+// writeStructured encodes v as JSON or YAML. YAML output is derived from the
+// JSON encoding so both formats share field names and ordering.
+func writeStructured(w io.Writer, format outputFormat, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode json output: %w", err)
+	}
+
+	if format == outputFormatJSON {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &node); err != nil {
+		return fmt.Errorf("convert output to yaml: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	yamlEnc := yaml.NewEncoder(w)
+	yamlEnc.SetIndent(2)
+	if err := yamlEnc.Encode(&node); err != nil {
+		return fmt.Errorf("encode yaml output: %w", err)
+	}
+	return yamlEnc.Close()
+}
+
+// clearYAMLStyle drops the flow and quoting styles inherited from the JSON
+// source so the encoder emits block YAML.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
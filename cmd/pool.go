@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/bnema/openai-accounts-cli/internal/application"
@@ -25,18 +27,55 @@ func newPoolCmd(app *app) *cobra.Command {
 		newPoolDeactivateCmd(app),
 		newPoolStatusCmd(app),
 		newPoolNextCmd(app),
+		newPoolCooldownCmd(app),
 		newPoolSwitchCmd(app),
+		newPoolCreateCmd(app),
+		newPoolCreateFromTagCmd(app),
+		newPoolRuntimeCmd(app),
 	)
 
 	return cmd
 }
 
 func newPoolActivateCmd(app *app) *cobra.Command {
-	return &cobra.Command{
+	var all bool
+	var providerName string
+
+	cmd := &cobra.Command{
 		Use:   "activate",
-		Short: "Activate the default OpenAI pool",
+		Short: "Activate the default pool of a provider (OpenAI unless --provider is set)",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			pool, err := app.poolService.ActivateDefaultOpenAIPool(cmd.Context())
+			if all && app.dryRun {
+				return errors.New("--dry-run cannot be combined with --all")
+			}
+			if all && cmd.Flags().Changed("provider") {
+				return errors.New("--provider cannot be combined with --all")
+			}
+			provider := domain.Provider(strings.ToLower(strings.TrimSpace(providerName)))
+			if !provider.Supported() {
+				return fmt.Errorf("unsupported provider %q (valid: %s, %s)", providerName, domain.ProviderOpenAI, domain.ProviderAnthropic)
+			}
+			if app.dryRun {
+				plan, err := app.poolService.PlanDefaultPoolActivation(cmd.Context(), provider)
+				if err != nil {
+					return err
+				}
+				writePoolActivationPlan(cmd.OutOrStdout(), plan)
+				return nil
+			}
+			if all {
+				pools, err := app.poolService.ActivateAllPools(cmd.Context())
+				for _, pool := range pools {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Activated pool %s (members: %d)\n", pool.ID, len(pool.Members))
+				}
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Activated %d pool(s)\n", len(pools))
+				return nil
+			}
+
+			pool, err := app.poolService.ActivateDefaultPool(cmd.Context(), provider)
 			if err != nil {
 				return err
 			}
@@ -45,14 +84,128 @@ func newPoolActivateCmd(app *app) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Activate every configured pool")
+	cmd.Flags().StringVar(&providerName, "provider", string(domain.ProviderOpenAI), "Provider whose default pool to activate (openai|anthropic)")
+
+	return cmd
+}
+
+func writePoolActivationPlan(w io.Writer, plan application.PoolActivationPlan) {
+	_, _ = fmt.Fprintf(w, "Would activate pool %s (members: %d)\n", plan.Pool.ID, len(plan.Pool.Members))
+	if len(plan.Added) == 0 && len(plan.Removed) == 0 {
+		_, _ = fmt.Fprintln(w, "no member changes")
+		return
+	}
+	for _, id := range plan.Added {
+		_, _ = fmt.Fprintf(w, "+%s\n", id)
+	}
+	for _, id := range plan.Removed {
+		_, _ = fmt.Fprintf(w, "-%s\n", id)
+	}
+}
+
+func newPoolCreateCmd(app *app) *cobra.Command {
+	var poolID string
+	var name string
+	var strategy string
+	var provider string
+	var members []string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a pool with a fixed list of member accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			id := strings.TrimSpace(poolID)
+			if id == "" {
+				return errors.New("--id must not be empty")
+			}
+			poolName := strings.TrimSpace(name)
+			if poolName == "" {
+				poolName = id
+			}
+
+			pool := domain.Pool{
+				ID:       domain.PoolID(id),
+				Name:     poolName,
+				Provider: domain.Provider(strings.ToLower(strings.TrimSpace(provider))),
+				Strategy: domain.PoolStrategy(strings.ToLower(strings.TrimSpace(strategy))),
+			}
+			for _, member := range members {
+				pool.Members = append(pool.Members, domain.AccountID(strings.TrimSpace(member)))
+			}
+
+			pool, err := app.poolService.CreatePool(cmd.Context(), pool)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Created pool %s (members: %d)\n", pool.ID, len(pool.Members))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&poolID, "id", "", "Pool ID")
+	cmd.Flags().StringVar(&name, "name", "", "Pool name (default: the ID)")
+	cmd.Flags().StringVar(&strategy, "strategy", string(domain.PoolStrategyLeastWeeklyUsed), "Account selection strategy")
+	cmd.Flags().StringVar(&provider, "provider", string(domain.ProviderOpenAI), "Provider of the member accounts (openai, anthropic)")
+	cmd.Flags().StringSliceVar(&members, "members", nil, "Member account IDs, comma-separated or repeated")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func newPoolCreateFromTagCmd(app *app) *cobra.Command {
+	var poolID string
+
+	cmd := &cobra.Command{
+		Use:   "create-from-tag <tag>",
+		Short: "Create a pool that auto-syncs accounts carrying a tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tag := domain.NormalizeTag(args[0])
+			id := poolID
+			if strings.TrimSpace(id) == "" {
+				id = tag
+			}
+
+			pool, err := app.poolService.CreatePoolFromTag(cmd.Context(), domain.PoolID(id), tag)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Created pool %s for tag %s (members: %d)\n", pool.ID, pool.MemberTag, len(pool.Members))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&poolID, "id", "", "Pool ID (default: the tag)")
+
+	return cmd
 }
 
 func newPoolDeactivateCmd(app *app) *cobra.Command {
-	return &cobra.Command{
+	var all bool
+	var poolID string
+
+	cmd := &cobra.Command{
 		Use:   "deactivate",
-		Short: "Deactivate the default OpenAI pool",
+		Short: "Deactivate a pool (the default OpenAI pool unless --pool is set)",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			pool, err := app.poolService.DeactivatePool(cmd.Context(), application.DefaultOpenAIPoolID)
+			if all {
+				pools, err := app.poolService.DeactivateAllPools(cmd.Context())
+				for _, pool := range pools {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deactivated pool %s\n", pool.ID)
+				}
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deactivated %d pool(s)\n", len(pools))
+				return nil
+			}
+
+			pool, err := app.poolService.DeactivatePool(cmd.Context(), domain.PoolID(strings.TrimSpace(poolID)))
 			if err != nil {
 				return err
 			}
@@ -61,48 +214,169 @@ func newPoolDeactivateCmd(app *app) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Deactivate every configured pool")
+	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
+	cmd.MarkFlagsMutuallyExclusive("pool", "all")
+
+	return cmd
+}
+
+type poolStatusView struct {
+	Pool     domain.PoolID       `json:"pool"`
+	Active   bool                `json:"active"`
+	Strategy domain.PoolStrategy `json:"strategy,omitempty"`
+	Members  []string            `json:"members"`
+
+	// configured is false for the placeholder shown before the default pool
+	// is created.
+	configured bool
 }
 
 func newPoolStatusCmd(app *app) *cobra.Command {
-	return &cobra.Command{
+	var format string
+	var all bool
+	var asTree bool
+	var poolID string
+
+	cmd := &cobra.Command{
 		Use:   "status",
-		Short: "Show default pool status",
+		Short: "Show default pool status (another pool with --pool, every pool with --all)",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			pool, err := app.poolService.GetPool(cmd.Context(), application.DefaultOpenAIPoolID)
+			outFormat, err := parseOutputFormat(format)
 			if err != nil {
-				if err == domain.ErrPoolNotFound {
-					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "pool: default-openai")
-					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "active: false")
-					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "members: none")
-					return nil
-				}
 				return err
 			}
+			if asTree && !all {
+				return errors.New("--tree requires --all")
+			}
+			if asTree && outFormat != outputFormatText {
+				return errors.New("--tree cannot be combined with --format or --json")
+			}
 
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "pool: %s\n", pool.ID)
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "active: %t\n", pool.Active)
-			if len(pool.Members) == 0 {
-				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "members: none")
-				return nil
+			// One GetStatusAll read serves every member; accounts that
+			// cannot be loaded fall back to their ID.
+			statuses := make(map[domain.AccountID]application.Status)
+			readStatuses := func() {
+				loaded, statusErr := app.service.GetStatusAll(cmd.Context())
+				if statusErr != nil {
+					return
+				}
+				for _, status := range loaded {
+					statuses[status.Account.ID] = status
+				}
 			}
 
-			members := make([]string, 0, len(pool.Members))
-			for _, member := range pool.Members {
-				status, statusErr := app.service.GetStatus(cmd.Context(), member)
-				if statusErr == nil && strings.TrimSpace(status.Account.Name) != "" {
-					members = append(members, sanitizeForTerminal(status.Account.Name))
-					continue
+			if all {
+				pools, err := app.poolService.ListPools(cmd.Context())
+				if err != nil {
+					return err
 				}
-				members = append(members, sanitizeForTerminal(string(member)))
+				readStatuses()
+
+				if asTree {
+					input := poolTreeInput{
+						pools:    pools,
+						statuses: statuses,
+						active:   make(map[domain.PoolID]domain.AccountID, len(pools)),
+					}
+					for _, pool := range pools {
+						activeID, err := app.continuityService.GetActiveAccountID(cmd.Context(), pool.ID)
+						if err != nil {
+							return err
+						}
+						input.active[pool.ID] = activeID
+					}
+					return writePoolTree(cmd.OutOrStdout(), input)
+				}
+
+				views := make([]poolStatusView, 0, len(pools))
+				for _, pool := range pools {
+					views = append(views, newPoolStatusView(pool, statuses))
+				}
+				if outFormat != outputFormatText {
+					return writeStructured(cmd.OutOrStdout(), outFormat, views)
+				}
+				if len(views) == 0 {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "no pools configured")
+					return nil
+				}
+				for i, view := range views {
+					if i > 0 {
+						_, _ = fmt.Fprintln(cmd.OutOrStdout())
+					}
+					writePoolStatusView(cmd.OutOrStdout(), view)
+				}
+				return nil
+			}
+
+			// The default pool shows as unconfigured until it is activated;
+			// any other pool must exist.
+			id := domain.PoolID(poolID)
+			view := poolStatusView{Pool: id, Members: []string{}}
+			pool, err := app.poolService.GetPool(cmd.Context(), id)
+			switch {
+			case err == domain.ErrPoolNotFound && id == application.DefaultOpenAIPoolID:
+			case err == domain.ErrPoolNotFound:
+				return fmt.Errorf("pool %s not found (run oa pool status --all)", id)
+			case err != nil:
+				return err
+			default:
+				readStatuses()
+				view = newPoolStatusView(pool, statuses)
 			}
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "members: %s\n", strings.Join(members, ", "))
+
+			if outFormat != outputFormatText {
+				return writeStructured(cmd.OutOrStdout(), outFormat, view)
+			}
+
+			writePoolStatusView(cmd.OutOrStdout(), view)
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Show every configured pool")
+	cmd.Flags().BoolVar(&asTree, "tree", false, "Render pools and their members as a tree (requires --all)")
+	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
+	cmd.MarkFlagsMutuallyExclusive("pool", "all")
+	bindFormatFlag(cmd, &format)
+
+	return cmd
+}
+
+func newPoolStatusView(pool domain.Pool, statuses map[domain.AccountID]application.Status) poolStatusView {
+	view := poolStatusView{Pool: pool.ID, Active: pool.Active, Strategy: pool.Strategy, Members: []string{}, configured: true}
+	for _, member := range pool.Members {
+		if name := strings.TrimSpace(statuses[member].Account.Name); name != "" {
+			view.Members = append(view.Members, sanitizeForTerminal(name))
+			continue
+		}
+		view.Members = append(view.Members, sanitizeForTerminal(string(member)))
+	}
+	return view
+}
+
+func writePoolStatusView(w io.Writer, view poolStatusView) {
+	_, _ = fmt.Fprintf(w, "pool: %s\n", view.Pool)
+	_, _ = fmt.Fprintf(w, "active: %t\n", view.Active)
+	switch {
+	case view.Strategy != "":
+		_, _ = fmt.Fprintf(w, "strategy: %s\n", view.Strategy)
+	case view.configured:
+		// Validate rejects this on save, but older pools.toml files may lack it.
+		_, _ = fmt.Fprintln(w, "strategy: none")
+		_, _ = fmt.Fprintf(w, "warning: pool %s has no strategy; set strategy = %q in pools.toml\n", view.Pool, domain.PoolStrategyLeastWeeklyUsed)
+	}
+	if len(view.Members) == 0 {
+		_, _ = fmt.Fprintln(w, "members: none")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "members: %s\n", strings.Join(view.Members, ", "))
 }
 
 func newPoolNextCmd(app *app) *cobra.Command {
 	var poolID string
+	var noSync bool
 
 	cmd := &cobra.Command{
 		Use:   "next",
@@ -113,6 +387,17 @@ func newPoolNextCmd(app *app) *cobra.Command {
 				return err
 			}
 
+			if current != "" {
+				remaining, err := app.continuityService.SwitchCooldownRemaining(cmd.Context(), domain.PoolID(poolID))
+				if err != nil {
+					return err
+				}
+				if remaining > 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Staying on account %s (switch cooldown: %s left)\n", current, remaining.Round(time.Second))
+					return nil
+				}
+			}
+
 			next, err := app.poolService.NextAccount(cmd.Context(), domain.PoolID(poolID), current)
 			if err != nil {
 				return err
@@ -122,8 +407,10 @@ func newPoolNextCmd(app *app) *cobra.Command {
 				return err
 			}
 
-			if err := syncOpencodeAuthForAccount(cmd.Context(), app, next); err != nil {
-				return err
+			if !noSync {
+				if err := syncOpencodeAuthForAccount(cmd.Context(), app, next); err != nil {
+					return err
+				}
 			}
 
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Switched to account %s\n", next)
@@ -132,6 +419,80 @@ func newPoolNextCmd(app *app) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
+	cmd.Flags().BoolVar(&noSync, "no-sync", false, "Do not update opencode auth.json")
+
+	return cmd
+}
+
+func newPoolCooldownCmd(app *app) *cobra.Command {
+	var poolID string
+
+	cmd := &cobra.Command{
+		Use:   "cooldown <duration>",
+		Short: "Set the minimum interval between pool next switches (0 disables)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			interval, err := time.ParseDuration(strings.TrimSpace(args[0]))
+			if err != nil {
+				return fmt.Errorf("parse cooldown: %w", err)
+			}
+			if err := app.continuityService.SetMinSwitchInterval(cmd.Context(), domain.PoolID(poolID), interval); err != nil {
+				return err
+			}
+
+			if interval == 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Disabled switch cooldown for pool %s\n", poolID)
+				return nil
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Set switch cooldown for pool %s to %s\n", poolID, interval)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
+
+	return cmd
+}
+
+func newPoolRuntimeCmd(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runtime",
+		Short: "Maintain pool runtime state (session ledgers)",
+	}
+
+	cmd.AddCommand(newPoolRuntimePruneCmd(app))
+
+	return cmd
+}
+
+func newPoolRuntimePruneCmd(app *app) *cobra.Command {
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Drop session ledgers inactive for longer than --older-than, across all pools",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			maxAge, err := parseLookback(olderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+			if maxAge == 0 {
+				return fmt.Errorf("--older-than must be greater than zero")
+			}
+
+			pruned, err := app.continuityService.PruneSessions(cmd.Context(), maxAge)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d session(s) inactive for more than %s\n", pruned, strings.TrimSpace(olderThan))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Inactivity TTL (e.g. 30d, 72h)")
+	_ = cmd.MarkFlagRequired("older-than")
 
 	return cmd
 }
@@ -139,6 +500,8 @@ func newPoolNextCmd(app *app) *cobra.Command {
 func newPoolSwitchCmd(app *app) *cobra.Command {
 	var poolID string
 	var accountSelector string
+	var noSync bool
+	var round bool
 
 	cmd := &cobra.Command{
 		Use:   "switch",
@@ -149,7 +512,12 @@ func newPoolSwitchCmd(app *app) *cobra.Command {
 				return err
 			}
 
-			target, err := resolveSwitchTarget(cmd, app, eligible, accountSelector)
+			var target domain.Account
+			if round {
+				target, err = nextRoundAccount(cmd, app, domain.PoolID(poolID), eligible)
+			} else {
+				target, err = resolveSwitchTarget(cmd, app, eligible, accountSelector)
+			}
 			if err != nil {
 				return err
 			}
@@ -158,8 +526,10 @@ func newPoolSwitchCmd(app *app) *cobra.Command {
 				return err
 			}
 
-			if err := syncOpencodeAuthForAccount(cmd.Context(), app, target.ID); err != nil {
-				return err
+			if !noSync {
+				if err := syncOpencodeAuthForAccount(cmd.Context(), app, target.ID); err != nil {
+					return err
+				}
 			}
 
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Switched to account %s\n", target.ID)
@@ -169,23 +539,49 @@ func newPoolSwitchCmd(app *app) *cobra.Command {
 
 	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
 	cmd.Flags().StringVar(&accountSelector, "account", "", "Target account ID or name")
+	cmd.Flags().BoolVar(&noSync, "no-sync", false, "Do not update opencode auth.json")
+	cmd.Flags().BoolVar(&round, "round", false, "Switch to the eligible account after the active one in pool member order, wrapping around")
+	cmd.MarkFlagsMutuallyExclusive("round", "account")
 
 	return cmd
 }
 
+// nextRoundAccount walks the pool members in their configured order from the
+// active account, so repeated calls visit every eligible account regardless
+// of usage. Without an eligible active account it starts at the first member.
+func nextRoundAccount(cmd *cobra.Command, app *app, poolID domain.PoolID, eligible []domain.Account) (domain.Account, error) {
+	pool, err := app.poolService.GetPool(cmd.Context(), poolID)
+	if err != nil {
+		return domain.Account{}, err
+	}
+	current, err := app.continuityService.GetActiveAccountID(cmd.Context(), poolID)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	byID := make(map[domain.AccountID]domain.Account, len(eligible))
+	for _, account := range eligible {
+		byID[account.ID] = account
+	}
+
+	start := slices.Index(pool.Members, current) + 1
+	for offset := range pool.Members {
+		member := pool.Members[(start+offset)%len(pool.Members)]
+		if account, ok := byID[member]; ok {
+			return account, nil
+		}
+	}
+	return domain.Account{}, fmt.Errorf("%w in pool %s", domain.ErrNoEligibleAccounts, poolID)
+}
+
 func resolveSwitchTarget(cmd *cobra.Command, app *app, eligible []domain.Account, selector string) (domain.Account, error) {
 	trimmed := strings.TrimSpace(selector)
 	if trimmed != "" {
-		for _, account := range eligible {
-			if string(account.ID) == trimmed {
-				return account, nil
-			}
-			name := displayAccountName(app, cmd, account)
-			if strings.EqualFold(name, trimmed) {
-				return account, nil
-			}
+		account, err := resolveAccountRef(eligible, trimmed)
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return domain.Account{}, fmt.Errorf("account %q is not eligible in pool", selector)
 		}
-		return domain.Account{}, fmt.Errorf("account %q is not eligible in pool", selector)
+		return account, err
 	}
 
 	for i, account := range eligible {
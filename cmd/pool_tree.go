@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/charmbracelet/lipgloss/tree"
+)
+
+// poolTreeInput is everything the pool tree needs, read once up front.
+type poolTreeInput struct {
+	pools    []domain.Pool
+	statuses map[domain.AccountID]application.Status
+	active   map[domain.PoolID]domain.AccountID
+}
+
+// writePoolTree renders every pool with its members. Accounts that belong to
+// more than one pool are listed under each of them and marked shared.
+func writePoolTree(w io.Writer, input poolTreeInput) error {
+	if len(input.pools) == 0 {
+		_, err := fmt.Fprintln(w, "no pools configured")
+		return err
+	}
+
+	memberships := make(map[domain.AccountID]int)
+	for _, pool := range input.pools {
+		for _, member := range pool.Members {
+			memberships[member]++
+		}
+	}
+
+	root := tree.Root("pools")
+	for _, pool := range input.pools {
+		state := "inactive"
+		if pool.Active {
+			state = "active"
+		}
+		branch := tree.Root(fmt.Sprintf("%s (%s)", sanitizeForTerminal(string(pool.ID)), state))
+		if len(pool.Members) == 0 {
+			branch.Child("no members")
+		}
+		for _, member := range pool.Members {
+			branch.Child(poolTreeMemberLabel(member, input.statuses[member], memberships[member] > 1, input.active[pool.ID] == member))
+		}
+		root.Child(branch)
+	}
+
+	_, err := fmt.Fprintln(w, root.String())
+	return err
+}
+
+func poolTreeMemberLabel(id domain.AccountID, status application.Status, shared, active bool) string {
+	label := sanitizeForTerminal(string(id))
+	if name := strings.TrimSpace(status.Account.Name); name != "" {
+		label = fmt.Sprintf("%s (%s)", sanitizeForTerminal(name), label)
+	}
+	if status.WeeklyLimit != nil {
+		label += fmt.Sprintf("  weekly %.0f%% used", status.WeeklyLimit.Percent)
+	}
+
+	var markers []string
+	if active {
+		markers = append(markers, "active")
+	}
+	if shared {
+		markers = append(markers, "shared")
+	}
+	if len(markers) > 0 {
+		label += "  [" + strings.Join(markers, ", ") + "]"
+	}
+	return label
+}
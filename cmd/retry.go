@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	authadapter "github.com/bnema/openai-accounts-cli/internal/adapters/auth"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultRetries      = 0
+	defaultRetryBackoff = time.Second
+)
+
+// retryPolicy is shared by every network call made while fetching usage:
+// the usage and subscription endpoints and OAuth token refresh.
+type retryPolicy struct {
+	Retries int
+	Backoff time.Duration
+}
+
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+func bindRetryFlags(cmd *cobra.Command, policy *retryPolicy) {
+	cmd.Flags().IntVar(&policy.Retries, "retries", defaultRetries, "Number of retries for transient network or server errors")
+	cmd.Flags().DurationVar(&policy.Backoff, "retry-backoff", defaultRetryBackoff, "Initial delay between retries, doubled after each attempt")
+}
+
+func (p retryPolicy) validate() error {
+	if p.Retries < 0 {
+		return fmt.Errorf("--retries must not be negative, got %d", p.Retries)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("--retry-backoff must not be negative, got %s", p.Backoff)
+	}
+	return nil
+}
+
+func (p retryPolicy) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	backoff := p.Backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.Retries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryableHTTPError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// isRetryableRefreshError retries a token refresh on the same failures as
+// isRetryableHTTPError: network errors, 429 and 5xx.
+func isRetryableRefreshError(err error) bool {
+	var statusErr *authadapter.RefreshStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isRetryableHTTPError(err)
+}
@@ -1,12 +1,24 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+	"github.com/spf13/cobra"
+)
 
 func Execute() error {
-	return newRootCmd().Execute()
+	rootCmd, release := newRootCmd(ports.SystemClock{})
+	defer release()
+	return rootCmd.Execute()
 }
 
-func newRootCmd() *cobra.Command {
+// newRootCmd returns the root command, whose services read time from clock,
+// and a release func that stops the --timeout deadline; call it once Execute
+// returns, whatever the outcome.
+func newRootCmd(clock ports.Clock) (*cobra.Command, context.CancelFunc) {
 	rootCmd := &cobra.Command{
 		Use:           "oa",
 		Short:         "OpenAI Accounts CLI (oa): manage auth and usage limits",
@@ -15,22 +27,52 @@ func newRootCmd() *cobra.Command {
 		SilenceErrors: false,
 	}
 
-	app, err := wireApp()
-	if err != nil {
-		rootCmd.RunE = func(_ *cobra.Command, _ []string) error {
+	var dryRun bool
+	var configDir string
+	var timeout time.Duration
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Log every file and secret write instead of performing it")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config", "", "Config directory holding accounts, pools and file secrets (default: $OA_CONFIG_DIR or ~/.codex)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the whole command after this long, e.g. 10s (default: no limit)")
+	wire := func(cmd *cobra.Command) (*app, error) {
+		return wireApp(wireOptions{dryRun: dryRun, configDir: configDir, stderr: cmd.ErrOrStderr(), clock: clock})
+	}
+
+	// Commands hold this pointer and only read it from RunE, so wiring can
+	// wait until the global flags are parsed.
+	app := &app{}
+	cancelTimeout := context.CancelFunc(func() {})
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		// login declares its own --timeout, which shadows this one.
+		if timeout < 0 {
+			return fmt.Errorf("--timeout must not be negative, got %s", timeout)
+		}
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+
+		wired, err := wire(cmd)
+		if err != nil {
 			return err
 		}
-		return rootCmd
+		*app = *wired
+		return nil
 	}
 
 	rootCmd.AddCommand(
 		newVersionCmd(),
 		newAccountCmd(app),
 		newAuthCmd(app),
+		newConfigCmd(app),
+		newDoctorCmd(app),
+		newMigrateCmd(app),
 		newPoolCmd(app),
 		newRunCmd(app),
+		newSecretCmd(app),
 		newUsageCmd(app),
 	)
+	registerAccountCompletion(rootCmd, wire)
 
-	return rootCmd
+	return rootCmd, func() { cancelTimeout() }
 }
@@ -1,18 +1,47 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/bnema/openai-accounts-cli/internal/application"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/spf13/cobra"
 )
 
+type runSelection struct {
+	PoolID            string   `json:"pool_id"`
+	AccountID         string   `json:"account_id"`
+	AccountName       string   `json:"account_name,omitempty"`
+	Model             string   `json:"model,omitempty"`
+	LogicalSessionID  string   `json:"logical_session_id"`
+	ProviderSessionID string   `json:"provider_session_id,omitempty"`
+	Command           []string `json:"command"`
+}
+
 func newRunCmd(app *app) *cobra.Command {
-	var poolID string
+	var (
+		poolID    string
+		asJSON    bool
+		allowSelf bool
+		pickOpts  application.PickOptions
+
+		memorySummary     string
+		memorySummaryFile string
+
+		watch            bool
+		rateLimitCodes   []int
+		rateLimitPattern string
+
+		verbose bool
+	)
 
 	cmd := &cobra.Command{
 		Use:                "run -- <command> [args...]",
@@ -25,64 +54,86 @@ func newRunCmd(app *app) *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var picked domain.AccountID
+			// The global --dry-run prints the selection instead of running.
+			dryRun := app.dryRun
+			if asJSON && !dryRun {
+				return errors.New("--json requires --dry-run")
+			}
+			if dryRun && (memorySummary != "" || memorySummaryFile != "") {
+				return errors.New("--memory-summary cannot be combined with --dry-run")
+			}
+			if watch && dryRun {
+				return errors.New("--watch cannot be combined with --dry-run")
+			}
+			if !watch && (cmd.Flags().Changed("rate-limit-exit-code") || cmd.Flags().Changed("rate-limit-pattern")) {
+				return errors.New("--rate-limit-exit-code and --rate-limit-pattern require --watch")
+			}
+			detector, err := newRateLimitDetector(rateLimitCodes, rateLimitPattern)
+			if err != nil {
+				return err
+			}
+			if !dryRun && !allowSelf && resolvesToSelf(args[0]) {
+				return fmt.Errorf("refusing to run %q: it resolves to this oa executable (pass --allow-self to override)", args[0])
+			}
 
-			active, err := app.continuityService.GetActiveAccountID(cmd.Context(), domain.PoolID(poolID))
+			picked, err := pickRunAccount(cmd, app, domain.PoolID(poolID), pickOpts)
 			if err != nil {
 				return err
 			}
-			if active != "" {
-				eligible, err := app.poolService.IsEligibleAccount(cmd.Context(), domain.PoolID(poolID), active)
-				if err != nil {
-					return err
-				}
-				if eligible {
-					picked = active
-				}
+
+			workspaceRoot, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("resolve workspace root: %w", err)
 			}
+			workspaceRoot = filepath.Clean(workspaceRoot)
+			windowFingerprint := envOrDefault("OA_WINDOW_FINGERPRINT", "default")
+			logicalSessionID := app.continuityService.ResolveLogicalSessionID(workspaceRoot, windowFingerprint)
 
-			if picked == "" {
-				picked, _, err = app.poolService.PickAccount(cmd.Context(), domain.PoolID(poolID))
+			if dryRun {
+				selection, err := dryRunSelection(cmd, app, domain.PoolID(poolID), picked, logicalSessionID, args)
 				if err != nil {
 					return err
 				}
+				return writeRunSelection(cmd, selection, asJSON)
 			}
 
-			if err := app.continuityService.SetActiveAccountID(cmd.Context(), domain.PoolID(poolID), picked); err != nil {
-				return err
+			stderrTail := &tailBuffer{limit: rateLimitStderrTail}
+			sessionLog := io.Discard
+			if verbose {
+				sessionLog = cmd.ErrOrStderr()
 			}
+			tried := map[domain.AccountID]bool{}
+			for {
+				tried[picked] = true
+				stderrTail.Reset()
+				runErr := runChildOnAccount(cmd, app, domain.PoolID(poolID), picked, logicalSessionID, args, stderrTail, sessionLog)
+				if runErr == nil {
+					break
+				}
+				if !watch || !detector.rateLimited(runErr, stderrTail.data) {
+					return runErr
+				}
 
-			if shouldSyncOpencodeAuth(args[0]) {
-				if err := syncOpencodeAuthForAccount(cmd.Context(), app, picked); err != nil {
+				next, ok, err := nextWatchAccount(cmd, app, domain.PoolID(poolID), pickOpts, tried)
+				if err != nil {
 					return err
 				}
+				if !ok {
+					return fmt.Errorf("%w (rate limited on every eligible account)", runErr)
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "oa: account %s is rate limited, re-running on account %s\n", picked, next)
+				picked = next
 			}
 
-			workspaceRoot, err := os.Getwd()
+			summary, err := resolveMemorySummary(memorySummary, memorySummaryFile)
 			if err != nil {
-				return fmt.Errorf("resolve workspace root: %w", err)
+				return err
 			}
-			workspaceRoot = filepath.Clean(workspaceRoot)
-			windowFingerprint := envOrDefault("OA_WINDOW_FINGERPRINT", "default")
-			logicalSessionID := app.continuityService.ResolveLogicalSessionID(workspaceRoot, windowFingerprint)
-			providerSessionID, _, err := app.continuityService.GetOrAttachAccountSession(cmd.Context(), domain.PoolID(poolID), logicalSessionID, picked)
-			if err != nil {
-				return fmt.Errorf("resolve provider session: %w", err)
+			if summary == "" {
+				return nil
 			}
-
-			child := exec.CommandContext(cmd.Context(), args[0], args[1:]...)
-			child.Stdout = cmd.OutOrStdout()
-			child.Stderr = cmd.ErrOrStderr()
-			child.Stdin = cmd.InOrStdin()
-			child.Env = append(os.Environ(),
-				"OA_POOL_ID="+poolID,
-				"OA_ACTIVE_ACCOUNT="+string(picked),
-				"OA_LOGICAL_SESSION_ID="+logicalSessionID,
-				"OA_PROVIDER_SESSION_ID="+providerSessionID,
-			)
-
-			if err := child.Run(); err != nil {
-				return fmt.Errorf("run child command: %w", err)
+			if err := app.continuityService.UpdateMemorySummary(cmd.Context(), domain.PoolID(poolID), logicalSessionID, summary); err != nil {
+				return fmt.Errorf("update session memory: %w", err)
 			}
 
 			return nil
@@ -90,6 +141,242 @@ func newRunCmd(app *app) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&poolID, "pool", string(application.DefaultOpenAIPoolID), "Pool ID")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Render the dry-run selection as JSON")
+	cmd.Flags().BoolVar(&allowSelf, "allow-self", false, "Allow the child command to be oa itself")
+	cmd.Flags().BoolVar(&pickOpts.RespectDaily, "respect-daily", false, "Skip accounts whose 5-hour window is exhausted")
+	cmd.Flags().StringVar(&memorySummary, "memory-summary", "", "Store this summary in the session memory after the command succeeds")
+	cmd.Flags().StringVar(&memorySummaryFile, "memory-summary-file", "", "Read the session memory summary from this file once the command succeeds")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Re-run the command on the next eligible account when it exits rate limited")
+	cmd.Flags().IntSliceVar(&rateLimitCodes, "rate-limit-exit-code", nil, "Child exit codes that mean the account is rate limited (with --watch)")
+	cmd.Flags().StringVar(&rateLimitPattern, "rate-limit-pattern", defaultRateLimitPattern, "Regex matched against the child's stderr to detect rate limiting (with --watch; empty disables)")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Log whether the provider session was reused or newly bootstrapped")
+	cmd.MarkFlagsMutuallyExclusive("memory-summary", "memory-summary-file")
 
 	return cmd
 }
+
+// runChildOnAccount makes account the active one, then runs the child with its
+// session env. Child stderr is also copied into stderrTail for rate-limit
+// detection; sessionLog receives whether the session was reused.
+func runChildOnAccount(cmd *cobra.Command, app *app, poolID domain.PoolID, account domain.AccountID, logicalSessionID string, args []string, stderrTail, sessionLog io.Writer) error {
+	if err := app.continuityService.SetActiveAccountID(cmd.Context(), poolID, account); err != nil {
+		return err
+	}
+
+	if shouldSyncOpencodeAuth(args[0]) {
+		if err := syncOpencodeAuthForAccount(cmd.Context(), app, account); err != nil {
+			return err
+		}
+	}
+
+	providerSessionID, bootstrapped, err := app.continuityService.GetOrAttachAccountSession(cmd.Context(), poolID, logicalSessionID, account)
+	if err != nil {
+		return fmt.Errorf("resolve provider session: %w", err)
+	}
+	if bootstrapped {
+		_, _ = fmt.Fprintf(sessionLog, "oa: bootstrapped new session %s for account %s\n", providerSessionID, account)
+	} else {
+		_, _ = fmt.Fprintf(sessionLog, "oa: reusing session %s for account %s\n", providerSessionID, account)
+	}
+
+	child := exec.CommandContext(cmd.Context(), args[0], args[1:]...)
+	child.Stdout = cmd.OutOrStdout()
+	child.Stderr = io.MultiWriter(cmd.ErrOrStderr(), stderrTail)
+	child.Stdin = cmd.InOrStdin()
+	child.Env = append(os.Environ(),
+		"OA_POOL_ID="+string(poolID),
+		"OA_ACTIVE_ACCOUNT="+string(account),
+		"OA_LOGICAL_SESSION_ID="+logicalSessionID,
+		"OA_PROVIDER_SESSION_ID="+providerSessionID,
+	)
+
+	if err := child.Run(); err != nil {
+		return fmt.Errorf("run child command: %w", err)
+	}
+	return nil
+}
+
+// resolveMemorySummary returns the post-run summary. The file is read only
+// after the child exits, so the child itself may write it.
+func resolveMemorySummary(summary, path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(summary), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read memory summary file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolvesToSelf reports whether command points at the running executable,
+// guarding against `oa run -- oa run -- ...` recursion.
+func resolvesToSelf(command string) bool {
+	childPath, err := exec.LookPath(command)
+	if err != nil {
+		return false
+	}
+	selfPath, err := os.Executable()
+	if err != nil {
+		return false
+	}
+
+	childInfo, err := os.Stat(childPath)
+	if err != nil {
+		return false
+	}
+	selfInfo, err := os.Stat(selfPath)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(childInfo, selfInfo)
+}
+
+func pickRunAccount(cmd *cobra.Command, app *app, poolID domain.PoolID, opts application.PickOptions) (domain.AccountID, error) {
+	active, err := app.continuityService.GetActiveAccountID(cmd.Context(), poolID)
+	if err != nil {
+		return "", err
+	}
+	if active != "" {
+		eligible, err := app.poolService.IsEligibleAccountWithOptions(cmd.Context(), poolID, active, opts)
+		if err != nil {
+			return "", err
+		}
+		if eligible {
+			return active, nil
+		}
+	}
+
+	picked, _, err := app.poolService.PickAccountWithOptions(cmd.Context(), poolID, opts)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoEligibleAccounts) {
+			return "", explainNoEligibleAccounts(cmd, app, poolID, opts, err)
+		}
+		return "", err
+	}
+
+	return picked, nil
+}
+
+// exhaustedAccount is a pool member held back by one or more used-up
+// windows; it is usable again once the last of them resets.
+type exhaustedAccount struct {
+	id           domain.AccountID
+	windows      []string
+	resetsAt     time.Time
+	resetUnknown bool
+}
+
+func (a *exhaustedAccount) exhaust(window string, resetsAt time.Time) {
+	a.windows = append(a.windows, window)
+	if resetsAt.IsZero() {
+		a.resetUnknown = true
+		return
+	}
+	if resetsAt.After(a.resetsAt) {
+		a.resetsAt = resetsAt
+	}
+}
+
+// explainNoEligibleAccounts turns the bare pick error into one that lists the
+// exhausted members and when the first of them becomes usable again.
+func explainNoEligibleAccounts(cmd *cobra.Command, app *app, poolID domain.PoolID, opts application.PickOptions, pickErr error) error {
+	pool, err := app.poolService.GetPool(cmd.Context(), poolID)
+	if err != nil {
+		return pickErr
+	}
+	if len(pool.Members) == 0 {
+		return fmt.Errorf("%w: the pool has no members (add accounts, then run `oa pool activate`)", pickErr)
+	}
+
+	respectDaily := opts.RespectDaily || pool.RespectDaily
+	now := app.clock.Now()
+	exhausted := make([]exhaustedAccount, 0, len(pool.Members))
+	for _, member := range pool.Members {
+		status, err := app.service.GetStatus(cmd.Context(), member)
+		if err != nil {
+			continue
+		}
+		account := exhaustedAccount{id: member}
+		if weekly := status.WeeklyLimit; weekly != nil && weekly.Percent >= 100 {
+			account.exhaust("weekly", weekly.ResetsAt)
+		}
+		if daily := status.DailyLimit; respectDaily && daily != nil && daily.Percent >= 100 && (daily.ResetsAt.IsZero() || daily.ResetsAt.After(now)) {
+			account.exhaust("5hours", daily.ResetsAt)
+		}
+		if len(account.windows) > 0 {
+			exhausted = append(exhausted, account)
+		}
+	}
+	if len(exhausted) == 0 {
+		return pickErr
+	}
+
+	parts := make([]string, 0, len(exhausted))
+	var soonest *exhaustedAccount
+	for i, account := range exhausted {
+		reset := "reset unknown"
+		if !account.resetUnknown {
+			reset = "resets " + account.resetsAt.UTC().Format("2006-01-02 15:04 MST")
+			if soonest == nil || account.resetsAt.Before(soonest.resetsAt) {
+				soonest = &exhausted[i]
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s, %s)", account.id, strings.Join(account.windows, " and "), reset))
+	}
+
+	detail := "exhausted: " + strings.Join(parts, ", ")
+	if soonest != nil {
+		detail += fmt.Sprintf("; soonest reset: account %s at %s", soonest.id, soonest.resetsAt.UTC().Format("2006-01-02 15:04 MST"))
+		if wait := soonest.resetsAt.Sub(now); wait > 0 {
+			detail += fmt.Sprintf(" (in %s)", wait.Round(time.Minute))
+		}
+	}
+	return fmt.Errorf("%w; %s", pickErr, detail)
+}
+
+func dryRunSelection(cmd *cobra.Command, app *app, poolID domain.PoolID, picked domain.AccountID, logicalSessionID string, args []string) (runSelection, error) {
+	status, err := app.service.GetStatus(cmd.Context(), picked)
+	if err != nil {
+		return runSelection{}, fmt.Errorf("load account %s: %w", picked, err)
+	}
+
+	providerSessionID, err := app.continuityService.LookupAccountSession(cmd.Context(), poolID, logicalSessionID, picked)
+	if err != nil {
+		return runSelection{}, fmt.Errorf("resolve provider session: %w", err)
+	}
+
+	return runSelection{
+		PoolID:            string(poolID),
+		AccountID:         string(picked),
+		AccountName:       status.Account.Name,
+		Model:             status.Account.Metadata.Model,
+		LogicalSessionID:  logicalSessionID,
+		ProviderSessionID: providerSessionID,
+		Command:           args,
+	}, nil
+}
+
+func writeRunSelection(cmd *cobra.Command, selection runSelection, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(selection)
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "pool: %s\n", selection.PoolID)
+	_, _ = fmt.Fprintf(out, "account: %s\n", selection.AccountID)
+	if selection.Model != "" {
+		_, _ = fmt.Fprintf(out, "model: %s\n", selection.Model)
+	}
+	_, _ = fmt.Fprintf(out, "logical session: %s\n", selection.LogicalSessionID)
+	providerSession := selection.ProviderSessionID
+	if providerSession == "" {
+		providerSession = "(new)"
+	}
+	_, _ = fmt.Fprintf(out, "provider session: %s\n", providerSession)
+	return nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// defaultRateLimitPattern matches the messages codex and opencode print when
+// an account runs out of quota.
+const defaultRateLimitPattern = `(?i)rate[ _-]?limit|too many requests|usage limit`
+
+// rateLimitStderrTail bounds how much child stderr is kept for matching.
+const rateLimitStderrTail = 64 * 1024
+
+// rateLimitDetector decides whether a failed child run was rate limited,
+// either by its exit code or by a pattern in its stderr.
+type rateLimitDetector struct {
+	exitCodes []int
+	pattern   *regexp.Regexp
+}
+
+func newRateLimitDetector(exitCodes []int, pattern string) (rateLimitDetector, error) {
+	detector := rateLimitDetector{exitCodes: exitCodes}
+	if pattern == "" {
+		return detector, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return rateLimitDetector{}, fmt.Errorf("parse --rate-limit-pattern: %w", err)
+	}
+	detector.pattern = re
+	return detector, nil
+}
+
+func (d rateLimitDetector) rateLimited(runErr error, stderr []byte) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return false
+	}
+	if slices.Contains(d.exitCodes, exitErr.ExitCode()) {
+		return true
+	}
+	return d.pattern != nil && d.pattern.Match(stderr)
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = b.data[over:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) Reset() {
+	b.data = b.data[:0]
+}
+
+// nextWatchAccount returns the first eligible account, in strategy order,
+// that has not been tried yet during this run.
+func nextWatchAccount(cmd *cobra.Command, app *app, poolID domain.PoolID, opts application.PickOptions, tried map[domain.AccountID]bool) (domain.AccountID, bool, error) {
+	eligible, err := app.poolService.EligibleAccountsWithOptions(cmd.Context(), poolID, opts)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoEligibleAccounts) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	for _, account := range eligible {
+		if !tried[account.ID] {
+			return account.ID, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+func newSecretCmd(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "secret",
+		Aliases: []string{"secrets"},
+		Short:   "Manage stored account secrets",
+	}
+
+	cmd.AddCommand(newSecretMigrateCmd(app), newSecretListCmd(app))
+
+	return cmd
+}
+
+func newSecretMigrateCmd(app *app) *cobra.Command {
+	var to string
+	var from string
+	var deleteSource bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move every account secret into one backend",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if deleteSource && from == "" {
+				return errors.New("--delete-source requires --from")
+			}
+
+			target, stale, err := splitSecretBackends(app.secretBackends, to)
+			if err != nil {
+				return err
+			}
+			if from != "" {
+				source, _, err := splitSecretBackends(app.secretBackends, from)
+				if err != nil {
+					return err
+				}
+				if source.name == target.name {
+					return fmt.Errorf("--from and --to are both %s", source.name)
+				}
+				return runSecretMove(cmd, app, source, target, deleteSource)
+			}
+
+			migrated, err := app.service.MigrateSecrets(cmd.Context(), target.store, stale)
+			for _, secretRef := range migrated {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Migrated %s to %s\n", secretRef, target.name)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Migrated %d secret(s) to %s\n", len(migrated), target.name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target secret backend (pass|file)")
+	cmd.Flags().StringVar(&from, "from", "", "Only copy the secrets this backend holds (pass|file), keeping the source copies unless --delete-source is set")
+	cmd.Flags().BoolVar(&deleteSource, "delete-source", false, "With --from, delete each secret from the source backend once copied")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+const (
+	secretStatusOK      = "OK"
+	secretStatusMissing = "MISSING"
+	secretStatusError   = "ERROR"
+)
+
+// secretListEntry is one secret ref an account points at. The secret value
+// itself is never part of it.
+type secretListEntry struct {
+	AccountID domain.AccountID `json:"account_id"`
+	Name      string           `json:"name,omitempty"`
+	Field     string           `json:"field"`
+	SecretRef string           `json:"secret_ref"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+}
+
+func newSecretListCmd(app *app) *cobra.Command {
+	var format string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every account secret ref and whether it resolves",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outFormat, err := resolveOutputFormat(format, asJSON)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := app.service.GetStatusAll(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			entries := make([]secretListEntry, 0, len(statuses))
+			for _, status := range statuses {
+				for _, entry := range accountSecretRefs(status.Account) {
+					if err := checkSecretRef(cmd.Context(), app, &entry); err != nil {
+						return err
+					}
+					entries = append(entries, entry)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if outFormat != outputFormatText {
+				return writeStructured(out, outFormat, entries)
+			}
+			if len(entries) == 0 {
+				_, _ = fmt.Fprintln(out, "no secret refs configured")
+				return nil
+			}
+			_, _ = fmt.Fprintln(out, strings.Join([]string{"ACCOUNT", "FIELD", "REF", "STATUS"}, "\t"))
+			for _, entry := range entries {
+				state := entry.Status
+				if entry.Error != "" {
+					state += " (" + sanitizeForTerminal(entry.Error) + ")"
+				}
+				_, _ = fmt.Fprintln(out, strings.Join([]string{string(entry.AccountID), entry.Field, sanitizeForTerminal(entry.SecretRef), state}, "\t"))
+			}
+			return nil
+		},
+	}
+
+	bindFormatFlag(cmd, &format)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Render JSON output (same as --format json)")
+
+	return cmd
+}
+
+// accountSecretRefs returns the account's auth and metadata secret refs,
+// leaving out empty refs and a metadata ref that repeats the auth one.
+func accountSecretRefs(account domain.Account) []secretListEntry {
+	authRef := strings.TrimSpace(account.Auth.SecretRef)
+	metadataRef := strings.TrimSpace(account.Metadata.SecretRef)
+
+	entries := make([]secretListEntry, 0, 2)
+	if authRef != "" {
+		entries = append(entries, secretListEntry{AccountID: account.ID, Name: account.Name, Field: "auth", SecretRef: authRef})
+	}
+	if metadataRef != "" && metadataRef != authRef {
+		entries = append(entries, secretListEntry{AccountID: account.ID, Name: account.Name, Field: "metadata", SecretRef: metadataRef})
+	}
+	return entries
+}
+
+// checkSecretRef reads the entry's ref through the secret store and records
+// whether it resolved. The ref is MISSING only when no backend has it; a
+// backend that failed for another reason makes it ERROR. Only a canceled or
+// timed out context is returned.
+func checkSecretRef(ctx context.Context, app *app, entry *secretListEntry) error {
+	_, err := app.secretStore.Get(ctx, entry.SecretRef)
+	switch {
+	case err == nil:
+		entry.Status = secretStatusOK
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, domain.ErrSecretNotFound):
+		entry.Status = secretStatusMissing
+	default:
+		entry.Status = secretStatusError
+		entry.Error = err.Error()
+	}
+	return nil
+}
+
+// runSecretMove copies the secrets held by source into target, reporting
+// the refs source does not have as skipped.
+func runSecretMove(cmd *cobra.Command, app *app, source, target secretBackend, deleteSource bool) error {
+	move, err := app.service.MoveSecrets(cmd.Context(), source.store, target.store, deleteSource)
+	out := cmd.OutOrStdout()
+	for _, secretRef := range move.Moved {
+		_, _ = fmt.Fprintf(out, "Moved %s from %s to %s\n", secretRef, source.name, target.name)
+	}
+	for _, secretRef := range move.Skipped {
+		_, _ = fmt.Fprintf(out, "Skipped %s (not in %s)\n", secretRef, source.name)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Moved %d secret(s) from %s to %s, skipped %d\n", len(move.Moved), source.name, target.name, len(move.Skipped))
+	return nil
+}
+
+func splitSecretBackends(backends []secretBackend, name string) (secretBackend, []ports.SecretStore, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	var (
+		target secretBackend
+		found  bool
+		stale  = make([]ports.SecretStore, 0, len(backends))
+		names  = make([]string, 0, len(backends))
+	)
+	for _, backend := range backends {
+		names = append(names, backend.name)
+		if backend.name == name {
+			target = backend
+			found = true
+			continue
+		}
+		stale = append(stale, backend.store)
+	}
+	if !found {
+		return secretBackend{}, nil, fmt.Errorf("unknown secret backend %q (valid backends: %s)", name, strings.Join(names, ", "))
+	}
+
+	return target, stale, nil
+}
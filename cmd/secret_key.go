@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+)
+
+const defaultSecretKeyTemplate = "openai://{account}/{kind}"
+
+const (
+	secretKindOAuthTokens = "oauth_tokens"
+	secretKindAPIKey      = "api_key"
+)
+
+// secretKeyTemplate builds secret-store keys from OA_SECRET_KEY_TEMPLATE, so
+// stored secrets can follow an existing pass layout such as
+// "codex/oa/accounts/{account}/{kind}".
+type secretKeyTemplate string
+
+func parseSecretKeyTemplate(raw string) (secretKeyTemplate, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return defaultSecretKeyTemplate, nil
+	}
+	if !strings.Contains(trimmed, "{account}") {
+		return "", fmt.Errorf("OA_SECRET_KEY_TEMPLATE %q must contain {account}", raw)
+	}
+	return secretKeyTemplate(trimmed), nil
+}
+
+func (t secretKeyTemplate) key(accountID domain.AccountID, kind string) string {
+	return strings.NewReplacer("{account}", string(accountID), "{kind}", kind).Replace(string(t))
+}
+
+func secretKindForMethod(method domain.AuthMethod) string {
+	if method == domain.AuthMethodChatGPT {
+		return secretKindOAuthTokens
+	}
+	return secretKindAPIKey
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,23 +10,58 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func writeStatusesOutput(cmd *cobra.Command, app *app, statuses []application.Status, staleAfter time.Duration, asJSON bool) error {
-	if asJSON {
-		enc := json.NewEncoder(cmd.OutOrStdout())
-		enc.SetIndent("", "  ")
-		return enc.Encode(statuses)
+type statusOutputOptions struct {
+	staleAfter  time.Duration
+	resetFormat statusadapter.ResetFormat
+	format      outputFormat
+	minWeekly   *float64
+	maxWeekly   *float64
+	precision   int
+	legacyJSON  bool
+	// minWeeklyPressure is the floor for the fleet-wide pressure warning.
+	minWeeklyPressure float64
+	recommendation    statusadapter.RecommendationMode
+	percentMode       statusadapter.PercentMode
+	// poolID selects whose active account the text output marks (default:
+	// the default OpenAI pool); noActive drops the marker.
+	poolID   domain.PoolID
+	noActive bool
+}
+
+func writeStatusesOutput(cmd *cobra.Command, app *app, statuses []application.Status, opts statusOutputOptions) error {
+	renderOpts := statusadapter.RenderOptions{
+		Now:                app.clock.Now(),
+		StaleAfter:         opts.staleAfter,
+		ResetFormat:        opts.resetFormat,
+		MinWeeklyLeft:      opts.minWeekly,
+		MaxWeeklyLeft:      opts.maxWeekly,
+		Precision:          opts.precision,
+		MinWeeklyPressure:  opts.minWeeklyPressure,
+		RecommendationMode: opts.recommendation,
+		PercentMode:        opts.percentMode,
 	}
 
-	activeAccountID, err := app.continuityService.GetActiveAccountID(cmd.Context(), application.DefaultOpenAIPoolID)
-	if err != nil {
-		return fmt.Errorf("load active pool account: %w", err)
+	if opts.format != outputFormatText {
+		filtered := statusadapter.FilterByWeeklyLeft(statuses, renderOpts)
+		if opts.legacyJSON {
+			return writeStructured(cmd.OutOrStdout(), opts.format, filtered)
+		}
+		return writeStructured(cmd.OutOrStdout(), opts.format, newStatusesView(filtered, renderOpts.Now))
+	}
+
+	if !opts.noActive {
+		poolID := opts.poolID
+		if poolID == "" {
+			poolID = application.DefaultOpenAIPoolID
+		}
+		activeAccountID, err := app.continuityService.GetActiveAccountID(cmd.Context(), poolID)
+		if err != nil {
+			return fmt.Errorf("load active pool account: %w", err)
+		}
+		renderOpts.ActiveAccountID = activeAccountID
 	}
 
-	rendered, err := app.statusRenderer(statuses, statusadapter.RenderOptions{
-		Now:             app.now(),
-		StaleAfter:      staleAfter,
-		ActiveAccountID: activeAccountID,
-	})
+	rendered, err := app.statusRenderer(statuses, renderOpts)
 	if err != nil {
 		return fmt.Errorf("render status: %w", err)
 	}
@@ -45,10 +79,24 @@ func loadStatuses(cmd *cobra.Command, svc *application.Service, accountID string
 		return statuses, nil
 	}
 
-	status, err := svc.GetStatus(cmd.Context(), domain.AccountID(accountID))
+	statuses, err := svc.GetStatusAll(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]domain.Account, 0, len(statuses))
+	for _, status := range statuses {
+		accounts = append(accounts, status.Account)
+	}
+	account, err := resolveAccountRef(accounts, accountID)
 	if err != nil {
 		return nil, err
 	}
 
-	return []application.Status{status}, nil
+	for _, status := range statuses {
+		if status.Account.ID == account.ID {
+			return []application.Status{status}, nil
+		}
+	}
+	return nil, accountNotFoundError{ref: accountID}
 }
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+)
+
+// statusSchemaVersion is bumped whenever a field of the structured status
+// output is renamed, removed or changes meaning. Adding fields keeps it.
+const statusSchemaVersion = 1
+
+// statusesView is the stable envelope for usage/status JSON and YAML output.
+// It is decoupled from application.Status so internals can change freely.
+type statusesView struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Accounts      []statusAccountView `json:"accounts"`
+}
+
+type statusAccountView struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Provider     string                  `json:"provider,omitempty"`
+	PlanType     string                  `json:"planType,omitempty"`
+	AuthMethod   string                  `json:"authMethod,omitempty"`
+	Tags         []string                `json:"tags,omitempty"`
+	Usage        statusUsageView         `json:"usage"`
+	Limits       statusLimitsView        `json:"limits"`
+	Subscription *statusSubscriptionView `json:"subscription,omitempty"`
+}
+
+type statusUsageView struct {
+	InputTokens       int64 `json:"inputTokens"`
+	OutputTokens      int64 `json:"outputTokens"`
+	CachedInputTokens int64 `json:"cachedInputTokens"`
+}
+
+type statusLimitsView struct {
+	Daily    *statusLimitView          `json:"daily"`
+	Weekly   *statusLimitView          `json:"weekly"`
+	Features []statusFeatureLimitsView `json:"features,omitempty"`
+}
+
+type statusFeatureLimitsView struct {
+	Feature string           `json:"feature"`
+	Daily   *statusLimitView `json:"daily,omitempty"`
+	Weekly  *statusLimitView `json:"weekly,omitempty"`
+}
+
+type statusLimitView struct {
+	PercentUsed       float64   `json:"percentUsed"`
+	LeftPercent       float64   `json:"leftPercent"`
+	ResetsAt          time.Time `json:"resetsAt"`
+	SecondsUntilReset int64     `json:"secondsUntilReset"`
+	CapturedAt        time.Time `json:"capturedAt"`
+}
+
+type statusSubscriptionView struct {
+	ActiveStart     time.Time `json:"activeStart"`
+	ActiveUntil     time.Time `json:"activeUntil"`
+	WillRenew       bool      `json:"willRenew"`
+	BillingPeriod   string    `json:"billingPeriod,omitempty"`
+	BillingCurrency string    `json:"billingCurrency,omitempty"`
+	IsDelinquent    bool      `json:"isDelinquent"`
+	CapturedAt      time.Time `json:"capturedAt"`
+}
+
+// newStatusesView computes countdowns against now so JSON consumers do not
+// have to redo the math.
+func newStatusesView(statuses []application.Status, now time.Time) statusesView {
+	view := statusesView{
+		SchemaVersion: statusSchemaVersion,
+		Accounts:      make([]statusAccountView, 0, len(statuses)),
+	}
+
+	for _, status := range statuses {
+		account := status.Account
+		entry := statusAccountView{
+			ID:         string(account.ID),
+			Name:       account.Name,
+			Provider:   account.Metadata.Provider,
+			PlanType:   account.Metadata.PlanType,
+			AuthMethod: string(account.Auth.Method),
+			Tags:       account.Metadata.Tags,
+			Usage: statusUsageView{
+				InputTokens:       status.Usage.InputTokens,
+				OutputTokens:      status.Usage.OutputTokens,
+				CachedInputTokens: status.Usage.CachedInputTokens,
+			},
+			Limits: statusLimitsView{
+				Daily:  newStatusLimitView(status.DailyLimit, now),
+				Weekly: newStatusLimitView(status.WeeklyLimit, now),
+			},
+		}
+		for _, feature := range status.FeatureLimits {
+			entry.Limits.Features = append(entry.Limits.Features, statusFeatureLimitsView{
+				Feature: feature.Feature,
+				Daily:   newStatusLimitView(feature.DailyLimit, now),
+				Weekly:  newStatusLimitView(feature.WeeklyLimit, now),
+			})
+		}
+		if sub := status.Subscription; sub != nil {
+			entry.Subscription = &statusSubscriptionView{
+				ActiveStart:     sub.ActiveStart,
+				ActiveUntil:     sub.ActiveUntil,
+				WillRenew:       sub.WillRenew,
+				BillingPeriod:   sub.BillingPeriod,
+				BillingCurrency: sub.BillingCurrency,
+				IsDelinquent:    sub.IsDelinquent,
+				CapturedAt:      sub.CapturedAt,
+			}
+		}
+		view.Accounts = append(view.Accounts, entry)
+	}
+
+	return view
+}
+
+func newStatusLimitView(limit *application.StatusLimit, now time.Time) *statusLimitView {
+	if limit == nil {
+		return nil
+	}
+
+	return &statusLimitView{
+		PercentUsed:       limit.Percent,
+		LeftPercent:       limit.LeftPercent(),
+		ResetsAt:          limit.ResetsAt,
+		SecondsUntilReset: limit.SecondsUntilReset(now),
+		CapturedAt:        limit.CapturedAt,
+	}
+}
@@ -13,17 +13,53 @@ import (
 	"time"
 
 	authadapter "github.com/bnema/openai-accounts-cli/internal/adapters/auth"
+	"github.com/bnema/openai-accounts-cli/internal/adapters/httpbody"
+	statusadapter "github.com/bnema/openai-accounts-cli/internal/adapters/render/status"
 	"github.com/bnema/openai-accounts-cli/internal/application"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/spf13/cobra"
 )
 
 var errUsageSessionExpired = errors.New("usage session expired")
+var errSessionExpired = errors.New("session expired")
 var refreshLocks sync.Map
 
+type usageOptions struct {
+	accountID         string
+	asJSON            bool
+	legacyJSON        bool
+	format            outputFormat
+	refreshIfStale    bool
+	failOnStale       bool
+	fetch             bool
+	noFetch           bool
+	plans             []string
+	resetFormat       statusadapter.ResetFormat
+	outputDelta       bool
+	deltaThreshold    float64
+	minWeekly         float64
+	maxWeekly         float64
+	precision         int
+	spinner           usageSpinnerOptions
+	parallelPools     bool
+	groupBy           string
+	minWeeklyPressure float64
+	recommendation    statusadapter.RecommendationMode
+	percentMode       statusadapter.PercentMode
+}
+
+const maxUsagePrecision = 3
+
+const usageStaleAfter = 6 * time.Hour
+
 func newUsageCmd(app *app) *cobra.Command {
-	var accountID string
-	var asJSON bool
+	var opts usageOptions
+	var resetFormat string
+	var format string
+	var retry retryPolicy
+	var spinnerStyle string
+	var recommendation string
+	var show string
 
 	cmd := &cobra.Command{
 		Use:     "usage",
@@ -31,12 +67,92 @@ func newUsageCmd(app *app) *cobra.Command {
 		Short:   "Fetch and display account usage limits",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runUsageFetch(cmd, app, accountID, asJSON)
+			if err := retry.validate(); err != nil {
+				return err
+			}
+			app.retry = retry
+			if err := validateWeeklyBounds(cmd, opts.minWeekly, opts.maxWeekly); err != nil {
+				return err
+			}
+			if opts.precision < 0 || opts.precision > maxUsagePrecision {
+				return fmt.Errorf("--precision must be between 0 and %d, got %d", maxUsagePrecision, opts.precision)
+			}
+			if opts.minWeeklyPressure < 0 {
+				return fmt.Errorf("--min-weekly-pressure must not be negative, got %g", opts.minWeeklyPressure)
+			}
+			if opts.deltaThreshold < 0 {
+				return fmt.Errorf("--delta-threshold must not be negative, got %g", opts.deltaThreshold)
+			}
+			resetFmt, err := statusadapter.ParseResetFormat(resetFormat)
+			if err != nil {
+				return err
+			}
+			opts.resetFormat = resetFmt
+			opts.recommendation, err = statusadapter.ParseRecommendationMode(recommendation)
+			if err != nil {
+				return err
+			}
+			opts.percentMode, err = statusadapter.ParsePercentMode(show)
+			if err != nil {
+				return err
+			}
+			opts.format, err = resolveOutputFormat(format, opts.asJSON || opts.legacyJSON)
+			if err != nil {
+				return err
+			}
+			opts.groupBy, err = parseUsageGroupBy(opts.groupBy)
+			if err != nil {
+				return err
+			}
+			opts.spinner.style, err = parseUsageSpinnerStyle(spinnerStyle)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("spinner-label") {
+				opts.spinner.label = envOrDefault("OA_SPINNER_LABEL", opts.spinner.label)
+			}
+			opts.spinner.disabled = opts.spinner.disabled || envEnabled("OA_NO_SPINNER")
+			if opts.parallelPools {
+				return runPoolsUsageFetch(cmd, app, opts)
+			}
+			return runUsageFetch(cmd, app, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&accountID, "account", "", "Account ID (default: all accounts)")
-	cmd.Flags().BoolVar(&asJSON, "json", false, "Render JSON output")
+	cmd.Flags().StringVar(&opts.accountID, "account", "", "Account ID or name; a unique name prefix also matches (default: all accounts)")
+	cmd.Flags().BoolVar(&opts.asJSON, "json", false, "Render JSON output (same as --format json)")
+	cmd.Flags().BoolVar(&opts.legacyJSON, "json-legacy", false, "Render the unversioned JSON layout used before schemaVersion 1 (deprecated)")
+	bindFormatFlag(cmd, &format)
+	cmd.Flags().BoolVar(&opts.refreshIfStale, "refresh-if-stale", false, "Only fetch accounts whose cached limits are older than the stale threshold")
+	cmd.Flags().BoolVar(&opts.fetch, "fetch", false, "Fetch every selected account, even when its cached limits are only minutes old")
+	cmd.Flags().BoolVar(&opts.noFetch, "no-fetch", false, "Render persisted snapshots only, without any network request")
+	cmd.MarkFlagsMutuallyExclusive("fetch", "no-fetch")
+	cmd.MarkFlagsMutuallyExclusive("fetch", "refresh-if-stale")
+	cmd.MarkFlagsMutuallyExclusive("no-fetch", "refresh-if-stale")
+	cmd.Flags().BoolVar(&opts.failOnStale, "fail-on-stale", false, "Exit with status 4 when any shown account has limits older than the stale threshold")
+	cmd.Flags().StringSliceVar(&opts.plans, "plan", nil, "Only fetch accounts on these plan types (e.g. pro,plus; unknown matches accounts without a plan)")
+	cmd.Flags().StringVar(&resetFormat, "reset-format", string(statusadapter.ResetFormatBoth), "How to show reset times (relative|absolute|both)")
+	cmd.Flags().Float64Var(&opts.minWeekly, "min-weekly", 0, "Only show accounts with at least this weekly percent left")
+	cmd.Flags().Float64Var(&opts.maxWeekly, "max-weekly", 100, "Only show accounts with at most this weekly percent left")
+	cmd.Flags().StringVar(&recommendation, "recommendation", string(statusadapter.RecommendationFull), "How much of the recommendation block to show (off|compact|full)")
+	cmd.Flags().StringVar(&show, "show", string(statusadapter.PercentLeft), "Percent shown on limit lines (used|left)")
+	cmd.Flags().IntVar(&opts.precision, "precision", 0, "Decimals shown for percent left (0-3)")
+	cmd.Flags().Float64Var(&opts.minWeeklyPressure, "min-weekly-pressure", 0, "Warn when even the recommended account has less weekly percent left per hour until reset (e.g. 0.6 is an even burn; 0 disables)")
+	cmd.Flags().BoolVar(&opts.outputDelta, "output-delta", false, "Print only limits whose percent changed since the previous fetch")
+	cmd.Flags().Float64Var(&opts.deltaThreshold, "delta-threshold", defaultUsageDeltaThreshold, "Minimum percent-point change reported by --output-delta")
+	cmd.Flags().BoolVar(&opts.parallelPools, "parallel-pools", false, "Refresh the members of every pool in one fetch (shared accounts once) and group the output by pool")
+	cmd.MarkFlagsMutuallyExclusive("parallel-pools", "account")
+	cmd.MarkFlagsMutuallyExclusive("parallel-pools", "output-delta")
+	cmd.MarkFlagsMutuallyExclusive("parallel-pools", "json-legacy")
+	cmd.Flags().StringVar(&opts.groupBy, "group-by", "", "Group the output by pool membership, with an unpooled group for the rest (pool)")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "output-delta")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "json-legacy")
+	cmd.Flags().BoolVar(&opts.spinner.disabled, "no-spinner", false, "Fetch without the progress spinner (or set OA_NO_SPINNER=1)")
+	cmd.Flags().StringVar(&opts.spinner.label, "spinner-label", defaultUsageSpinnerLabel, "Text shown next to the progress spinner (or set OA_SPINNER_LABEL)")
+	cmd.Flags().StringVar(&spinnerStyle, "spinner-style", "dot", "Progress spinner style (dot|line|minidot|jump|pulse|points|meter)")
+	bindRetryFlags(cmd, &retry)
+
+	cmd.AddCommand(newUsageHistoryCmd(app))
 
 	return cmd
 }
@@ -47,12 +163,15 @@ type tokenClaims struct {
 	APIAuth          struct {
 		ChatGPTAccountID string `json:"chatgpt_account_id"`
 	} `json:"https://api.openai.com/auth"`
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
 }
 
 type usageWindow struct {
 	UsedPercent        float64 `json:"used_percent"`
 	LimitWindowSeconds int     `json:"limit_window_seconds"`
 	ResetAt            int64   `json:"reset_at"`
+	ResetAfterSeconds  int64   `json:"reset_after_seconds"`
 }
 
 type usageRateLimit struct {
@@ -61,7 +180,16 @@ type usageRateLimit struct {
 }
 
 type usageAdditionalRateLimit struct {
-	RateLimit *usageRateLimit `json:"rate_limit"`
+	LimitName      string          `json:"limit_name"`
+	MeteredFeature string          `json:"metered_feature"`
+	RateLimit      *usageRateLimit `json:"rate_limit"`
+}
+
+func (l usageAdditionalRateLimit) feature() string {
+	if name := strings.TrimSpace(l.LimitName); name != "" {
+		return name
+	}
+	return strings.TrimSpace(l.MeteredFeature)
 }
 
 type usagePayload struct {
@@ -82,40 +210,198 @@ type subscriptionPayload struct {
 
 type fetchResult struct {
 	accountID domain.AccountID
+	update    *application.UsageUpdate
 	err       error
 }
 
-func runUsageFetch(cmd *cobra.Command, app *app, accountID string, asJSON bool) error {
-	statuses, err := loadStatuses(cmd, app.service, accountID)
+func runUsageFetch(cmd *cobra.Command, app *app, opts usageOptions) error {
+	statuses, err := loadStatuses(cmd, app.service, opts.accountID)
+	if err != nil {
+		return err
+	}
+	if opts.accountID != "" && len(statuses) == 1 {
+		// Pin the resolved ID: a fetch may rename the account away from the
+		// name the user passed.
+		opts.accountID = string(statuses[0].Account.ID)
+	}
+
+	if err := fetchUsageStatuses(cmd, app, opts, statuses); err != nil {
+		return err
+	}
+
+	updated, err := loadStatuses(cmd, app.service, opts.accountID)
 	if err != nil {
 		return err
 	}
 
+	if opts.outputDelta {
+		if err := writeUsageDeltas(cmd.OutOrStdout(), usageDeltas(statuses, updated, opts.deltaThreshold), opts.format); err != nil {
+			return err
+		}
+		return checkStaleStatuses(opts, updated, app.clock.Now())
+	}
+
+	if opts.groupBy == usageGroupByPool {
+		pools, err := app.poolService.ListPools(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("list pools: %w", err)
+		}
+		return writeGroupedStatusesOutput(cmd, app, groupStatusesByPool(pools, updated, true), opts)
+	}
+
+	outputOpts := newUsageStatusOutputOptions(cmd, opts)
+	if err := writeStatusesOutput(cmd, app, updated, outputOpts); err != nil {
+		return err
+	}
+	shown := statusadapter.FilterByWeeklyLeft(updated, statusadapter.RenderOptions{
+		MinWeeklyLeft: outputOpts.minWeekly,
+		MaxWeeklyLeft: outputOpts.maxWeekly,
+	})
+	return checkStaleStatuses(opts, shown, app.clock.Now())
+}
+
+// fetchUsageStatuses refreshes the chatgpt accounts among statuses that the
+// fetch flags select, behind the spinner in text mode.
+func fetchUsageStatuses(cmd *cobra.Command, app *app, opts usageOptions, statuses []application.Status) error {
 	chatgptAccounts := filterChatGPTAccounts(statuses)
+	if opts.noFetch {
+		chatgptAccounts = nil
+	}
+	if len(opts.plans) > 0 {
+		chatgptAccounts = filterAccountsByPlan(chatgptAccounts, opts.plans)
+	}
+	if opts.refreshIfStale {
+		chatgptAccounts = filterStaleAccounts(statuses, chatgptAccounts, app.clock.Now(), usageStaleAfter)
+	}
 
 	fetchCmd := func(ctx context.Context) error {
-		if len(chatgptAccounts) == 0 {
-			return nil
-		}
-		return fetchAccountsConcurrently(ctx, app, chatgptAccounts, cmd.ErrOrStderr())
+		return fetchAccountsConcurrently(ctx, app, chatgptAccounts, opts.fetch, cmd.ErrOrStderr())
 	}
 
-	if asJSON {
+	switch {
+	case len(chatgptAccounts) == 0:
+	case opts.format != outputFormatText, opts.spinner.disabled:
 		if err := fetchCmd(cmd.Context()); err != nil {
 			return err
 		}
-	} else {
-		if err := runUsageFetchSpinner(cmd.Context(), cmd.ErrOrStderr(), fetchCmd); err != nil {
+	default:
+		if err := runUsageFetchSpinner(cmd.Context(), cmd.ErrOrStderr(), opts.spinner, fetchCmd); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	updated, err := loadStatuses(cmd, app.service, accountID)
-	if err != nil {
-		return err
+func newUsageStatusOutputOptions(cmd *cobra.Command, opts usageOptions) statusOutputOptions {
+	outputOpts := statusOutputOptions{
+		staleAfter:        usageStaleAfter,
+		resetFormat:       opts.resetFormat,
+		format:            opts.format,
+		precision:         opts.precision,
+		legacyJSON:        opts.legacyJSON,
+		minWeeklyPressure: opts.minWeeklyPressure,
+		recommendation:    opts.recommendation,
+		percentMode:       opts.percentMode,
+	}
+	if cmd.Flags().Changed("min-weekly") {
+		outputOpts.minWeekly = &opts.minWeekly
+	}
+	if cmd.Flags().Changed("max-weekly") {
+		outputOpts.maxWeekly = &opts.maxWeekly
+	}
+	return outputOpts
+}
+
+// checkStaleStatuses enforces --fail-on-stale against the same per-limit rule
+// the renderer uses for its [stale] marker.
+func checkStaleStatuses(opts usageOptions, statuses []application.Status, now time.Time) error {
+	if !opts.failOnStale {
+		return nil
 	}
 
-	return writeStatusesOutput(cmd, app, updated, 6*time.Hour, asJSON)
+	var stale []domain.AccountID
+	for _, status := range statuses {
+		if hasStaleLimit(status, now, usageStaleAfter) {
+			stale = append(stale, status.Account.ID)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return staleDataError{accounts: stale, staleAfter: usageStaleAfter}
+}
+
+func hasStaleLimit(status application.Status, now time.Time, staleAfter time.Duration) bool {
+	limits := []*application.StatusLimit{status.DailyLimit, status.WeeklyLimit}
+	for _, feature := range status.FeatureLimits {
+		limits = append(limits, feature.DailyLimit, feature.WeeklyLimit)
+	}
+	for _, limit := range limits {
+		if limit != nil && (domain.LimitSnapshot{AsOf: limit.CapturedAt}).IsStale(now, staleAfter) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWeeklyBounds(cmd *cobra.Command, minWeekly, maxWeekly float64) error {
+	for _, bound := range []struct {
+		flag  string
+		value float64
+	}{{"min-weekly", minWeekly}, {"max-weekly", maxWeekly}} {
+		if cmd.Flags().Changed(bound.flag) && (bound.value < 0 || bound.value > 100) {
+			return fmt.Errorf("--%s must be between 0 and 100, got %g", bound.flag, bound.value)
+		}
+	}
+	if cmd.Flags().Changed("min-weekly") && cmd.Flags().Changed("max-weekly") && minWeekly > maxWeekly {
+		return fmt.Errorf("--min-weekly (%g) must not exceed --max-weekly (%g)", minWeekly, maxWeekly)
+	}
+	return nil
+}
+
+func filterStaleAccounts(statuses []application.Status, accounts []domain.Account, now time.Time, staleAfter time.Duration) []domain.Account {
+	capturedAt := make(map[domain.AccountID]time.Time, len(statuses))
+	for _, status := range statuses {
+		capturedAt[status.Account.ID] = latestCapturedAt(status)
+	}
+
+	stale := make([]domain.Account, 0, len(accounts))
+	for _, account := range accounts {
+		latest := capturedAt[account.ID]
+		if latest.IsZero() || now.Sub(latest) > staleAfter {
+			stale = append(stale, account)
+		}
+	}
+	return stale
+}
+
+func latestCapturedAt(status application.Status) time.Time {
+	var latest time.Time
+	for _, limit := range []*application.StatusLimit{status.DailyLimit, status.WeeklyLimit} {
+		if limit != nil && limit.CapturedAt.After(latest) {
+			latest = limit.CapturedAt
+		}
+	}
+	return latest
+}
+
+func filterAccountsByPlan(accounts []domain.Account, plans []string) []domain.Account {
+	wanted := make(map[string]struct{}, len(plans))
+	for _, plan := range plans {
+		wanted[domain.NormalizePlanType(plan)] = struct{}{}
+	}
+
+	filtered := make([]domain.Account, 0, len(accounts))
+	for _, account := range accounts {
+		plan := domain.NormalizePlanType(account.Metadata.PlanType)
+		if plan == "" {
+			plan = "unknown"
+		}
+		if _, ok := wanted[plan]; ok {
+			filtered = append(filtered, account)
+		}
+	}
+	return filtered
 }
 
 func filterChatGPTAccounts(statuses []application.Status) []domain.Account {
@@ -128,7 +414,10 @@ func filterChatGPTAccounts(statuses []application.Status) []domain.Account {
 	return accounts
 }
 
-func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.Account, errWriter io.Writer) error {
+// fetchAccountsConcurrently fetches and persists usage for accounts. With
+// bypassCache set, accounts fetched within the last few minutes are fetched
+// again instead of being skipped.
+func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.Account, bypassCache bool, errWriter io.Writer) error {
 	const maxConcurrent = 5
 	results := make(chan fetchResult, len(accounts))
 	semaphore := make(chan struct{}, maxConcurrent)
@@ -148,8 +437,12 @@ func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.
 				return
 			}
 
-			err := fetchAndPersistLimits(ctx, app, acc)
-			results <- fetchResult{accountID: acc.ID, err: err}
+			fetch := fetchAccountLimits
+			if bypassCache {
+				fetch = fetchAccountLimitsUncached
+			}
+			update, err := fetch(ctx, app, acc)
+			results <- fetchResult{accountID: acc.ID, update: update, err: err}
 		}(account)
 	}
 
@@ -158,17 +451,37 @@ func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.
 		close(results)
 	}()
 
-	var successes []domain.AccountID
+	var fetched []fetchResult
 	var failures []fetchResult
+	var updates []application.UsageUpdate
 
 	for result := range results {
 		if result.err == nil {
-			successes = append(successes, result.accountID)
+			fetched = append(fetched, result)
+			if result.update != nil {
+				updates = append(updates, *result.update)
+			}
 		} else {
 			failures = append(failures, result)
 		}
 	}
 
+	skipped, err := app.service.ApplyUsageUpdates(ctx, updates)
+	if err != nil {
+		return fmt.Errorf("persist usage updates: %w", err)
+	}
+	skippedIDs := make(map[domain.AccountID]bool, len(skipped))
+	for _, skip := range skipped {
+		skippedIDs[skip.AccountID] = true
+		failures = append(failures, fetchResult{accountID: skip.AccountID, err: fmt.Errorf("account %s: store usage: %w", skip.AccountID, skip.Err)})
+	}
+	var successes []domain.AccountID
+	for _, result := range fetched {
+		if !skippedIDs[result.accountID] {
+			successes = append(successes, result.accountID)
+		}
+	}
+
 	if len(failures) > 0 {
 		fmt.Fprintln(errWriter, "\nFailed to fetch:")
 		for _, failure := range failures {
@@ -180,7 +493,7 @@ func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.
 		if len(accounts) == 1 {
 			return failures[0].err
 		}
-		return fmt.Errorf("all accounts failed to fetch")
+		return newFetchFailuresError(failures)
 	}
 
 	if len(successes) > 0 && len(failures) > 0 {
@@ -190,147 +503,145 @@ func fetchAccountsConcurrently(ctx context.Context, app *app, accounts []domain.
 	return nil
 }
 
-func fetchAndPersistLimits(ctx context.Context, app *app, account domain.Account) error {
+func fetchAccountLimits(ctx context.Context, app *app, account domain.Account) (*application.UsageUpdate, error) {
 	// Check if we have fresh data (within 5 minutes)
 	// Reload account from repository to get the latest persisted state
 	const cacheDuration = 5 * time.Minute
-	currentTime := app.now()
+	currentTime := app.clock.Now()
 
 	status, err := app.service.GetStatus(ctx, account.ID)
 	if err != nil {
 		// If we can't load status, proceed with fetch
-		return fetchAndPersistLimitsUncached(ctx, app, account)
+		return fetchAccountLimitsUncached(ctx, app, account)
 	}
 
 	// Check the most recent capture time across all limits
-	var mostRecent time.Time
-	if status.DailyLimit != nil && !status.DailyLimit.CapturedAt.IsZero() {
-		mostRecent = status.DailyLimit.CapturedAt
-	}
-	if status.WeeklyLimit != nil && !status.WeeklyLimit.CapturedAt.IsZero() {
-		if mostRecent.IsZero() || status.WeeklyLimit.CapturedAt.After(mostRecent) {
-			mostRecent = status.WeeklyLimit.CapturedAt
-		}
-	}
+	mostRecent := latestCapturedAt(status)
 
 	// Skip fetch if we have recent data
 	if !mostRecent.IsZero() && currentTime.Sub(mostRecent) < cacheDuration {
-		return nil // Skip fetch, data is fresh
+		return nil, nil // Skip fetch, data is fresh
 	}
 
-	return fetchAndPersistLimitsUncached(ctx, app, account)
+	return fetchAccountLimitsUncached(ctx, app, account)
 }
 
-func fetchAndPersistLimitsUncached(ctx context.Context, app *app, account domain.Account) error {
-
+func fetchAccountLimitsUncached(ctx context.Context, app *app, account domain.Account) (*application.UsageUpdate, error) {
 	secretRef := strings.TrimSpace(account.Auth.SecretRef)
 	if secretRef == "" {
-		return fmt.Errorf("account %s: auth secret reference is empty", account.ID)
+		return nil, fmt.Errorf("account %s: auth secret reference is empty", account.ID)
 	}
 
 	secretValue, err := app.secretStore.Get(ctx, secretRef)
 	if err != nil {
-		return fmt.Errorf("account %s: load auth secret: %w", account.ID, err)
+		return nil, fmt.Errorf("account %s: load auth secret: %w", account.ID, err)
 	}
 
 	tokens, err := decodeOAuthTokens(secretValue)
 	if err != nil {
-		return fmt.Errorf("account %s: %w", account.ID, err)
+		return nil, fmt.Errorf("account %s: %w", account.ID, err)
 	}
 
 	tokens, err = ensureFreshTokens(ctx, app, account, tokens, false)
 	if err != nil {
 		if errors.Is(err, authadapter.ErrRefreshTokenInvalid) {
-			return fmt.Errorf("%s: session expired, please re-login with `oa auth login browser --account %s`", usageAccountLabel(account, tokens), account.ID)
+			return nil, sessionExpiredError(account, tokens)
 		}
-		return fmt.Errorf("account %s: refresh oauth tokens: %w", account.ID, err)
+		return nil, fmt.Errorf("account %s: refresh oauth tokens: %w", account.ID, err)
 	}
 
 	claims := parseTokenClaims(tokens.IDToken)
 
-	payload, err := fetchUsagePayload(ctx, app.httpClient, app.usageBaseURL, tokens)
+	payload, err := fetchUsageWithRetry(ctx, app, account, tokens)
 	if err != nil {
 		if errors.Is(err, errUsageSessionExpired) {
 			staleToken := tokens.AccessToken
 			tokens, err = ensureFreshTokens(ctx, app, account, tokens, true)
 			if err != nil {
 				if errors.Is(err, authadapter.ErrRefreshTokenInvalid) {
-					return fmt.Errorf("%s: session expired, please re-login with `oa auth login browser --account %s`", usageAccountLabel(account, tokens), account.ID)
+					return nil, sessionExpiredError(account, tokens)
 				}
-				return fmt.Errorf("account %s: refresh oauth tokens after unauthorized usage response: %w", account.ID, err)
+				return nil, fmt.Errorf("account %s: refresh oauth tokens after unauthorized usage response: %w", account.ID, err)
 			}
 			if strings.TrimSpace(tokens.AccessToken) == strings.TrimSpace(staleToken) {
-				return fmt.Errorf("%s: session expired, please re-login with `oa auth login browser --account %s`", usageAccountLabel(account, tokens), account.ID)
+				return nil, sessionExpiredError(account, tokens)
 			}
-			payload, err = fetchUsagePayload(ctx, app.httpClient, app.usageBaseURL, tokens)
+			payload, err = fetchUsageWithRetry(ctx, app, account, tokens)
 			if err != nil {
 				if errors.Is(err, errUsageSessionExpired) {
-					return fmt.Errorf("%s: session expired, please re-login with `oa auth login browser --account %s`", usageAccountLabel(account, tokens), account.ID)
+					return nil, sessionExpiredError(account, tokens)
 				}
-				return fmt.Errorf("account %s: fetch usage after refresh: %w", account.ID, err)
+				return nil, fmt.Errorf("account %s: fetch usage after refresh: %w", account.ID, err)
 			}
 		} else {
-			return fmt.Errorf("account %s: fetch usage: %w", account.ID, err)
+			return nil, fmt.Errorf("account %s: fetch usage: %w", account.ID, err)
 		}
 	}
 
+	now := app.clock.Now()
+	resolveWindowResets(payload, now)
 	daily, weekly := pickDailyWeeklyWindows(payload)
 	if daily == nil && weekly == nil {
-		return fmt.Errorf("account %s: missing limit snapshots in usage payload", account.ID)
+		return nil, fmt.Errorf("account %s: missing limit snapshots in usage payload", account.ID)
 	}
 
-	now := app.now()
-	if daily != nil {
-		if err := app.service.SetLimit(ctx, account.ID, "daily", daily.UsedPercent, time.Unix(daily.ResetAt, 0).UTC(), now); err != nil {
-			return fmt.Errorf("account %s: save daily limit snapshot: %w", account.ID, err)
-		}
+	update := &application.UsageUpdate{
+		AccountID: account.ID,
+		Limits:    limitUpdates(daily, weekly, now),
 	}
-	if weekly != nil {
-		if err := app.service.SetLimit(ctx, account.ID, "weekly", weekly.UsedPercent, time.Unix(weekly.ResetAt, 0).UTC(), now); err != nil {
-			return fmt.Errorf("account %s: save weekly limit snapshot: %w", account.ID, err)
+	for _, additional := range payload.AdditionalRateLimits {
+		feature := additional.feature()
+		if feature == "" || additional.RateLimit == nil {
+			continue
 		}
+		featureDaily, featureWeekly := pickWindows([]*usageWindow{additional.RateLimit.PrimaryWindow, additional.RateLimit.SecondaryWindow})
+		if featureDaily == nil && featureWeekly == nil {
+			continue
+		}
+		update.FeatureLimits = append(update.FeatureLimits, application.FeatureLimitUpdate{
+			Feature: feature,
+			Limits:  limitUpdates(featureDaily, featureWeekly, now),
+		})
+	}
+
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("account %s: usage payload: %w", account.ID, err)
 	}
 
 	if email := strings.TrimSpace(claims.Email); email != "" && account.Name != email {
-		if err := app.service.SetAccountName(ctx, account.ID, email); err != nil {
-			return fmt.Errorf("account %s: save account name from token email: %w", account.ID, err)
-		}
+		update.Name = email
 	}
 
 	if planType := strings.TrimSpace(payload.PlanType); planType != "" && account.Metadata.PlanType != planType {
-		if err := app.service.SetAccountPlanType(ctx, account.ID, planType); err != nil {
-			return fmt.Errorf("account %s: save account plan type: %w", account.ID, err)
-		}
+		update.PlanType = planType
 	}
 
-	subPayload, subErr := fetchSubscriptionPayload(ctx, app.httpClient, app.usageBaseURL, tokens)
+	subPayload, subErr := fetchSubscriptionWithRetry(ctx, app, account, tokens)
 	if errors.Is(subErr, errUsageSessionExpired) {
 		staleToken := tokens.AccessToken
 		tokens, err = ensureFreshTokens(ctx, app, account, tokens, true)
 		if err == nil && strings.TrimSpace(tokens.AccessToken) != strings.TrimSpace(staleToken) {
-			subPayload, subErr = fetchSubscriptionPayload(ctx, app.httpClient, app.usageBaseURL, tokens)
+			subPayload, subErr = fetchSubscriptionWithRetry(ctx, app, account, tokens)
 		}
 	}
 	if subErr == nil {
 		activeStart, _ := time.Parse(time.RFC3339, subPayload.ActiveStart)
 		activeUntil, _ := time.Parse(time.RFC3339, subPayload.ActiveUntil)
-		sub := domain.Subscription{
+		update.Subscription = &domain.Subscription{
 			ActiveStart:     activeStart,
 			ActiveUntil:     activeUntil,
 			WillRenew:       subPayload.WillRenew,
 			BillingPeriod:   subPayload.BillingPeriod,
 			BillingCurrency: subPayload.BillingCurrency,
 			IsDelinquent:    subPayload.IsDelinquent,
-		}
-		if err := app.service.SetSubscription(ctx, account.ID, sub); err != nil {
-			return fmt.Errorf("account %s: save subscription: %w", account.ID, err)
+			CapturedAt:      now,
 		}
 	}
 
-	return nil
+	return update, nil
 }
 
-func fetchUsagePayload(ctx context.Context, client *http.Client, baseURL string, tokens oauthTokens) (usagePayload, error) {
+func fetchUsagePayload(ctx context.Context, client *http.Client, maxBytes int64, baseURL string, tokens oauthTokens) (usagePayload, error) {
 	endpoint := strings.TrimRight(baseURL, "/") + "/wham/usage"
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -348,7 +659,7 @@ func fetchUsagePayload(ctx context.Context, client *http.Client, baseURL string,
 	}
 	defer response.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	body, err := httpbody.ReadAll(response.Body, maxBytes)
 	if err != nil {
 		return usagePayload{}, fmt.Errorf("read response: %w", err)
 	}
@@ -356,7 +667,7 @@ func fetchUsagePayload(ctx context.Context, client *http.Client, baseURL string,
 		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
 			return usagePayload{}, fmt.Errorf("%w: status %d: %s", errUsageSessionExpired, response.StatusCode, strings.TrimSpace(string(body)))
 		}
-		return usagePayload{}, fmt.Errorf("status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+		return usagePayload{}, &httpStatusError{StatusCode: response.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	var payload usagePayload
@@ -367,7 +678,35 @@ func fetchUsagePayload(ctx context.Context, client *http.Client, baseURL string,
 	return payload, nil
 }
 
-func fetchSubscriptionPayload(ctx context.Context, client *http.Client, baseURL string, tokens oauthTokens) (subscriptionPayload, error) {
+// accountUsageBaseURL prefers the account's own base URL over the global one.
+func accountUsageBaseURL(app *app, account domain.Account) string {
+	if baseURL := strings.TrimSpace(account.Metadata.BaseURL); baseURL != "" {
+		return baseURL
+	}
+	return app.usageBaseURL
+}
+
+func fetchUsageWithRetry(ctx context.Context, app *app, account domain.Account, tokens oauthTokens) (usagePayload, error) {
+	var payload usagePayload
+	err := app.retry.do(ctx, isRetryableHTTPError, func() error {
+		var err error
+		payload, err = fetchUsagePayload(ctx, app.httpClient, app.maxResponseBytes, accountUsageBaseURL(app, account), tokens)
+		return err
+	})
+	return payload, err
+}
+
+func fetchSubscriptionWithRetry(ctx context.Context, app *app, account domain.Account, tokens oauthTokens) (subscriptionPayload, error) {
+	var payload subscriptionPayload
+	err := app.retry.do(ctx, isRetryableHTTPError, func() error {
+		var err error
+		payload, err = fetchSubscriptionPayload(ctx, app.httpClient, app.maxResponseBytes, accountUsageBaseURL(app, account), tokens)
+		return err
+	})
+	return payload, err
+}
+
+func fetchSubscriptionPayload(ctx context.Context, client *http.Client, maxBytes int64, baseURL string, tokens oauthTokens) (subscriptionPayload, error) {
 	accountID := accountIDFromToken(tokens.IDToken)
 
 	endpoint := strings.TrimRight(baseURL, "/") + "/subscriptions"
@@ -388,7 +727,7 @@ func fetchSubscriptionPayload(ctx context.Context, client *http.Client, baseURL
 	}
 	defer response.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	body, err := httpbody.ReadAll(response.Body, maxBytes)
 	if err != nil {
 		return subscriptionPayload{}, fmt.Errorf("read response: %w", err)
 	}
@@ -396,7 +735,7 @@ func fetchSubscriptionPayload(ctx context.Context, client *http.Client, baseURL
 		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
 			return subscriptionPayload{}, fmt.Errorf("%w: status %d: %s", errUsageSessionExpired, response.StatusCode, strings.TrimSpace(string(body)))
 		}
-		return subscriptionPayload{}, fmt.Errorf("status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+		return subscriptionPayload{}, &httpStatusError{StatusCode: response.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	var payload subscriptionPayload
@@ -428,12 +767,24 @@ func ensureFreshTokens(ctx context.Context, app *app, account domain.Account, ex
 	if err != nil {
 		return existing, fmt.Errorf("account %s: %w", account.ID, err)
 	}
+	if reconciled := reconcileTokenExpiry(storedTokens, app.clock.Now()); reconciled.ExpiresAt != storedTokens.ExpiresAt {
+		storedTokens = reconciled
+		// Pin the computed expiry so later runs do not count expires_in from
+		// their own now. Read-only stores keep working off this run's value.
+		encoded, err := encodeOAuthTokens(storedTokens)
+		if err == nil {
+			err = app.secretStore.Put(ctx, secretRef, encoded)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(app.stderr, "warning: account %s: store computed token expiry: %v\n", account.ID, err)
+		}
+	}
 
 	if force {
 		if staleAccessToken != "" && strings.TrimSpace(storedTokens.AccessToken) != "" && strings.TrimSpace(storedTokens.AccessToken) != staleAccessToken {
 			return storedTokens, nil
 		}
-	} else if !tokenExpiringSoon(storedTokens, app.now(), proactiveRefreshSkew) {
+	} else if !tokenExpiringSoon(storedTokens, app.clock.Now(), proactiveRefreshSkew) {
 		return storedTokens, nil
 	}
 
@@ -441,10 +792,26 @@ func ensureFreshTokens(ctx context.Context, app *app, account domain.Account, ex
 		return storedTokens, fmt.Errorf("%w: refresh_token missing", authadapter.ErrRefreshTokenInvalid)
 	}
 
-	refreshed, err := authadapter.RefreshTokens(app.httpClient, authadapter.RefreshTokenRequest{
-		Issuer:       app.browserLogin.Issuer,
-		ClientID:     app.browserLogin.ClientID,
-		RefreshToken: storedTokens.RefreshToken,
+	// A refresh rotates the refresh token, and dry-run would drop the new
+	// one instead of storing it, so keep working off the stored tokens.
+	if app.dryRun {
+		app.dryRunLog.printf("would refresh oauth tokens for account %s", account.ID)
+		if force {
+			return storedTokens, fmt.Errorf("account %s: access token was rejected and --dry-run does not refresh it", account.ID)
+		}
+		return storedTokens, nil
+	}
+
+	var refreshed authadapter.ExchangedTokens
+	err = app.retry.do(ctx, isRetryableRefreshError, func() error {
+		var refreshErr error
+		refreshed, refreshErr = authadapter.RefreshTokens(app.httpClient, authadapter.RefreshTokenRequest{
+			Issuer:           app.browserLogin.Issuer,
+			ClientID:         app.browserLogin.ClientID,
+			RefreshToken:     storedTokens.RefreshToken,
+			MaxResponseBytes: app.maxResponseBytes,
+		})
+		return refreshErr
 	})
 	if err != nil {
 		return storedTokens, err
@@ -463,7 +830,7 @@ func ensureFreshTokens(ctx context.Context, app *app, account domain.Account, ex
 	if strings.TrimSpace(updated.IDToken) == "" {
 		updated.IDToken = storedTokens.IDToken
 	}
-	updated = withCalculatedExpiry(updated, app.now())
+	updated = withCalculatedExpiry(updated, app.clock.Now())
 
 	encoded, err := encodeOAuthTokens(updated)
 	if err != nil {
@@ -494,6 +861,10 @@ func usageAccountLabel(account domain.Account, tokens oauthTokens) string {
 	return fmt.Sprintf("account %s (%s, %s)", id, email, classification)
 }
 
+func sessionExpiredError(account domain.Account, tokens oauthTokens) error {
+	return fmt.Errorf("%s: %w, please re-login with `oa auth login browser --account %s`", usageAccountLabel(account, tokens), errSessionExpired, account.ID)
+}
+
 func accountIDFromToken(token string) string {
 	claims := parseTokenClaims(token)
 
@@ -523,8 +894,40 @@ func parseTokenClaims(token string) tokenClaims {
 	return claims
 }
 
+func limitUpdates(daily, weekly *usageWindow, capturedAt time.Time) []application.LimitUpdate {
+	updates := make([]application.LimitUpdate, 0, 2)
+	if daily != nil {
+		updates = append(updates, application.LimitUpdate{
+			Window:     application.LimitWindowDaily,
+			Percent:    daily.UsedPercent,
+			ResetsAt:   time.Unix(daily.ResetAt, 0).UTC(),
+			CapturedAt: capturedAt,
+		})
+	}
+	if weekly != nil {
+		updates = append(updates, application.LimitUpdate{
+			Window:     application.LimitWindowWeekly,
+			Percent:    weekly.UsedPercent,
+			ResetsAt:   time.Unix(weekly.ResetAt, 0).UTC(),
+			CapturedAt: capturedAt,
+		})
+	}
+	return updates
+}
+
+// pickDailyWeeklyWindows bins the top-level rate limit into daily/weekly
+// windows. Additional (per-feature) limits are only used as a fallback when
+// the top-level rate limit reports nothing.
 func pickDailyWeeklyWindows(payload usagePayload) (*usageWindow, *usageWindow) {
-	windows := collectWindows(payload)
+	daily, weekly := pickWindows(collectWindows(payload))
+	if daily != nil || weekly != nil {
+		return daily, weekly
+	}
+
+	return pickWindows(collectAdditionalWindows(payload))
+}
+
+func pickWindows(windows []*usageWindow) (*usageWindow, *usageWindow) {
 	var daily *usageWindow
 	var weekly *usageWindow
 
@@ -550,22 +953,37 @@ func pickDailyWeeklyWindows(payload usagePayload) (*usageWindow, *usageWindow) {
 }
 
 func collectWindows(payload usagePayload) []*usageWindow {
-	windows := make([]*usageWindow, 0, 8)
-	appendRateLimitWindows := func(limit *usageRateLimit) {
-		if limit == nil {
-			return
-		}
-		windows = append(windows, limit.PrimaryWindow, limit.SecondaryWindow)
+	if payload.RateLimit == nil {
+		return nil
 	}
 
-	appendRateLimitWindows(payload.RateLimit)
+	return []*usageWindow{payload.RateLimit.PrimaryWindow, payload.RateLimit.SecondaryWindow}
+}
+
+func collectAdditionalWindows(payload usagePayload) []*usageWindow {
+	windows := make([]*usageWindow, 0, 2*len(payload.AdditionalRateLimits))
 	for _, additional := range payload.AdditionalRateLimits {
-		appendRateLimitWindows(additional.RateLimit)
+		if additional.RateLimit == nil {
+			continue
+		}
+		windows = append(windows, additional.RateLimit.PrimaryWindow, additional.RateLimit.SecondaryWindow)
 	}
 
 	return windows
 }
 
+// resolveWindowResets fills in reset_at from reset_after_seconds for windows
+// that only report the countdown, which some responses do for additional
+// limits when the top-level rate_limit is null.
+func resolveWindowResets(payload usagePayload, now time.Time) {
+	windows := append(collectWindows(payload), collectAdditionalWindows(payload)...)
+	for _, window := range windows {
+		if window != nil && window.ResetAt <= 0 && window.ResetAfterSeconds > 0 {
+			window.ResetAt = now.Unix() + window.ResetAfterSeconds
+		}
+	}
+}
+
 func isWeeklyWindow(seconds int) bool {
 	return seconds >= 6*24*60*60
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+)
+
+const defaultUsageDeltaThreshold = 1.0
+
+type usageDelta struct {
+	AccountID domain.AccountID `json:"account_id"`
+	Name      string           `json:"name"`
+	Window    string           `json:"window"`
+	Before    *float64         `json:"before,omitempty"`
+	After     float64          `json:"after"`
+}
+
+// usageDeltas compares the limit snapshots persisted before a fetch with the
+// fresh ones and keeps the windows that moved by at least threshold points.
+func usageDeltas(before, after []application.Status, threshold float64) []usageDelta {
+	previous := make(map[domain.AccountID]application.Status, len(before))
+	for _, status := range before {
+		previous[status.Account.ID] = status
+	}
+
+	deltas := make([]usageDelta, 0)
+	for _, status := range after {
+		old := previous[status.Account.ID]
+		windows := []struct {
+			label  string
+			before *application.StatusLimit
+			after  *application.StatusLimit
+		}{
+			{label: "5hours", before: old.DailyLimit, after: status.DailyLimit},
+			{label: "weekly", before: old.WeeklyLimit, after: status.WeeklyLimit},
+		}
+
+		for _, window := range windows {
+			if window.after == nil {
+				continue
+			}
+			delta := usageDelta{
+				AccountID: status.Account.ID,
+				Name:      status.Account.Name,
+				Window:    window.label,
+				After:     window.after.Percent,
+			}
+			if window.before != nil {
+				if math.Abs(window.after.Percent-window.before.Percent) < threshold {
+					continue
+				}
+				percent := window.before.Percent
+				delta.Before = &percent
+			}
+			deltas = append(deltas, delta)
+		}
+	}
+
+	return deltas
+}
+
+func writeUsageDeltas(w io.Writer, deltas []usageDelta, format outputFormat) error {
+	if format != outputFormatText {
+		return writeStructured(w, format, deltas)
+	}
+
+	if len(deltas) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	for _, delta := range deltas {
+		before := "new"
+		if delta.Before != nil {
+			before = fmt.Sprintf("%.0f%%", *delta.Before)
+		}
+		label := string(delta.AccountID)
+		if delta.Name != "" && delta.Name != label {
+			label = fmt.Sprintf("%s (%s)", delta.Name, delta.AccountID)
+		}
+		if _, err := fmt.Fprintf(w, "%s %s: %s -> %.0f%% used\n", label, delta.Window, before, delta.After); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	authadapter "github.com/bnema/openai-accounts-cli/internal/adapters/auth"
+)
+
+// fetchFailuresError reports that every account failed to fetch, summarizing
+// the failure reasons while keeping each one reachable via errors.Is/As.
+type fetchFailuresError struct {
+	total   int
+	summary string
+	errs    []error
+}
+
+func newFetchFailuresError(failures []fetchResult) error {
+	counts := map[string]int{}
+	errs := make([]error, 0, len(failures))
+	for _, failure := range failures {
+		counts[fetchFailureCategory(failure.err)]++
+		errs = append(errs, failure.err)
+	}
+
+	categories := make([]string, 0, len(counts))
+	for category := range counts {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if counts[categories[i]] != counts[categories[j]] {
+			return counts[categories[i]] > counts[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
+	}
+
+	return &fetchFailuresError{total: len(failures), summary: strings.Join(parts, ", "), errs: errs}
+}
+
+func (e *fetchFailuresError) Error() string {
+	return fmt.Sprintf("all %d accounts failed: %s", e.total, e.summary)
+}
+
+func (e *fetchFailuresError) Unwrap() []error {
+	return e.errs
+}
+
+func fetchFailureCategory(err error) string {
+	var statusErr *httpStatusError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, errSessionExpired), errors.Is(err, errUsageSessionExpired), errors.Is(err, authadapter.ErrRefreshTokenInvalid):
+		return "session expired"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("HTTP %d", statusErr.StatusCode)
+	case errors.As(err, &urlErr):
+		return "network error"
+	default:
+		return "other error"
+	}
+}
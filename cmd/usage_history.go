@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+type usageHistoryEntry struct {
+	Window     string    `json:"window"`
+	Percent    float64   `json:"percent"`
+	ResetsAt   time.Time `json:"resets_at"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+func newUsageHistoryCmd(app *app) *cobra.Command {
+	var accountRef string
+	var sinceRaw string
+	var untilRaw string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recorded usage history for an account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+			now := app.clock.Now()
+			since, err := parseHistoryBound(sinceRaw, now)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			until, err := parseHistoryBound(untilRaw, now)
+			if err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+			if !since.IsZero() && !until.IsZero() && until.Before(since) {
+				return fmt.Errorf("--until must not be before --since")
+			}
+
+			statuses, err := loadStatuses(cmd, app.service, accountRef)
+			if err != nil {
+				return err
+			}
+			history, err := app.service.UsageHistory(cmd.Context(), statuses[0].Account.ID, since, until)
+			if err != nil {
+				return err
+			}
+
+			return writeUsageHistory(cmd.OutOrStdout(), history, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVar(&accountRef, "account", "", "Account ID or name; a unique name prefix also matches")
+	cmd.Flags().StringVar(&sinceRaw, "since", "", "Only show entries captured at or after this time (e.g. 7d, 12h, 2026-03-01, RFC3339)")
+	cmd.Flags().StringVar(&untilRaw, "until", "", "Only show entries captured at or before this time (same forms as --since)")
+	bindFormatFlag(cmd, &format)
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
+
+// parseHistoryBound accepts a lookback relative to now ("7d", "36h") or an
+// absolute date or RFC3339 timestamp. An empty value means no bound.
+func parseHistoryBound(raw string, now time.Time) (time.Time, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return time.Time{}, nil
+	}
+
+	if lookback, err := parseLookback(trimmed); err == nil {
+		return now.Add(-lookback), nil
+	}
+	if at, err := time.Parse(time.RFC3339, trimmed); err == nil {
+		return at, nil
+	}
+	if at, err := time.ParseInLocation(time.DateOnly, trimmed, time.UTC); err == nil {
+		return at, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a duration (7d, 12h), date (2006-01-02) or RFC3339 time", trimmed)
+}
+
+func writeUsageHistory(w io.Writer, history []domain.UsageHistoryEntry, format outputFormat) error {
+	entries := make([]usageHistoryEntry, 0, len(history))
+	for _, entry := range history {
+		entries = append(entries, usageHistoryEntry{
+			Window:     usageHistoryWindowLabel(entry.Window),
+			Percent:    entry.Percent,
+			ResetsAt:   entry.ResetsAt,
+			CapturedAt: entry.CapturedAt,
+		})
+	}
+
+	if format != outputFormatText {
+		return writeStructured(w, format, entries)
+	}
+
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "no usage history")
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%s  %-7s %3.0f%% used\n", entry.CapturedAt.UTC().Format("2006-01-02 15:04 UTC"), entry.Window, entry.Percent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func usageHistoryWindowLabel(window string) string {
+	if window == string(application.LimitWindowDaily) {
+		return "5hours"
+	}
+	return window
+}
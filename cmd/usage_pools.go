@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	statusadapter "github.com/bnema/openai-accounts-cli/internal/adapters/render/status"
+	"github.com/bnema/openai-accounts-cli/internal/application"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+const usageGroupByPool = "pool"
+
+// poolsStatusesView is the structured output of grouped usage: the usual
+// account views, grouped under each pool.
+type poolsStatusesView struct {
+	SchemaVersion int                `json:"schemaVersion"`
+	Pools         []poolStatusesView `json:"pools"`
+}
+
+type poolStatusesView struct {
+	Pool     string              `json:"pool"`
+	Accounts []statusAccountView `json:"accounts"`
+}
+
+// statusGroup is one pool's member statuses, in member order. The unpooled
+// group collects accounts that belong to no pool.
+type statusGroup struct {
+	pool     domain.PoolID
+	unpooled bool
+	statuses []application.Status
+}
+
+func (g statusGroup) label() string {
+	if g.unpooled {
+		return string(application.UnpooledGroupID)
+	}
+	return string(g.pool)
+}
+
+func parseUsageGroupBy(raw string) (string, error) {
+	groupBy := strings.ToLower(strings.TrimSpace(raw))
+	if groupBy != "" && groupBy != usageGroupByPool {
+		return "", fmt.Errorf("unsupported --group-by %q (valid: %s)", raw, usageGroupByPool)
+	}
+	return groupBy, nil
+}
+
+// runPoolsUsageFetch refreshes every account that belongs to at least one pool
+// in a single concurrent fetch, so an account shared by several pools is only
+// fetched once, then renders the statuses grouped by pool.
+func runPoolsUsageFetch(cmd *cobra.Command, app *app, opts usageOptions) error {
+	pools, err := app.poolService.ListPools(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("list pools: %w", err)
+	}
+
+	statuses, err := app.service.GetStatusAll(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := fetchUsageStatuses(cmd, app, opts, poolMemberStatuses(pools, statuses)); err != nil {
+		return err
+	}
+
+	updated, err := app.service.GetStatusAll(cmd.Context())
+	if err != nil {
+		return err
+	}
+	groups := groupStatusesByPool(pools, updated, opts.groupBy == usageGroupByPool)
+	return writeGroupedStatusesOutput(cmd, app, groups, opts)
+}
+
+// writeGroupedStatusesOutput renders each non-empty group under a pool header,
+// marking that pool's active account, then applies --fail-on-stale.
+func writeGroupedStatusesOutput(cmd *cobra.Command, app *app, groups []statusGroup, opts usageOptions) error {
+	now := app.clock.Now()
+	outputOpts := newUsageStatusOutputOptions(cmd, opts)
+	filterOpts := statusadapter.RenderOptions{MinWeeklyLeft: outputOpts.minWeekly, MaxWeeklyLeft: outputOpts.maxWeekly}
+
+	var shown []application.Status
+	for i := range groups {
+		groups[i].statuses = statusadapter.FilterByWeeklyLeft(groups[i].statuses, filterOpts)
+		shown = append(shown, groups[i].statuses...)
+	}
+
+	if opts.format != outputFormatText {
+		view := poolsStatusesView{SchemaVersion: statusSchemaVersion, Pools: make([]poolStatusesView, 0, len(groups))}
+		for _, group := range groups {
+			view.Pools = append(view.Pools, poolStatusesView{Pool: group.label(), Accounts: newStatusesView(group.statuses, now).Accounts})
+		}
+		if err := writeStructured(cmd.OutOrStdout(), opts.format, view); err != nil {
+			return err
+		}
+		return checkStaleStatuses(opts, shown, now)
+	}
+
+	out := cmd.OutOrStdout()
+	written := 0
+	for _, group := range groups {
+		if len(group.statuses) == 0 {
+			continue
+		}
+		if written > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		written++
+		_, _ = fmt.Fprintf(out, "pool: %s\n", group.label())
+		outputOpts.poolID = group.pool
+		outputOpts.noActive = group.unpooled
+		if err := writeStatusesOutput(cmd, app, group.statuses, outputOpts); err != nil {
+			return err
+		}
+	}
+	if written == 0 {
+		_, _ = fmt.Fprintln(out, "no pooled accounts to show")
+	}
+	return checkStaleStatuses(opts, shown, now)
+}
+
+// groupStatusesByPool places each status under every pool it is a member of.
+// With withUnpooled set, accounts in no pool form a trailing unpooled group.
+func groupStatusesByPool(pools []domain.Pool, statuses []application.Status, withUnpooled bool) []statusGroup {
+	byID := make(map[domain.AccountID]application.Status, len(statuses))
+	for _, status := range statuses {
+		byID[status.Account.ID] = status
+	}
+
+	pooled := make(map[domain.AccountID]struct{})
+	groups := make([]statusGroup, 0, len(pools)+1)
+	for _, pool := range pools {
+		group := statusGroup{pool: pool.ID, statuses: make([]application.Status, 0, len(pool.Members))}
+		for _, member := range pool.Members {
+			pooled[member] = struct{}{}
+			if status, ok := byID[member]; ok {
+				group.statuses = append(group.statuses, status)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	if withUnpooled {
+		unpooled := statusGroup{unpooled: true}
+		for _, status := range statuses {
+			if _, ok := pooled[status.Account.ID]; !ok {
+				unpooled.statuses = append(unpooled.statuses, status)
+			}
+		}
+		groups = append(groups, unpooled)
+	}
+	return groups
+}
+
+// poolMemberStatuses returns the statuses of accounts in at least one pool,
+// each account once.
+func poolMemberStatuses(pools []domain.Pool, statuses []application.Status) []application.Status {
+	members := make(map[domain.AccountID]struct{})
+	for _, pool := range pools {
+		for _, member := range pool.Members {
+			members[member] = struct{}{}
+		}
+	}
+
+	selected := make([]application.Status, 0, len(members))
+	for _, status := range statuses {
+		if _, ok := members[status.Account.ID]; ok {
+			selected = append(selected, status)
+		}
+	}
+	return selected
+}
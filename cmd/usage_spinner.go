@@ -4,12 +4,55 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+const defaultUsageSpinnerLabel = "Fetching usage limits..."
+
+var usageSpinnerStyles = map[string]spinner.Spinner{
+	"dot":     spinner.Dot,
+	"line":    spinner.Line,
+	"minidot": spinner.MiniDot,
+	"jump":    spinner.Jump,
+	"pulse":   spinner.Pulse,
+	"points":  spinner.Points,
+	"meter":   spinner.Meter,
+}
+
+// usageSpinnerOptions controls the progress spinner shown while text-mode
+// usage fetches run. A disabled spinner fetches directly with no output.
+type usageSpinnerOptions struct {
+	label    string
+	style    spinner.Spinner
+	disabled bool
+}
+
+func parseUsageSpinnerStyle(raw string) (spinner.Spinner, error) {
+	style, ok := usageSpinnerStyles[strings.ToLower(strings.TrimSpace(raw))]
+	if !ok {
+		names := make([]string, 0, len(usageSpinnerStyles))
+		for name := range usageSpinnerStyles {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return spinner.Spinner{}, fmt.Errorf("unsupported spinner style %q (valid: %s)", raw, strings.Join(names, ", "))
+	}
+	return style, nil
+}
+
+// envEnabled reports whether key holds a true boolean such as 1 or true.
+func envEnabled(key string) bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	return err == nil && enabled
+}
+
 type usageFetchDoneMsg struct {
 	err error
 }
@@ -22,9 +65,9 @@ type usageFetchSpinnerModel struct {
 	done    bool
 }
 
-func newUsageFetchSpinnerModel(label string, fetch tea.Cmd) usageFetchSpinnerModel {
+func newUsageFetchSpinnerModel(label string, style spinner.Spinner, fetch tea.Cmd) usageFetchSpinnerModel {
 	s := spinner.New(
-		spinner.WithSpinner(spinner.Dot),
+		spinner.WithSpinner(style),
 		spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("69"))),
 	)
 
@@ -62,13 +105,13 @@ func (m usageFetchSpinnerModel) View() string {
 	return fmt.Sprintf("%s %s", m.spinner.View(), m.label)
 }
 
-func runUsageFetchSpinner(ctx context.Context, output io.Writer, fetch func(context.Context) error) error {
+func runUsageFetchSpinner(ctx context.Context, output io.Writer, opts usageSpinnerOptions, fetch func(context.Context) error) error {
 	fetchCmd := func() tea.Msg {
 		return usageFetchDoneMsg{err: fetch(ctx)}
 	}
 
 	p := tea.NewProgram(
-		newUsageFetchSpinnerModel("Fetching usage limits...", fetchCmd),
+		newUsageFetchSpinnerModel(opts.label, opts.style, fetchCmd),
 		tea.WithInput(nil),
 		tea.WithOutput(output),
 		tea.WithContext(ctx),
@@ -1,16 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	authadapter "github.com/bnema/openai-accounts-cli/internal/adapters/auth"
+	"github.com/bnema/openai-accounts-cli/internal/adapters/httpbody"
 	statusadapter "github.com/bnema/openai-accounts-cli/internal/adapters/render/status"
 	tomlrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/toml"
 	chainstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/chain"
+	cmdstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/cmd"
+	filestore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/file"
+	passstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/pass"
 	"github.com/bnema/openai-accounts-cli/internal/application"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
 	"github.com/spf13/viper"
@@ -18,68 +27,204 @@ import (
 
 var errNotImplementedYet = errors.New("not implemented yet")
 
+const defaultLoginTimeout = 5 * time.Minute
+
+// loginLockStaleAfter is how old a login lock may get before another login
+// treats it as left behind by a crashed one. Login timeouts stay below it.
+const loginLockStaleAfter = time.Hour
+
 type app struct {
 	service           *application.Service
 	poolService       *application.PoolService
 	continuityService *application.SessionContinuityService
+	accountsFile      accountsFile
+	configStores      []configStore
+	configDir         string
 	secretStore       ports.SecretStore
+	secretBackends    []secretBackend
 	statusRenderer    func([]application.Status, statusadapter.RenderOptions) (string, error)
 	browserLogin      browserLoginConfig
 	usageBaseURL      string
 	httpClient        *http.Client
-	now               func() time.Time
+	maxResponseBytes  int64
+	retry             retryPolicy
+	secretKeys        secretKeyTemplate
+	clock             ports.Clock
+	dryRun            bool
+	dryRunLog         dryRunLog
+	stderr            io.Writer
+}
+
+// wireOptions carries the global flags that change how the app is wired.
+type wireOptions struct {
+	dryRun bool
+	// configDir overrides $OA_CONFIG_DIR when set.
+	configDir string
+	// stderr receives warnings and the --dry-run log; nil discards them.
+	stderr io.Writer
+	// clock is the time source for services and token expiry; nil uses the
+	// system clock.
+	clock ports.Clock
+}
+
+// accountsFile is the on-disk accounts store as seen by commands that edit it
+// directly.
+type accountsFile interface {
+	Path() string
+	Edit(ctx context.Context, edit func(path string) error) error
+}
+
+// configStore is a store under the config directory that can hold off its
+// writers while the directory is copied.
+type configStore interface {
+	Hold(ctx context.Context, fn func() error) error
+}
+
+type secretBackend struct {
+	name  string
+	store ports.SecretStore
 }
 
 type browserLoginConfig struct {
-	Issuer     string
-	ClientID   string
-	ListenAddr string
-	Timeout    time.Duration
+	Issuer         string
+	ClientID       string
+	ListenAddr     string
+	RedirectHost   string
+	RedirectPath   string
+	DeviceCodePath string
+	TokenPath      string
 }
 
-func wireApp() (*app, error) {
-	repo, err := tomlrepo.NewRepository(viper.New())
+func wireApp(opts wireOptions) (*app, error) {
+	repoConfig := func() *viper.Viper {
+		cfg := viper.New()
+		if opts.configDir != "" {
+			cfg.Set(tomlrepo.ConfigDirKey, opts.configDir)
+		}
+		return cfg
+	}
+
+	repo, err := tomlrepo.NewRepository(repoConfig())
 	if err != nil {
 		return nil, fmt.Errorf("wire account repository: %w", err)
 	}
 
-	poolRepo, err := tomlrepo.NewPoolRepository(viper.New())
+	poolRepo, err := tomlrepo.NewPoolRepository(repoConfig())
 	if err != nil {
 		return nil, fmt.Errorf("wire pool repository: %w", err)
 	}
 
-	poolRuntimeRepo, err := tomlrepo.NewPoolRuntimeRepository(viper.New())
+	poolRuntimeRepo, err := tomlrepo.NewPoolRuntimeRepository(repoConfig())
 	if err != nil {
 		return nil, fmt.Errorf("wire pool runtime repository: %w", err)
 	}
 
-	homeDir, err := os.UserHomeDir()
+	configDir, err := tomlrepo.ResolveConfigDir(repoConfig())
 	if err != nil {
-		return nil, fmt.Errorf("resolve home directory: %w", err)
+		return nil, err
 	}
 
-	secretStore, err := chainstore.NewPassFirstWithFileFallback(filepath.Join(homeDir, ".codex", "secrets"))
+	fileSecrets := filestore.NewStore(filepath.Join(configDir, secretsDirName))
+	secretBackends := []secretBackend{
+		{name: "pass", store: passstore.NewStore().WithDir(passStoreDir())},
+		{name: "file", store: fileSecrets},
+	}
+
+	var accounts ports.AccountRepository = repo
+	var pools ports.PoolRepository = poolRepo
+	var runtimes ports.PoolRuntimeRepository = poolRuntimeRepo
+	clock := opts.clock
+	if clock == nil {
+		clock = ports.SystemClock{}
+	}
+	stderr := opts.stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	dryLog := dryRunLog{out: stderr}
+	if opts.dryRun {
+		accounts = dryRunAccountRepository{AccountRepository: repo, path: repo.Path(), log: dryLog}
+		pools = dryRunPoolRepository{PoolRepository: poolRepo, path: poolRepo.Path(), log: dryLog}
+		runtimes = dryRunPoolRuntimeRepository{PoolRuntimeRepository: poolRuntimeRepo, path: poolRuntimeRepo.Path(), log: dryLog}
+		for i, backend := range secretBackends {
+			secretBackends[i].store = dryRunSecretStore{SecretStore: backend.store, backend: backend.name, log: dryLog}
+		}
+	}
+	chained := make([]ports.SecretStore, 0, len(secretBackends))
+	for _, backend := range secretBackends {
+		chained = append(chained, backend.store)
+	}
+	newChain := chainstore.NewStoreChecked
+	if envEnabled("OA_SECRET_WRITE_THROUGH") {
+		newChain = chainstore.NewWriteThroughStore
+	}
+	secretStore, err := newChain(chained...)
 	if err != nil {
 		return nil, fmt.Errorf("wire secret store chain: %w", err)
 	}
+	secretStore.WithWarnings(func(message string) {
+		_, _ = fmt.Fprintln(stderr, message)
+	}).WithRefStore(cmdstore.RefPrefix, cmdstore.NewStore())
+
+	secretKeys, err := parseSecretKeyTemplate(os.Getenv("OA_SECRET_KEY_TEMPLATE"))
+	if err != nil {
+		return nil, err
+	}
 
 	return &app{
-		service:           application.NewService(repo, secretStore, ports.SystemClock{}),
-		poolService:       application.NewPoolService(repo, poolRepo, ports.SystemClock{}),
-		continuityService: application.NewSessionContinuityService(poolRuntimeRepo, ports.SystemClock{}),
+		service:           application.NewService(accounts, secretStore, clock),
+		poolService:       application.NewPoolService(accounts, pools, clock),
+		continuityService: application.NewSessionContinuityService(runtimes, clock),
+		accountsFile:      repo,
+		configStores:      []configStore{repo, poolRepo, poolRuntimeRepo, fileSecrets},
+		configDir:         configDir,
 		secretStore:       secretStore,
+		secretBackends:    secretBackends,
 		statusRenderer:    statusadapter.Render,
 		browserLogin: browserLoginConfig{
-			Issuer:     envOrDefault("OA_AUTH_ISSUER", "https://auth.openai.com"),
-			ClientID:   envOrDefault("OA_AUTH_CLIENT_ID", "app_EMoamEEZ73f0CkXaXp7hrann"),
-			ListenAddr: envOrDefault("OA_AUTH_LISTEN", "127.0.0.1:1455"),
-			Timeout:    5 * time.Minute,
+			Issuer:         envOrDefault("OA_AUTH_ISSUER", "https://auth.openai.com"),
+			ClientID:       envOrDefault("OA_AUTH_CLIENT_ID", "app_EMoamEEZ73f0CkXaXp7hrann"),
+			ListenAddr:     envOrDefault("OA_AUTH_LISTEN", "127.0.0.1:1455"),
+			RedirectHost:   envOrDefault("OA_AUTH_REDIRECT_HOST", authadapter.DefaultRedirectHost),
+			RedirectPath:   envOrDefault("OA_AUTH_REDIRECT_PATH", authadapter.DefaultRedirectPath),
+			DeviceCodePath: envOrDefault("OA_AUTH_DEVICE_CODE_PATH", "/oauth/device/code"),
+			TokenPath:      envOrDefault("OA_AUTH_TOKEN_PATH", "/oauth/token"),
 		},
-		usageBaseURL: envOrDefault("OA_USAGE_BASE_URL", "https://chatgpt.com/backend-api"),
-		httpClient:   http.DefaultClient,
-		now:          time.Now,
+		usageBaseURL:     envOrDefault("OA_USAGE_BASE_URL", "https://chatgpt.com/backend-api"),
+		httpClient:       newHTTPClient(),
+		maxResponseBytes: maxResponseBytes(os.Getenv("OA_MAX_RESPONSE_BYTES")),
+		retry:            retryPolicy{Retries: defaultRetries, Backoff: defaultRetryBackoff},
+		secretKeys:       secretKeys,
+		clock:            clock,
+		dryRun:           opts.dryRun,
+		dryRunLog:        dryLog,
+		stderr:           stderr,
 	}, nil
 }
+
+// passStoreDir is where pass keeps its .gpg entries, honouring
+// PASSWORD_STORE_DIR like pass itself. It is empty when no home is known.
+func passStoreDir() string {
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".password-store")
+}
+
+// maxResponseBytes parses OA_MAX_RESPONSE_BYTES, falling back to
+// httpbody.DefaultMaxBytes unless it holds a positive integer.
+func maxResponseBytes(raw string) int64 {
+	limit, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || limit <= 0 {
+		return httpbody.DefaultMaxBytes
+	}
+	return limit
+}
+
 func envOrDefault(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
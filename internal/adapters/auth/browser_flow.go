@@ -1,21 +1,22 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
-)
 
-const maxTokenResponseBytes = 1 << 20
+	"github.com/bnema/openai-accounts-cli/internal/adapters/httpbody"
+)
 
 var (
 	ErrStateMismatch       = errors.New("oauth callback state mismatch")
@@ -24,6 +25,20 @@ var (
 	ErrRefreshTokenInvalid = errors.New("oauth refresh token invalid")
 )
 
+// RefreshStatusError is a non-2xx answer from the token endpoint other than
+// invalid_grant.
+type RefreshStatusError struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *RefreshStatusError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("refresh token endpoint returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("refresh token endpoint returned status %d: %s", e.StatusCode, e.Detail)
+}
+
 type AuthorizationRequest struct {
 	AuthURL       string
 	ClientID      string
@@ -40,6 +55,9 @@ type TokenExchangeRequest struct {
 	RedirectURI  string
 	Code         string
 	CodeVerifier string
+	// MaxResponseBytes caps the token response; zero uses
+	// httpbody.DefaultMaxBytes.
+	MaxResponseBytes int64
 }
 
 type ExchangedTokens struct {
@@ -54,6 +72,9 @@ type RefreshTokenRequest struct {
 	Issuer       string
 	ClientID     string
 	RefreshToken string
+	// MaxResponseBytes caps the token response; zero uses
+	// httpbody.DefaultMaxBytes.
+	MaxResponseBytes int64
 }
 
 func NewState() (string, error) {
@@ -115,8 +136,44 @@ func BuildAuthorizationURL(req AuthorizationRequest) (string, error) {
 	return parsed.String(), nil
 }
 
+const (
+	DefaultRedirectHost = "localhost"
+	DefaultRedirectPath = "/auth/callback"
+)
+
+// CallbackRedirect controls the redirect URI advertised to the authorization
+// server. Empty fields fall back to DefaultRedirectHost and DefaultRedirectPath.
+type CallbackRedirect struct {
+	Host string
+	Path string
+}
+
+func (r CallbackRedirect) normalized() (CallbackRedirect, error) {
+	host := strings.TrimSpace(r.Host)
+	if host == "" {
+		host = DefaultRedirectHost
+	}
+	if strings.ContainsAny(host, "/?#") || strings.Contains(host, "://") {
+		return CallbackRedirect{}, fmt.Errorf("invalid redirect host %q", r.Host)
+	}
+
+	path := strings.TrimSpace(r.Path)
+	if path == "" {
+		path = DefaultRedirectPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if strings.ContainsAny(path, "?#") {
+		return CallbackRedirect{}, fmt.Errorf("invalid redirect path %q", r.Path)
+	}
+
+	return CallbackRedirect{Host: host, Path: path}, nil
+}
+
 type CallbackServer struct {
 	expectedState string
+	redirect      CallbackRedirect
 	listener      net.Listener
 	server        *http.Server
 	resultCh      chan callbackResult
@@ -130,9 +187,17 @@ type callbackResult struct {
 }
 
 func StartCallbackServer(listenAddr string, expectedState string) (*CallbackServer, error) {
+	return StartCallbackServerWithRedirect(listenAddr, expectedState, CallbackRedirect{})
+}
+
+func StartCallbackServerWithRedirect(listenAddr string, expectedState string, redirect CallbackRedirect) (*CallbackServer, error) {
 	if expectedState == "" {
 		return nil, ErrMissingState
 	}
+	redirect, err := redirect.normalized()
+	if err != nil {
+		return nil, err
+	}
 	if listenAddr == "" {
 		listenAddr = "127.0.0.1:0"
 	}
@@ -144,12 +209,13 @@ func StartCallbackServer(listenAddr string, expectedState string) (*CallbackServ
 
 	cb := &CallbackServer{
 		expectedState: expectedState,
+		redirect:      redirect,
 		listener:      listener,
 		resultCh:      make(chan callbackResult, 1),
 	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/auth/callback", cb.handleCallback)
+	mux.HandleFunc(redirect.Path, cb.handleCallback)
 
 	cb.server = &http.Server{Handler: mux}
 
@@ -163,20 +229,27 @@ func StartCallbackServer(listenAddr string, expectedState string) (*CallbackServ
 }
 
 func (c *CallbackServer) RedirectURI() string {
+	host := c.redirect.Host
 	if tcpAddr, ok := c.listener.Addr().(*net.TCPAddr); ok {
-		return fmt.Sprintf("http://localhost:%d/auth/callback", tcpAddr.Port)
+		host = net.JoinHostPort(host, strconv.Itoa(tcpAddr.Port))
 	}
-	return fmt.Sprintf("http://localhost/auth/callback")
+	return (&url.URL{Scheme: "http", Host: host, Path: c.redirect.Path}).String()
 }
 
-func (c *CallbackServer) WaitForCode(timeout time.Duration) (string, error) {
+// WaitForCode waits for the callback until timeout passes or ctx is done.
+func (c *CallbackServer) WaitForCode(ctx context.Context, timeout time.Duration) (string, error) {
 	defer c.Close()
 
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
 	select {
 	case result := <-c.resultCh:
 		return result.code, result.err
-	case <-time.After(timeout):
+	case <-timer.C:
 		return "", ErrCallbackTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
 }
 
@@ -270,8 +343,13 @@ func ExchangeCodeForTokens(client *http.Client, req TokenExchangeRequest) (Excha
 		return ExchangedTokens{}, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
 	}
 
+	body, err := httpbody.ReadAll(resp.Body, req.MaxResponseBytes)
+	if err != nil {
+		return ExchangedTokens{}, fmt.Errorf("read token response: %w", err)
+	}
+
 	var tokens ExchangedTokens
-	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenResponseBytes)).Decode(&tokens); err != nil {
+	if err := json.Unmarshal(body, &tokens); err != nil {
 		return ExchangedTokens{}, fmt.Errorf("decode token response: %w", err)
 	}
 	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.IDToken == "" {
@@ -316,7 +394,7 @@ func RefreshTokens(client *http.Client, req RefreshTokenRequest) (ExchangedToken
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseBytes))
+	body, err := httpbody.ReadAll(resp.Body, req.MaxResponseBytes)
 	if err != nil {
 		return ExchangedTokens{}, fmt.Errorf("read refresh token response: %w", err)
 	}
@@ -326,6 +404,7 @@ func RefreshTokens(client *http.Client, req RefreshTokenRequest) (ExchangedToken
 			Error            string `json:"error"`
 			ErrorDescription string `json:"error_description"`
 		}
+		statusErr := &RefreshStatusError{StatusCode: resp.StatusCode}
 		if unmarshalErr := json.Unmarshal(body, &oauthErr); unmarshalErr == nil {
 			detail := strings.TrimSpace(oauthErr.Error)
 			if desc := strings.TrimSpace(oauthErr.ErrorDescription); desc != "" {
@@ -334,11 +413,9 @@ func RefreshTokens(client *http.Client, req RefreshTokenRequest) (ExchangedToken
 			if oauthErr.Error == "invalid_grant" {
 				return ExchangedTokens{}, fmt.Errorf("%w: %s", ErrRefreshTokenInvalid, detail)
 			}
-			if detail != "" {
-				return ExchangedTokens{}, fmt.Errorf("refresh token endpoint returned status %d: %s", resp.StatusCode, detail)
-			}
+			statusErr.Detail = detail
 		}
-		return ExchangedTokens{}, fmt.Errorf("refresh token endpoint returned status %d", resp.StatusCode)
+		return ExchangedTokens{}, statusErr
 	}
 
 	var tokens ExchangedTokens
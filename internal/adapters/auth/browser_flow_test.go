@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -74,7 +75,7 @@ func TestCallbackServerReturnsCodeOnSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	assert.Contains(t, string(body), "Authentication complete")
 
-	code, err := server.WaitForCode(2 * time.Second)
+	code, err := server.WaitForCode(context.Background(), 2*time.Second)
 	require.NoError(t, err)
 	assert.Equal(t, "auth-code", code)
 }
@@ -92,7 +93,7 @@ func TestCallbackServerReturnsErrorOnStateMismatch(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	_, err = server.WaitForCode(2 * time.Second)
+	_, err = server.WaitForCode(context.Background(), 2*time.Second)
 	require.Error(t, err)
 	assert.True(t, errors.Is(err, ErrStateMismatch))
 }
@@ -104,11 +105,63 @@ func TestCallbackServerTimesOutWaitingForCallback(t *testing.T) {
 	require.NoError(t, err)
 	defer func() { _ = server.Close() }()
 
-	_, err = server.WaitForCode(50 * time.Millisecond)
+	_, err = server.WaitForCode(context.Background(), 50*time.Millisecond)
 	require.Error(t, err)
 	assert.True(t, errors.Is(err, ErrCallbackTimeout))
 }
 
+func TestCallbackServerStopsWaitingWhenContextIsCanceled(t *testing.T) {
+	t.Parallel()
+
+	server, err := StartCallbackServer("127.0.0.1:0", "expected-state")
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = server.WaitForCode(ctx, time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestCallbackServerUsesConfiguredRedirectHostAndPath(t *testing.T) {
+	t.Parallel()
+
+	server, err := StartCallbackServerWithRedirect("127.0.0.1:0", "expected-state", CallbackRedirect{Host: "127.0.0.1", Path: "oauth/done"})
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+
+	redirectURI, err := url.Parse(server.RedirectURI())
+	require.NoError(t, err)
+	assert.Equal(t, "http", redirectURI.Scheme)
+	assert.Equal(t, "127.0.0.1", redirectURI.Hostname())
+	assert.NotEmpty(t, redirectURI.Port())
+	assert.Equal(t, "/oauth/done", redirectURI.Path)
+
+	defaultRoute, err := http.Get("http://" + redirectURI.Host + DefaultRedirectPath + "?code=auth-code&state=expected-state")
+	require.NoError(t, err)
+	_ = defaultRoute.Body.Close()
+	assert.Equal(t, http.StatusNotFound, defaultRoute.StatusCode)
+
+	resp, err := http.Get(server.RedirectURI() + "?code=auth-code&state=expected-state")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	code, err := server.WaitForCode(context.Background(), 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "auth-code", code)
+}
+
+func TestStartCallbackServerWithRedirectRejectsInvalidHost(t *testing.T) {
+	t.Parallel()
+
+	_, err := StartCallbackServerWithRedirect("127.0.0.1:0", "expected-state", CallbackRedirect{Host: "http://127.0.0.1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid redirect host")
+}
+
 func TestStartCallbackServerRequiresExpectedState(t *testing.T) {
 	t.Parallel()
 
@@ -191,6 +244,26 @@ func TestRefreshTokensSuccess(t *testing.T) {
 	assert.Equal(t, "it2", tokens.IDToken)
 }
 
+func TestRefreshTokensReportsStatusCode(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"temporarily_unavailable"}`))
+	}))
+	defer server.Close()
+
+	_, err := RefreshTokens(http.DefaultClient, RefreshTokenRequest{
+		Issuer:       server.URL,
+		ClientID:     "client-123",
+		RefreshToken: "refresh-abc",
+	})
+	var statusErr *RefreshStatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.EqualError(t, err, "refresh token endpoint returned status 503: temporarily_unavailable")
+}
+
 func TestRefreshTokensReturnsInvalidGrantSentinel(t *testing.T) {
 	t.Parallel()
 
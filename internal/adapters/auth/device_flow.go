@@ -5,15 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/bnema/openai-accounts-cli/internal/adapters/httpbody"
 )
 
 const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
-const maxOAuthResponseBytes = 1 << 20
 
 var ErrDeviceFlowTimeout = errors.New("timed out waiting for device authorization")
 
@@ -27,6 +27,9 @@ type DeviceFlowAdapter struct {
 	API            API
 	HTTPClient     *http.Client
 	RequestTimeout time.Duration
+	// MaxResponseBytes caps each response body; zero uses
+	// httpbody.DefaultMaxBytes.
+	MaxResponseBytes int64
 }
 
 type DeviceCodeResult struct {
@@ -48,6 +51,7 @@ type TokenResult struct {
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int64  `json:"expires_in"`
 	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
 	Scope        string `json:"scope"`
 }
 
@@ -96,12 +100,17 @@ func (a DeviceFlowAdapter) RequestDeviceCode(ctx context.Context, clientID strin
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		oauthErr := decodeOAuthError(resp)
+		oauthErr := decodeOAuthError(resp, a.MaxResponseBytes)
 		return DeviceCodeResult{}, fmt.Errorf("request device code: %s", oauthErr)
 	}
 
+	body, err := httpbody.ReadAll(resp.Body, a.MaxResponseBytes)
+	if err != nil {
+		return DeviceCodeResult{}, fmt.Errorf("read device code response: %w", err)
+	}
+
 	var payload deviceCodeResponse
-	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOAuthResponseBytes)).Decode(&payload); err != nil {
+	if err := json.Unmarshal(body, &payload); err != nil {
 		return DeviceCodeResult{}, fmt.Errorf("decode device code response: %w", err)
 	}
 
@@ -207,9 +216,14 @@ func (a DeviceFlowAdapter) pollTokenOnce(ctx context.Context, clientID string, d
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	body, err := httpbody.ReadAll(resp.Body, a.MaxResponseBytes)
+	if err != nil {
+		return TokenResult{}, 0, false, fmt.Errorf("read token response: %w", err)
+	}
+
 	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
 		var token TokenResult
-		if err := json.NewDecoder(io.LimitReader(resp.Body, maxOAuthResponseBytes)).Decode(&token); err != nil {
+		if err := json.Unmarshal(body, &token); err != nil {
 			return TokenResult{}, 0, false, fmt.Errorf("decode token response: %w", err)
 		}
 		if token.AccessToken == "" {
@@ -219,7 +233,7 @@ func (a DeviceFlowAdapter) pollTokenOnce(ctx context.Context, clientID string, d
 	}
 
 	var oauthErr oauthErrorResponse
-	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOAuthResponseBytes)).Decode(&oauthErr); err != nil {
+	if err := json.Unmarshal(body, &oauthErr); err != nil {
 		return TokenResult{}, 0, false, fmt.Errorf("request token: status %d", resp.StatusCode)
 	}
 
@@ -258,9 +272,14 @@ func (a DeviceFlowAdapter) requestContext(ctx context.Context) (context.Context,
 	return context.WithTimeout(ctx, requestTimeout)
 }
 
-func decodeOAuthError(resp *http.Response) string {
+func decodeOAuthError(resp *http.Response, maxBytes int64) string {
+	body, err := httpbody.ReadAll(resp.Body, maxBytes)
+	if err != nil {
+		return fmt.Sprintf("status %d: %v", resp.StatusCode, err)
+	}
+
 	var oauthErr oauthErrorResponse
-	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOAuthResponseBytes)).Decode(&oauthErr); err != nil {
+	if err := json.Unmarshal(body, &oauthErr); err != nil {
 		return fmt.Sprintf("status %d", resp.StatusCode)
 	}
 	return formatOAuthError(resp.StatusCode, oauthErr)
@@ -85,7 +85,7 @@ func TestPollTokenReturnsSuccessAfterPending(t *testing.T) {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"access_token":"token-abc","token_type":"Bearer","expires_in":3600}`))
+		_, _ = w.Write([]byte(`{"access_token":"token-abc","id_token":"id-abc","token_type":"Bearer","expires_in":3600}`))
 	}))
 	t.Cleanup(server.Close)
 
@@ -106,6 +106,7 @@ func TestPollTokenReturnsSuccessAfterPending(t *testing.T) {
 	})
 	require.NoError(t, err)
 	assert.Equal(t, "token-abc", token.AccessToken)
+	assert.Equal(t, "id-abc", token.IDToken)
 	assert.Equal(t, "Bearer", token.TokenType)
 	assert.Equal(t, int64(3600), token.ExpiresIn)
 	assert.Equal(t, int32(2), attempts.Load())
@@ -0,0 +1,123 @@
+package filelock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	pollInterval = 10 * time.Millisecond
+	// StaleAfter is how old a lock file may get before Acquire assumes it was
+	// left behind by a crashed process and removes it.
+	StaleAfter = 30 * time.Second
+)
+
+// ErrLocked is returned by TryAcquire when another holder owns the lock.
+var ErrLocked = errors.New("lock is held by another process")
+
+// Acquire takes an exclusive lock for path by creating path+".lock" with
+// O_EXCL, waiting until the lock is free or ctx is done. The returned
+// function releases the lock.
+func Acquire(ctx context.Context, path string) (func() error, error) {
+	for {
+		release, err := TryAcquire(path, StaleAfter)
+		if !errors.Is(err, ErrLocked) {
+			return release, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for lock %s.lock: %w", path, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// TryAcquire takes the lock for path without waiting. Lock files older than
+// staleAfter are broken first; a non-positive staleAfter never expires them.
+// The lock file records an owner token, and release only removes a lock file
+// that still carries it.
+func TryAcquire(path string, staleAfter time.Duration) (func() error, error) {
+	lockPath := path + ".lock"
+
+	token, err := newOwnerToken()
+	if err != nil {
+		return nil, err
+	}
+	if staleAfter > 0 {
+		breakStaleLock(lockPath, token, staleAfter)
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+
+	owner := fmt.Sprintf("%d %s\n", os.Getpid(), token)
+	_, writeErr := file.WriteString(owner)
+	if err := errors.Join(writeErr, file.Close()); err != nil {
+		_ = os.Remove(lockPath)
+		return nil, fmt.Errorf("write lock file: %w", err)
+	}
+
+	return func() error {
+		return release(lockPath, owner)
+	}, nil
+}
+
+// breakStaleLock moves a lock file older than staleAfter aside instead of
+// removing it by path: when two processes judge the same lock stale, the
+// slower one must not delete the fresh lock the faster one just took. If the
+// file it moved turns out to be fresh, it is linked back into place.
+func breakStaleLock(lockPath, token string, staleAfter time.Duration) {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= staleAfter {
+		return
+	}
+
+	moved := lockPath + "." + token + ".stale"
+	if err := os.Rename(lockPath, moved); err != nil {
+		return
+	}
+	defer func() { _ = os.Remove(moved) }()
+
+	if info, err := os.Stat(moved); err == nil && time.Since(info.ModTime()) <= staleAfter {
+		_ = os.Link(moved, lockPath)
+	}
+}
+
+// release removes the lock file only while it still holds owner, so a holder
+// whose lock was broken as stale cannot remove the next holder's lock.
+func release(lockPath, owner string) error {
+	data, err := os.ReadFile(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("release lock file: %w", err)
+	}
+	if string(data) != owner {
+		return nil
+	}
+
+	if err := os.Remove(lockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release lock file: %w", err)
+	}
+	return nil
+}
+
+func newOwnerToken() (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate lock owner token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,140 @@
+package filelock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireSerializesHolders(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "auth.json")
+	var mu sync.Mutex
+	inside := 0
+	maxInside := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := Acquire(context.Background(), path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			inside++
+			if inside > maxInside {
+				maxInside = inside
+			}
+			mu.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			mu.Lock()
+			inside--
+			mu.Unlock()
+			assert.NoError(t, release())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxInside)
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestAcquireHonorsContextWhileLockIsHeld(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "auth.json")
+	release, err := Acquire(context.Background(), path)
+	require.NoError(t, err)
+	defer func() { _ = release() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err = Acquire(ctx, path)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAcquireRemovesStaleLock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(path+".lock", []byte("12345\n"), 0o600))
+	old := time.Now().Add(-2 * StaleAfter)
+	require.NoError(t, os.Chtimes(path+".lock", old, old))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	release, err := Acquire(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestTryAcquireFailsFastWhileLockIsHeld(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "login-acc-1")
+	release, err := TryAcquire(path, 0)
+	require.NoError(t, err)
+
+	_, err = TryAcquire(path, 0)
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, release())
+	release, err = TryAcquire(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestTryAcquireKeepsOldLockWithoutStaleAfter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "login-acc-1")
+	require.NoError(t, os.WriteFile(path+".lock", []byte("12345\n"), 0o600))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path+".lock", old, old))
+
+	_, err := TryAcquire(path, 0)
+	require.ErrorIs(t, err, ErrLocked)
+
+	release, err := TryAcquire(path, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestReleaseKeepsLockTakenOverByAnotherHolder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "login-acc-1")
+	release, err := TryAcquire(path, time.Minute)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path+".lock", old, old))
+	releaseNext, err := TryAcquire(path, time.Minute)
+	require.NoError(t, err)
+	leftovers, err := filepath.Glob(path + ".lock.*")
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+
+	require.NoError(t, release())
+	assert.FileExists(t, path+".lock")
+
+	_, err = TryAcquire(path, time.Minute)
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, releaseNext())
+	assert.NoFileExists(t, path+".lock")
+}
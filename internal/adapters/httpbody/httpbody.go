@@ -0,0 +1,30 @@
+package httpbody
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const DefaultMaxBytes int64 = 1 << 20
+
+var ErrTooLarge = errors.New("response body too large")
+
+// ReadAll reads at most limit bytes and fails with ErrTooLarge instead of
+// returning a silently truncated body. A limit of zero or less means
+// DefaultMaxBytes.
+func ReadAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = DefaultMaxBytes
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: response exceeded %d bytes", ErrTooLarge, limit)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,32 @@
+package httpbody
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAllReturnsBodyWithinLimit(t *testing.T) {
+	t.Parallel()
+
+	body, err := ReadAll(strings.NewReader("12345678"), 8)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", string(body))
+}
+
+func TestReadAllFailsWhenBodyExceedsLimit(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadAll(strings.NewReader(`{"plan_type":"pro"}`), 8)
+	require.ErrorIs(t, err, ErrTooLarge)
+	assert.ErrorContains(t, err, "response exceeded 8 bytes")
+}
+
+func TestReadAllDefaultsLimitWhenUnset(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadAll(strings.NewReader(strings.Repeat("x", int(DefaultMaxBytes)+1)), 0)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
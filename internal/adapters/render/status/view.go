@@ -12,14 +12,118 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// ResetFormat controls how reset and renewal times are rendered.
+type ResetFormat string
+
+const (
+	ResetFormatRelative ResetFormat = "relative"
+	ResetFormatAbsolute ResetFormat = "absolute"
+	ResetFormatBoth     ResetFormat = "both"
+)
+
+// ParseResetFormat validates a reset format name; empty selects both.
+func ParseResetFormat(raw string) (ResetFormat, error) {
+	switch format := ResetFormat(strings.ToLower(strings.TrimSpace(raw))); format {
+	case "":
+		return ResetFormatBoth, nil
+	case ResetFormatRelative, ResetFormatAbsolute, ResetFormatBoth:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported reset format %q (valid formats: relative, absolute, both)", raw)
+	}
+}
+
+// RecommendationMode controls the recommendation block above the accounts.
+type RecommendationMode string
+
+const (
+	RecommendationFull    RecommendationMode = "full"
+	RecommendationCompact RecommendationMode = "compact"
+	RecommendationOff     RecommendationMode = "off"
+)
+
+// ParseRecommendationMode validates a recommendation mode name; empty selects
+// full.
+func ParseRecommendationMode(raw string) (RecommendationMode, error) {
+	switch mode := RecommendationMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case "":
+		return RecommendationFull, nil
+	case RecommendationFull, RecommendationCompact, RecommendationOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported recommendation mode %q (valid modes: off, compact, full)", raw)
+	}
+}
+
+// PercentMode selects whether limit lines show the percent left or used.
+type PercentMode string
+
+const (
+	PercentLeft PercentMode = "left"
+	PercentUsed PercentMode = "used"
+)
+
+// ParsePercentMode validates a percent mode name; empty selects left.
+func ParsePercentMode(raw string) (PercentMode, error) {
+	switch mode := PercentMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case "":
+		return PercentLeft, nil
+	case PercentLeft, PercentUsed:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported percent mode %q (valid modes: used, left)", raw)
+	}
+}
+
 type RenderOptions struct {
 	Now             time.Time
 	StaleAfter      time.Duration
 	ActiveAccountID domain.AccountID
+	ResetFormat     ResetFormat
+	// MinWeeklyLeft and MaxWeeklyLeft, when set, keep only accounts whose
+	// weekly percent left falls within the bounds.
+	MinWeeklyLeft *float64
+	MaxWeeklyLeft *float64
+	// Precision is the number of decimals shown for percent left.
+	Precision int
+	// MinWeeklyPressure, when positive, adds a fleet-wide warning if even the
+	// recommended account has less weekly percent left per hour until reset.
+	MinWeeklyPressure float64
+	// RecommendationMode selects the full recommendation block (the default),
+	// a single compact line, or none at all. The weekly pressure warning
+	// follows the recommendation unless it is off.
+	RecommendationMode RecommendationMode
+	// PercentMode picks the percent shown on limit lines; the bar and its
+	// color always follow the percent left.
+	PercentMode PercentMode
+}
+
+// FilterByWeeklyLeft applies the weekly-left bounds from opts. Accounts
+// without a weekly snapshot are dropped once any bound is set.
+func FilterByWeeklyLeft(statuses []application.Status, opts RenderOptions) []application.Status {
+	if opts.MinWeeklyLeft == nil && opts.MaxWeeklyLeft == nil {
+		return statuses
+	}
+
+	filtered := make([]application.Status, 0, len(statuses))
+	for _, status := range statuses {
+		if status.WeeklyLimit == nil {
+			continue
+		}
+		left := limitLeftPercent(status.WeeklyLimit)
+		if opts.MinWeeklyLeft != nil && left < *opts.MinWeeklyLeft {
+			continue
+		}
+		if opts.MaxWeeklyLeft != nil && left > *opts.MaxWeeklyLeft {
+			continue
+		}
+		filtered = append(filtered, status)
+	}
+	return filtered
 }
 
 func renderView(statuses []application.Status, opts RenderOptions, s styles) string {
-	ordered := prioritizeStatuses(statuses, opts.Now)
+	ordered := prioritizeStatuses(FilterByWeeklyLeft(statuses, opts), opts.Now)
 
 	lines := []string{
 		s.title.Render("OpenAI Account Usage"),
@@ -31,30 +135,49 @@ func renderView(statuses []application.Status, opts RenderOptions, s styles) str
 		return lipgloss.JoinVertical(lipgloss.Left, lines...)
 	}
 
-	for _, recommendation := range recommendationLines(ordered, opts.Now, s) {
+	for _, recommendation := range recommendationLines(ordered, opts, s) {
 		lines = append(lines, recommendation)
 	}
+	if reset, ok := nextResetLine(ordered, opts); ok {
+		lines = append(lines, s.detail.Render(reset))
+	}
 
 	for _, status := range ordered {
 		lines = append(lines, s.section.Render(renderAccount(status, opts, s)))
 	}
 
+	if total, ok := totalUsageLine(ordered); ok {
+		lines = append(lines, s.header.Render(total))
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
-func recommendationLines(statuses []application.Status, now time.Time, s styles) []string {
+func recommendationLines(statuses []application.Status, opts RenderOptions, s styles) []string {
+	if opts.RecommendationMode == RecommendationOff {
+		return nil
+	}
+
+	now := opts.Now
 	for i, status := range statuses {
 		if !canUseNow(status, now) {
 			continue
 		}
 
-		lines := []string{
-			s.detail.Render(fmt.Sprintf("recommendation: use %s first", recommendationAccountLabel(status))),
-			s.detail.Render(fmt.Sprintf("details: %s", recommendationDetails(status, now))),
+		var lines []string
+		if opts.RecommendationMode == RecommendationCompact {
+			lines = append(lines, s.detail.Render(fmt.Sprintf("recommendation: use %s, %s", recommendationAccountLabel(status), recommendationCompactLeft(status, opts))))
+		} else {
+			lines = append(lines,
+				s.detail.Render(fmt.Sprintf("recommendation: use %s first", recommendationAccountLabel(status))),
+				s.detail.Render(fmt.Sprintf("details: %s", recommendationDetails(status, opts))),
+			)
+			if next, ok := nextAvailableStatus(statuses, i+1, now); ok {
+				lines = append(lines, s.detail.Render(fmt.Sprintf("next: %s (%s)", recommendationAccountLabel(next), recommendationPrioritySnapshot(next, opts))))
+			}
 		}
-
-		if next, ok := nextAvailableStatus(statuses, i+1, now); ok {
-			lines = append(lines, s.detail.Render(fmt.Sprintf("next: %s (%s)", recommendationAccountLabel(next), recommendationPrioritySnapshot(next, now))))
+		if warning, ok := weeklyPressureWarning(status, opts); ok {
+			lines = append(lines, s.warning.Render(warning))
 		}
 
 		return lines
@@ -63,6 +186,62 @@ func recommendationLines(statuses []application.Status, now time.Time, s styles)
 	return []string{s.warning.Render("recommendation: no account available now (waiting for reset)")}
 }
 
+// weeklyPressureWarning flags a fleet running low: statuses are ordered by
+// weekly pressure, so when the recommended account is under the floor, every
+// usable account is.
+func weeklyPressureWarning(status application.Status, opts RenderOptions) (string, bool) {
+	if opts.MinWeeklyPressure <= 0 || status.WeeklyLimit == nil {
+		return "", false
+	}
+
+	pressure := buildAccountPriority(status, opts.Now).weeklyPressure
+	if pressure >= opts.MinWeeklyPressure {
+		return "", false
+	}
+	return fmt.Sprintf("warning: weekly pressure %.2f%%/h is below %.2f%%/h on every account (slow down or add accounts)", pressure, opts.MinWeeklyPressure), true
+}
+
+// nextResetLine names the limit, across every account and feature, that
+// resets soonest. Snapshots without a reset time or already reset are skipped.
+func nextResetLine(statuses []application.Status, opts RenderOptions) (string, bool) {
+	now := opts.Now
+	if now.IsZero() {
+		return "", false
+	}
+
+	var (
+		soonest *application.StatusLimit
+		owner   application.Status
+		feature string
+	)
+	consider := func(status application.Status, limit *application.StatusLimit, featureName string) {
+		if limit == nil || limit.ResetsAt.IsZero() || !limit.ResetsAt.After(now) {
+			return
+		}
+		if soonest == nil || limit.ResetsAt.Before(soonest.ResetsAt) {
+			soonest, owner, feature = limit, status, featureName
+		}
+	}
+	for _, status := range statuses {
+		consider(status, status.DailyLimit, "")
+		consider(status, status.WeeklyLimit, "")
+		for _, featureLimit := range status.FeatureLimits {
+			consider(status, featureLimit.DailyLimit, featureLimit.Feature)
+			consider(status, featureLimit.WeeklyLimit, featureLimit.Feature)
+		}
+	}
+	if soonest == nil {
+		return "", false
+	}
+
+	window := windowLabel(soonest.Window)
+	if feature = strings.TrimSpace(feature); feature != "" {
+		window = feature + " " + window
+	}
+	when := strings.TrimPrefix(formatReset(soonest.ResetsAt, opts), "resets ")
+	return fmt.Sprintf("next reset: %s %s %s", recommendationAccountLabel(owner), window, when), true
+}
+
 func recommendationAccountLabel(status application.Status) string {
 	name := strings.TrimSpace(status.Account.Name)
 	id := strings.TrimSpace(string(status.Account.ID))
@@ -85,15 +264,15 @@ func recommendationAccountLabel(status application.Status) string {
 	return name
 }
 
-func recommendationDetails(status application.Status, now time.Time) string {
+func recommendationDetails(status application.Status, opts RenderOptions) string {
 	parts := make([]string, 0, 2)
 
 	if status.WeeklyLimit != nil {
-		parts = append(parts, fmt.Sprintf("weekly %s", recommendationLimitSnapshot(status.WeeklyLimit, now)))
+		parts = append(parts, fmt.Sprintf("weekly %s", recommendationLimitSnapshot(status.WeeklyLimit, opts)))
 	}
 
 	if status.DailyLimit != nil {
-		parts = append(parts, fmt.Sprintf("5hours %s", recommendationLimitSnapshot(status.DailyLimit, now)))
+		parts = append(parts, fmt.Sprintf("5hours %s", recommendationLimitSnapshot(status.DailyLimit, opts)))
 	}
 
 	if len(parts) == 0 {
@@ -103,23 +282,37 @@ func recommendationDetails(status application.Status, now time.Time) string {
 	return strings.Join(parts, "; ")
 }
 
-func recommendationPrioritySnapshot(status application.Status, now time.Time) string {
+func recommendationPrioritySnapshot(status application.Status, opts RenderOptions) string {
+	if status.WeeklyLimit != nil {
+		return fmt.Sprintf("weekly %s", recommendationLimitSnapshot(status.WeeklyLimit, opts))
+	}
+
+	if status.DailyLimit != nil {
+		return fmt.Sprintf("5hours %s", recommendationLimitSnapshot(status.DailyLimit, opts))
+	}
+
+	return "no limit snapshot"
+}
+
+// recommendationCompactLeft is the percent left of the limit that orders the
+// accounts: weekly when known, otherwise the 5-hour window.
+func recommendationCompactLeft(status application.Status, opts RenderOptions) string {
 	if status.WeeklyLimit != nil {
-		return fmt.Sprintf("weekly %s", recommendationLimitSnapshot(status.WeeklyLimit, now))
+		return fmt.Sprintf("%.*f%% weekly left", opts.Precision, limitLeftPercent(status.WeeklyLimit))
 	}
 
 	if status.DailyLimit != nil {
-		return fmt.Sprintf("5hours %s", recommendationLimitSnapshot(status.DailyLimit, now))
+		return fmt.Sprintf("%.*f%% 5hours left", opts.Precision, limitLeftPercent(status.DailyLimit))
 	}
 
 	return "no limit snapshot"
 }
 
-func recommendationLimitSnapshot(limit *application.StatusLimit, now time.Time) string {
+func recommendationLimitSnapshot(limit *application.StatusLimit, opts RenderOptions) string {
 	leftPercent := limitLeftPercent(limit)
-	reset := formatResetRelative(limit.ResetsAt, now)
+	reset := formatReset(limit.ResetsAt, opts)
 
-	return fmt.Sprintf("%.0f%% left (%s)", leftPercent, reset)
+	return fmt.Sprintf("%.*f%% left (%s)", opts.Precision, leftPercent, reset)
 }
 
 func nextAvailableStatus(statuses []application.Status, start int, now time.Time) (application.Status, bool) {
@@ -300,6 +493,10 @@ func renderAccount(status application.Status, opts RenderOptions, s styles) stri
 		parts = append(parts, line)
 	}
 
+	for _, line := range featureLimitLines(status, opts, s) {
+		parts = append(parts, line)
+	}
+
 	if status.Subscription != nil {
 		parts = append(parts, subscriptionLine(status.Subscription, opts, s))
 	}
@@ -315,34 +512,69 @@ func authLabel(method domain.AuthMethod) string {
 	return string(method)
 }
 
+// limitLines renders the 5-hour and weekly limits. Once any limit has been
+// fetched, a missing window means the API did not report one (e.g. the plan
+// has no 5-hour window) and says so instead of silently dropping the line.
 func limitLines(status application.Status, opts RenderOptions, s styles) []string {
+	fetched := status.DailyLimit != nil || status.WeeklyLimit != nil || len(status.FeatureLimits) > 0
+	if !fetched {
+		if status.Account.Auth.Method == domain.AuthMethodChatGPT {
+			return []string{s.detail.Render("limits: not fetched yet (run oa usage)")}
+		}
+		return []string{s.detail.Render("limit: n/a")}
+	}
+
 	lines := make([]string, 0, 2)
-	for _, limit := range []*application.StatusLimit{status.DailyLimit, status.WeeklyLimit} {
-		if limit == nil {
+	for _, window := range []struct {
+		kind  application.LimitWindowKind
+		limit *application.StatusLimit
+	}{
+		{kind: application.LimitWindowDaily, limit: status.DailyLimit},
+		{kind: application.LimitWindowWeekly, limit: status.WeeklyLimit},
+	} {
+		if window.limit == nil {
+			lines = append(lines, s.detail.Render(fmt.Sprintf("%s limit: not reported", windowLabel(window.kind))))
 			continue
 		}
 
-		lines = append(lines, limitLine(limit, opts, s))
+		lines = append(lines, limitLine(window.limit, "", opts, s))
 	}
 
-	if len(lines) == 0 {
-		return []string{s.detail.Render("limit: n/a")}
+	return lines
+}
+
+func featureLimitLines(status application.Status, opts RenderOptions, s styles) []string {
+	lines := make([]string, 0, 2*len(status.FeatureLimits))
+	for _, feature := range status.FeatureLimits {
+		for _, limit := range []*application.StatusLimit{feature.DailyLimit, feature.WeeklyLimit} {
+			if limit == nil {
+				continue
+			}
+			lines = append(lines, limitLine(limit, feature.Feature, opts, s))
+		}
 	}
 
 	return lines
 }
 
-func limitLine(limit *application.StatusLimit, opts RenderOptions, s styles) string {
+func limitLine(limit *application.StatusLimit, feature string, opts RenderOptions, s styles) string {
 	leftPercent := clampPercent(100 - limit.Percent)
 	bar := renderProgressBar(limit.Percent, 24, s)
-	label := s.limitKey.Render(fmt.Sprintf("%s limit:", windowLabel(limit.Window)))
+	labelText := fmt.Sprintf("%s limit:", windowLabel(limit.Window))
+	if feature = strings.TrimSpace(feature); feature != "" {
+		labelText = fmt.Sprintf("%s %s", feature, labelText)
+	}
+	label := s.limitKey.Render(labelText)
 	percentColor := interpolateColor(leftPercent, 0, 100)
 	percentStyle := lipgloss.NewStyle().Foreground(percentColor)
-	meta := percentStyle.Render(fmt.Sprintf("%2.0f%% left", leftPercent))
+	meta := percentStyle.Render(fmt.Sprintf("%2.*f%% left", opts.Precision, leftPercent))
+	if opts.PercentMode == PercentUsed {
+		meta = percentStyle.Render(fmt.Sprintf("%2.*f%% used", opts.Precision, clampPercent(limit.Percent)))
+	}
 
 	resetColor := resetTimeColor(limit.ResetsAt, opts.Now, limit.Window)
 	resetStyle := lipgloss.NewStyle().Foreground(resetColor)
-	reset := resetStyle.Render(fmt.Sprintf("(%s)", formatResetRelative(limit.ResetsAt, opts.Now)))
+	reset := resetStyle.Render(fmt.Sprintf("(%s)", formatReset(limit.ResetsAt, opts)))
 
 	line := lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -368,13 +600,38 @@ func limitLine(limit *application.StatusLimit, opts RenderOptions, s styles) str
 }
 
 func usageLine(status application.Status) string {
-	if status.Account.Auth.Method == domain.AuthMethodChatGPT && status.Usage.BlendedTotal() == 0 {
+	if !hasUsageData(status) {
 		return "usage: n/a (live token totals unavailable)"
 	}
 
 	return fmt.Sprintf("usage: %d tokens", status.Usage.BlendedTotal())
 }
 
+// totalUsageLine sums token usage across accounts that report it; chatgpt
+// accounts without live totals are left out.
+func totalUsageLine(statuses []application.Status) (string, bool) {
+	var total domain.Usage
+	counted := 0
+	for _, status := range statuses {
+		if !hasUsageData(status) {
+			continue
+		}
+		total.InputTokens += status.Usage.InputTokens
+		total.OutputTokens += status.Usage.OutputTokens
+		total.CachedInputTokens += status.Usage.CachedInputTokens
+		counted++
+	}
+	if counted == 0 {
+		return "", false
+	}
+
+	return fmt.Sprintf("total: %s tokens", total.BlendedTotalCompact()), true
+}
+
+func hasUsageData(status application.Status) bool {
+	return !(status.Account.Auth.Method == domain.AuthMethodChatGPT && status.Usage.BlendedTotal() == 0)
+}
+
 func renderProgressBar(usedPercent float64, width int, s styles) string {
 	if width <= 0 {
 		return ""
@@ -430,6 +687,27 @@ func formatResetAt(resetsAt, now time.Time) string {
 	return resetsAt.Format("15:04 on 02 Jan")
 }
 
+func formatReset(resetsAt time.Time, opts RenderOptions) string {
+	switch opts.ResetFormat {
+	case ResetFormatAbsolute:
+		if resetsAt.IsZero() {
+			return "resets unknown"
+		}
+		return "resets at " + formatAbsoluteTime(resetsAt)
+	case ResetFormatRelative:
+		if opts.Now.IsZero() || resetsAt.Before(opts.Now) {
+			return formatResetRelative(resetsAt, opts.Now)
+		}
+		return formatResetIn(resetsAt, opts.Now)
+	default:
+		return formatResetRelative(resetsAt, opts.Now)
+	}
+}
+
+func formatAbsoluteTime(at time.Time) string {
+	return at.Format("2006-01-02 15:04 MST")
+}
+
 func formatResetRelative(resetsAt, now time.Time) string {
 	if now.IsZero() {
 		return "resets " + formatResetAt(resetsAt, now)
@@ -439,6 +717,15 @@ func formatResetRelative(resetsAt, now time.Time) string {
 		return "reset now"
 	}
 
+	clock := resetsAt.Format("15:04")
+	if resetsAt.Sub(now) >= 24*time.Hour {
+		clock = resetsAt.Format("15:04 on 02 Jan")
+	}
+
+	return fmt.Sprintf("%s (%s)", formatResetIn(resetsAt, now), clock)
+}
+
+func formatResetIn(resetsAt, now time.Time) string {
 	remaining := resetsAt.Sub(now)
 	if remaining < 24*time.Hour {
 		hours := int(math.Ceil(remaining.Hours()))
@@ -449,7 +736,7 @@ func formatResetRelative(resetsAt, now time.Time) string {
 		if hours == 1 {
 			suffix = "hour"
 		}
-		return fmt.Sprintf("resets in %d %s (%s)", hours, suffix, resetsAt.Format("15:04"))
+		return fmt.Sprintf("resets in %d %s", hours, suffix)
 	}
 
 	days := int(math.Ceil(remaining.Hours() / 24))
@@ -461,7 +748,7 @@ func formatResetRelative(resetsAt, now time.Time) string {
 		suffix = "day"
 	}
 
-	return fmt.Sprintf("resets in %d %s (%s)", days, suffix, resetsAt.Format("15:04 on 02 Jan"))
+	return fmt.Sprintf("resets in %d %s", days, suffix)
 }
 
 func accountTitle(name string, id domain.AccountID, planType string, active bool) string {
@@ -524,7 +811,7 @@ func subscriptionLine(sub *application.StatusSubscription, opts RenderOptions, s
 	}
 
 	label := s.limitKey.Render("renewal:")
-	renewalText := formatRenewalRelative(sub.ActiveUntil, sub.WillRenew, opts.Now)
+	renewalText := formatRenewal(sub.ActiveUntil, sub.WillRenew, opts)
 
 	line := lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -540,6 +827,34 @@ func subscriptionLine(sub *application.StatusSubscription, opts RenderOptions, s
 	return line
 }
 
+func formatRenewal(activeUntil time.Time, willRenew bool, opts RenderOptions) string {
+	switch opts.ResetFormat {
+	case ResetFormatAbsolute:
+		action := "renews"
+		if !willRenew {
+			action = "expires"
+		}
+		if !opts.Now.IsZero() && activeUntil.Before(opts.Now) {
+			action = "renewed"
+			if !willRenew {
+				action = "expired"
+			}
+		}
+		return fmt.Sprintf("%s at %s", action, formatAbsoluteTime(activeUntil))
+	case ResetFormatRelative:
+		renewal := formatRenewalRelative(activeUntil, willRenew, opts.Now)
+		if opts.Now.IsZero() {
+			return renewal
+		}
+		if i := strings.Index(renewal, " ("); i >= 0 {
+			return renewal[:i]
+		}
+		return renewal
+	default:
+		return formatRenewalRelative(activeUntil, willRenew, opts.Now)
+	}
+}
+
 func formatRenewalRelative(activeUntil time.Time, willRenew bool, now time.Time) string {
 	if now.IsZero() {
 		return activeUntil.Format("02 Jan 2006")
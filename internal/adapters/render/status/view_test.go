@@ -182,6 +182,45 @@ func TestRenderShowsUnavailableUsageHintForChatGPTWithoutTokenSnapshot(t *testin
 	assert.Contains(t, output, "5hours limit:")
 }
 
+func TestRenderMarksDailyLimitNotReportedWhenOnlyWeeklyFetched(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+
+	output, err := Render([]application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    30,
+				ResetsAt:   now.Add(72 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}, RenderOptions{Now: now, StaleAfter: 6 * time.Hour})
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "5hours limit: not reported")
+	assert.Contains(t, output, "70% left")
+	assert.NotContains(t, output, "not fetched yet")
+}
+
+func TestRenderMarksLimitsNotFetchedForChatGPTWithoutSnapshots(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+
+	output, err := Render([]application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+		},
+		{
+			Account: domain.Account{ID: "acc-2", Name: "Keyed", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+		},
+	}, RenderOptions{Now: now, StaleAfter: 6 * time.Hour})
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "limits: not fetched yet (run oa usage)")
+	assert.Contains(t, output, "limit: n/a")
+	assert.NotContains(t, output, "not reported")
+}
+
 func TestRenderPrioritizesAccountsForWeeklyUsage(t *testing.T) {
 	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
 
@@ -251,6 +290,159 @@ func TestRenderPrioritizesAccountsForWeeklyUsage(t *testing.T) {
 	assert.Less(t, midIndex, blockedIndex)
 }
 
+func TestRenderReportsEarliestUpcomingResetAcrossAccounts(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+
+	output, err := Render([]application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			DailyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowDaily,
+				Percent:    100,
+				ResetsAt:   now.Add(-30 * time.Minute),
+				CapturedAt: now.Add(-2 * time.Hour),
+			},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    40,
+				ResetsAt:   now.Add(4 * 24 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+		{
+			Account: domain.Account{ID: "acc-2", Name: "Secondary", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			DailyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowDaily,
+				Percent:    30,
+				CapturedAt: now,
+			},
+		},
+		{
+			Account: domain.Account{ID: "acc-3", Name: "Third", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			DailyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowDaily,
+				Percent:    80,
+				ResetsAt:   now.Add(4 * time.Hour),
+				CapturedAt: now,
+			},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    90,
+				ResetsAt:   now.Add(3 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}, RenderOptions{Now: now, StaleAfter: 6 * time.Hour})
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "next reset: Third (acc-3) weekly in 3 hours (14:00)")
+	assert.Equal(t, 1, strings.Count(output, "next reset:"))
+}
+
+func TestRenderWarnsWhenFleetWeeklyPressureIsBelowFloor(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+	weekly := func(percent float64, resetIn time.Duration) *application.StatusLimit {
+		return &application.StatusLimit{Window: application.LimitWindowWeekly, Percent: percent, ResetsAt: now.Add(resetIn), CapturedAt: now}
+	}
+	statuses := []application.Status{
+		{Account: domain.Account{ID: "acc-1", Name: "Primary"}, WeeklyLimit: weekly(80, 100*time.Hour)},
+		{Account: domain.Account{ID: "acc-2", Name: "Secondary"}, WeeklyLimit: weekly(85, 50*time.Hour)},
+	}
+
+	output, err := Render(statuses, RenderOptions{Now: now, MinWeeklyPressure: 0.5})
+	require.NoError(t, err)
+	assert.Contains(t, output, "recommendation: use Secondary (acc-2) first")
+	assert.Contains(t, output, "warning: weekly pressure 0.30%/h is below 0.50%/h on every account (slow down or add accounts)")
+
+	output, err = Render(statuses, RenderOptions{Now: now, MinWeeklyPressure: 0.1})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "weekly pressure")
+
+	output, err = Render(statuses, RenderOptions{Now: now})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "weekly pressure")
+}
+
+func TestRenderRecommendationModes(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+	weekly := func(percent float64) *application.StatusLimit {
+		return &application.StatusLimit{Window: application.LimitWindowWeekly, Percent: percent, ResetsAt: now.Add(48 * time.Hour), CapturedAt: now}
+	}
+	statuses := []application.Status{
+		{Account: domain.Account{ID: "acc-1", Name: "Primary"}, WeeklyLimit: weekly(53)},
+		{Account: domain.Account{ID: "acc-2", Name: "Secondary"}, WeeklyLimit: weekly(80)},
+	}
+
+	tests := []struct {
+		mode     RecommendationMode
+		contains []string
+		excludes []string
+	}{
+		{
+			mode:     RecommendationFull,
+			contains: []string{"recommendation: use Primary (acc-1) first", "details: weekly 47% left", "next: Secondary (acc-2) (weekly 20% left"},
+		},
+		{
+			mode:     RecommendationCompact,
+			contains: []string{"recommendation: use Primary (acc-1), 47% weekly left"},
+			excludes: []string{"first", "details:", "next:"},
+		},
+		{
+			mode:     RecommendationOff,
+			contains: []string{"next reset: Primary (acc-1)", "47% left"},
+			excludes: []string{"recommendation:", "details:", "next:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			output, err := Render(statuses, RenderOptions{Now: now, RecommendationMode: tt.mode})
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, output, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, output, unwanted)
+			}
+		})
+	}
+
+	output, err := Render(statuses, RenderOptions{Now: now, RecommendationMode: RecommendationCompact, MinWeeklyPressure: 5})
+	require.NoError(t, err)
+	assert.Contains(t, output, "warning: weekly pressure")
+	output, err = Render(statuses, RenderOptions{Now: now, RecommendationMode: RecommendationOff, MinWeeklyPressure: 5})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "weekly pressure")
+
+	// Without any usable account, compact keeps the single waiting line.
+	exhausted := []application.Status{{Account: domain.Account{ID: "acc-1", Name: "Primary"}, WeeklyLimit: weekly(100)}}
+	output, err = Render(exhausted, RenderOptions{Now: now, RecommendationMode: RecommendationCompact})
+	require.NoError(t, err)
+	assert.Contains(t, output, "recommendation: no account available now (waiting for reset)")
+	output, err = Render(exhausted, RenderOptions{Now: now, RecommendationMode: RecommendationOff})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "recommendation:")
+}
+
+func TestRenderOmitsNextResetWithoutUpcomingResets(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+
+	output, err := Render([]application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			DailyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowDaily,
+				Percent:    10,
+				ResetsAt:   now.Add(-time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}, RenderOptions{Now: now, StaleAfter: 6 * time.Hour})
+
+	require.NoError(t, err)
+	assert.NotContains(t, output, "next reset:")
+}
+
 func TestRenderMarksActiveAccountInTitle(t *testing.T) {
 	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
 
@@ -279,3 +471,203 @@ func TestRenderMarksActiveAccountInTitle(t *testing.T) {
 	assert.Contains(t, output, "Account: active@example.com (Unknown, Active)")
 	assert.Contains(t, output, "Account: other@example.com (Unknown)")
 }
+
+func TestRenderResetFormats(t *testing.T) {
+	now := time.Date(2026, 2, 14, 13, 0, 0, 0, time.UTC)
+	statuses := []application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary"},
+			DailyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowDaily,
+				Percent:    40,
+				ResetsAt:   now.Add(3 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}
+
+	tests := []struct {
+		format   ResetFormat
+		contains string
+		excludes string
+	}{
+		{format: ResetFormatRelative, contains: "(resets in 3 hours)", excludes: "16:00"},
+		{format: ResetFormatAbsolute, contains: "(resets at 2026-02-14 16:00 UTC)", excludes: "resets in"},
+		{format: ResetFormatBoth, contains: "(resets in 3 hours (16:00))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			output, err := Render(statuses, RenderOptions{Now: now, StaleAfter: 6 * time.Hour, ResetFormat: tt.format})
+
+			require.NoError(t, err)
+			assert.Contains(t, output, tt.contains)
+			if tt.excludes != "" {
+				assert.NotContains(t, output, tt.excludes)
+			}
+		})
+	}
+}
+
+func TestRenderPercentPrecision(t *testing.T) {
+	now := time.Date(2026, 2, 14, 13, 0, 0, 0, time.UTC)
+	statuses := []application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary"},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    26.8,
+				ResetsAt:   now.Add(48 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}
+
+	output, err := Render(statuses, RenderOptions{Now: now, Precision: 1})
+	require.NoError(t, err)
+	assert.Contains(t, output, "73.2% left")
+
+	output, err = Render(statuses, RenderOptions{Now: now})
+	require.NoError(t, err)
+	assert.Contains(t, output, "73% left")
+	assert.NotContains(t, output, "73.2%")
+}
+
+func TestRenderPercentModes(t *testing.T) {
+	now := time.Date(2026, 2, 14, 13, 0, 0, 0, time.UTC)
+	statuses := []application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary"},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    73,
+				ResetsAt:   now.Add(48 * time.Hour),
+				CapturedAt: now,
+			},
+		},
+	}
+	bar := "[======------------------]"
+
+	left, err := Render(statuses, RenderOptions{Now: now, PercentMode: PercentLeft})
+	require.NoError(t, err)
+	assert.Contains(t, left, bar+" 27% left")
+	assert.NotContains(t, left, "% used")
+
+	used, err := Render(statuses, RenderOptions{Now: now, PercentMode: PercentUsed})
+	require.NoError(t, err)
+	assert.Contains(t, used, bar+" 73% used")
+	assert.NotContains(t, used, bar+" 27% left")
+
+	defaulted, err := Render(statuses, RenderOptions{Now: now})
+	require.NoError(t, err)
+	assert.Equal(t, left, defaulted)
+}
+
+func TestParsePercentMode(t *testing.T) {
+	mode, err := ParsePercentMode("")
+	require.NoError(t, err)
+	assert.Equal(t, PercentLeft, mode)
+
+	mode, err = ParsePercentMode("Used")
+	require.NoError(t, err)
+	assert.Equal(t, PercentUsed, mode)
+
+	_, err = ParsePercentMode("remaining")
+	require.Error(t, err)
+}
+
+func TestParseResetFormat(t *testing.T) {
+	format, err := ParseResetFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, ResetFormatBoth, format)
+
+	format, err = ParseResetFormat("Absolute")
+	require.NoError(t, err)
+	assert.Equal(t, ResetFormatAbsolute, format)
+
+	_, err = ParseResetFormat("iso")
+	require.Error(t, err)
+}
+
+func TestParseRecommendationMode(t *testing.T) {
+	mode, err := ParseRecommendationMode("")
+	require.NoError(t, err)
+	assert.Equal(t, RecommendationFull, mode)
+
+	mode, err = ParseRecommendationMode("Compact")
+	require.NoError(t, err)
+	assert.Equal(t, RecommendationCompact, mode)
+
+	_, err = ParseRecommendationMode("none")
+	require.Error(t, err)
+}
+
+func TestRenderSumsTokenTotalsAcrossAccounts(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+
+	output, err := Render([]application.Status{
+		{
+			Account: domain.Account{ID: "acc-1", Name: "Primary", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			Usage:   domain.Usage{InputTokens: 700_000, OutputTokens: 200_000, CachedInputTokens: 100_000},
+		},
+		{
+			Account: domain.Account{ID: "acc-2", Name: "Secondary", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}},
+			Usage:   domain.Usage{InputTokens: 150_000, OutputTokens: 50_000},
+		},
+		{
+			Account: domain.Account{ID: "acc-3", Name: "Team", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+		},
+	}, RenderOptions{Now: now, StaleAfter: 6 * time.Hour})
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "total: 1.2M tokens")
+}
+
+func TestRenderOmitsTokenTotalWhenOnlyChatGPTAccounts(t *testing.T) {
+	output, err := Render([]application.Status{
+		{Account: domain.Account{ID: "acc-1", Name: "Team", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}}},
+	}, RenderOptions{Now: time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)})
+
+	require.NoError(t, err)
+	assert.NotContains(t, output, "total:")
+}
+
+func TestRenderFiltersByWeeklyPercentLeft(t *testing.T) {
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+	weekly := func(id domain.AccountID, used float64) application.Status {
+		return application.Status{
+			Account: domain.Account{ID: id, Name: string(id)},
+			WeeklyLimit: &application.StatusLimit{
+				Window:     application.LimitWindowWeekly,
+				Percent:    used,
+				ResetsAt:   now.Add(72 * time.Hour),
+				CapturedAt: now,
+			},
+		}
+	}
+	statuses := []application.Status{
+		weekly("acc-full", 10),
+		weekly("acc-half", 50),
+		weekly("acc-low", 95),
+		{Account: domain.Account{ID: "acc-none", Name: "acc-none"}},
+	}
+	ids := func(filtered []application.Status) []domain.AccountID {
+		out := make([]domain.AccountID, 0, len(filtered))
+		for _, status := range filtered {
+			out = append(out, status.Account.ID)
+		}
+		return out
+	}
+	bound := func(v float64) *float64 { return &v }
+
+	assert.Len(t, FilterByWeeklyLeft(statuses, RenderOptions{}), 4)
+	assert.Equal(t, []domain.AccountID{"acc-full", "acc-half"}, ids(FilterByWeeklyLeft(statuses, RenderOptions{MinWeeklyLeft: bound(50)})))
+	assert.Equal(t, []domain.AccountID{"acc-low"}, ids(FilterByWeeklyLeft(statuses, RenderOptions{MaxWeeklyLeft: bound(10)})))
+	assert.Equal(t, []domain.AccountID{"acc-half"}, ids(FilterByWeeklyLeft(statuses, RenderOptions{MinWeeklyLeft: bound(20), MaxWeeklyLeft: bound(60)})))
+
+	output, err := Render(statuses, RenderOptions{Now: now, MinWeeklyLeft: bound(50)})
+	require.NoError(t, err)
+	assert.Contains(t, output, "accounts: 2")
+	assert.NotContains(t, output, "acc-low")
+	assert.NotContains(t, output, "acc-none")
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+)
+
+func cloneAccount(account domain.Account) domain.Account {
+	account.Metadata.Tags = slices.Clone(account.Metadata.Tags)
+	account.Limits.Daily = clonePointer(account.Limits.Daily)
+	account.Limits.Weekly = clonePointer(account.Limits.Weekly)
+	if account.Limits.Features != nil {
+		features := make([]domain.FeatureLimitSnapshots, len(account.Limits.Features))
+		for i, feature := range account.Limits.Features {
+			feature.Daily = clonePointer(feature.Daily)
+			feature.Weekly = clonePointer(feature.Weekly)
+			features[i] = feature
+		}
+		account.Limits.Features = features
+	}
+	account.Subscription = clonePointer(account.Subscription)
+	account.History = slices.Clone(account.History)
+	return account
+}
+
+func clonePool(pool domain.Pool) domain.Pool {
+	pool.Members = slices.Clone(pool.Members)
+	return pool
+}
+
+func cloneRuntime(runtime domain.PoolRuntime) domain.PoolRuntime {
+	if runtime.Sessions == nil {
+		return runtime
+	}
+
+	sessions := make(map[string]domain.SessionLedger, len(runtime.Sessions))
+	for id, ledger := range runtime.Sessions {
+		ledger.AccountSessions = maps.Clone(ledger.AccountSessions)
+		ledger.Memory.Decisions = slices.Clone(ledger.Memory.Decisions)
+		ledger.Memory.PendingTasks = slices.Clone(ledger.Memory.PendingTasks)
+		ledger.Memory.LastCodeRefs = slices.Clone(ledger.Memory.LastCodeRefs)
+		sessions[id] = ledger
+	}
+	runtime.Sessions = sessions
+	return runtime
+}
+
+func clonePointer[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
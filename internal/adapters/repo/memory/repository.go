@@ -0,0 +1,292 @@
+// Package memory holds in-memory implementations of the repository ports, for
+// embedding oa as a library without touching the filesystem. All repositories
+// are safe for concurrent use and copy values in and out, so callers never
+// share slices or pointers with the stored state.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+)
+
+// AccountRepository keeps accounts in insertion order, like accounts.toml.
+type AccountRepository struct {
+	mu       sync.RWMutex
+	accounts []domain.Account
+}
+
+var _ ports.AccountRepository = (*AccountRepository)(nil)
+
+func NewAccountRepository(accounts ...domain.Account) *AccountRepository {
+	r := &AccountRepository{accounts: make([]domain.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		r.upsert(account)
+	}
+	return r
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id domain.AccountID) (domain.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Account{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.ID == id {
+			return cloneAccount(account), nil
+		}
+	}
+	return domain.Account{}, domain.ErrAccountNotFound
+}
+
+func (r *AccountRepository) Exists(ctx context.Context, id domain.AccountID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *AccountRepository) List(ctx context.Context) ([]domain.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]domain.Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, cloneAccount(account))
+	}
+	return accounts, nil
+}
+
+func (r *AccountRepository) Save(ctx context.Context, account domain.Account) error {
+	return r.SaveAll(ctx, []domain.Account{account})
+}
+
+// SaveAll upserts every account under one lock, so readers never observe a
+// partially applied batch.
+func (r *AccountRepository) SaveAll(ctx context.Context, accounts []domain.Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, account := range accounts {
+		r.upsert(account)
+	}
+	return nil
+}
+
+// Replace swaps the account stored under id for account in place.
+func (r *AccountRepository) Replace(ctx context.Context, id domain.AccountID, account domain.Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := -1
+	for i := range r.accounts {
+		switch r.accounts[i].ID {
+		case id:
+			index = i
+		case account.ID:
+			return fmt.Errorf("account %s: %w", account.ID, domain.ErrAccountExists)
+		}
+	}
+	if index < 0 {
+		return domain.ErrAccountNotFound
+	}
+	r.accounts[index] = cloneAccount(account)
+	return nil
+}
+
+// Delete removes the account stored under id.
+func (r *AccountRepository) Delete(ctx context.Context, id domain.AccountID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.accounts {
+		if r.accounts[i].ID == id {
+			r.accounts = slices.Delete(r.accounts, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrAccountNotFound
+}
+
+func (r *AccountRepository) upsert(account domain.Account) {
+	account = cloneAccount(account)
+	for i := range r.accounts {
+		if r.accounts[i].ID == account.ID {
+			r.accounts[i] = account
+			return
+		}
+	}
+	r.accounts = append(r.accounts, account)
+}
+
+// PoolRepository keeps pools in insertion order, like pools.toml.
+type PoolRepository struct {
+	mu    sync.RWMutex
+	pools []domain.Pool
+}
+
+var _ ports.PoolRepository = (*PoolRepository)(nil)
+
+func NewPoolRepository(pools ...domain.Pool) *PoolRepository {
+	r := &PoolRepository{pools: make([]domain.Pool, 0, len(pools))}
+	for _, pool := range pools {
+		r.upsert(pool)
+	}
+	return r
+}
+
+func (r *PoolRepository) GetByID(ctx context.Context, id domain.PoolID) (domain.Pool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Pool{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, pool := range r.pools {
+		if pool.ID == id {
+			return clonePool(pool), nil
+		}
+	}
+	return domain.Pool{}, domain.ErrPoolNotFound
+}
+
+func (r *PoolRepository) List(ctx context.Context) ([]domain.Pool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pools := make([]domain.Pool, 0, len(r.pools))
+	for _, pool := range r.pools {
+		pools = append(pools, clonePool(pool))
+	}
+	return pools, nil
+}
+
+func (r *PoolRepository) Save(ctx context.Context, pool domain.Pool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.upsert(pool)
+	return nil
+}
+
+func (r *PoolRepository) upsert(pool domain.Pool) {
+	pool = clonePool(pool)
+	for i := range r.pools {
+		if r.pools[i].ID == pool.ID {
+			r.pools[i] = pool
+			return
+		}
+	}
+	r.pools = append(r.pools, pool)
+}
+
+// PoolRuntimeRepository keeps one runtime per pool.
+type PoolRuntimeRepository struct {
+	mu       sync.RWMutex
+	runtimes []domain.PoolRuntime
+}
+
+var _ ports.PoolRuntimeRepository = (*PoolRuntimeRepository)(nil)
+
+func NewPoolRuntimeRepository(runtimes ...domain.PoolRuntime) *PoolRuntimeRepository {
+	r := &PoolRuntimeRepository{runtimes: make([]domain.PoolRuntime, 0, len(runtimes))}
+	for _, runtime := range runtimes {
+		r.upsert(runtime)
+	}
+	return r
+}
+
+func (r *PoolRuntimeRepository) GetByPoolID(ctx context.Context, poolID domain.PoolID) (domain.PoolRuntime, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.PoolRuntime{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, runtime := range r.runtimes {
+		if runtime.PoolID == poolID {
+			return cloneRuntime(runtime), nil
+		}
+	}
+	return domain.PoolRuntime{}, domain.ErrPoolNotFound
+}
+
+func (r *PoolRuntimeRepository) List(ctx context.Context) ([]domain.PoolRuntime, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	runtimes := make([]domain.PoolRuntime, 0, len(r.runtimes))
+	for _, runtime := range r.runtimes {
+		runtimes = append(runtimes, cloneRuntime(runtime))
+	}
+	return runtimes, nil
+}
+
+func (r *PoolRuntimeRepository) Save(ctx context.Context, runtime domain.PoolRuntime) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.upsert(runtime)
+	return nil
+}
+
+func (r *PoolRuntimeRepository) upsert(runtime domain.PoolRuntime) {
+	runtime = cloneRuntime(runtime)
+	for i := range r.runtimes {
+		if r.runtimes[i].PoolID == runtime.PoolID {
+			r.runtimes[i] = runtime
+			return
+		}
+	}
+	r.runtimes = append(r.runtimes, runtime)
+}
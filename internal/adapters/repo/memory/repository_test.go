@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRepositoryConcurrentSavesAndReads(t *testing.T) {
+	t.Parallel()
+
+	repo := NewAccountRepository()
+	ctx := context.Background()
+
+	const writers = 16
+	const perWriter = 25
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				id := domain.AccountID(fmt.Sprintf("%d-%d", w, i))
+				assert.NoError(t, repo.Save(ctx, domain.Account{ID: id, Metadata: domain.AccountMetadata{Tags: []string{"work"}}}))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				accounts, err := repo.List(ctx)
+				assert.NoError(t, err)
+				for _, account := range accounts {
+					account.Metadata.Tags[0] = "mutated"
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, writers*perWriter)
+	for _, account := range accounts {
+		assert.Equal(t, []string{"work"}, account.Metadata.Tags, "callers must not share stored slices")
+	}
+}
+
+func TestAccountRepositoryKeepsInsertionOrderAndCopies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	weekly := &domain.AccountLimitSnapshot{Percent: 40}
+	repo := NewAccountRepository(
+		domain.Account{ID: "2", Limits: domain.AccountLimitSnapshots{Weekly: weekly}},
+		domain.Account{ID: "1"},
+	)
+	weekly.Percent = 99
+
+	require.NoError(t, repo.SaveAll(ctx, []domain.Account{{ID: "2", Name: "renamed", Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 40}}}, {ID: "3"}}))
+
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	assert.Equal(t, []domain.AccountID{"2", "1", "3"}, []domain.AccountID{accounts[0].ID, accounts[1].ID, accounts[2].ID})
+	assert.Equal(t, "renamed", accounts[0].Name)
+
+	got, err := repo.GetByID(ctx, "2")
+	require.NoError(t, err)
+	got.Limits.Weekly.Percent = 100
+	again, err := repo.GetByID(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, again.Limits.Weekly.Percent)
+
+	exists, err := repo.Exists(ctx, "1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	_, err = repo.GetByID(ctx, "missing")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	require.NoError(t, repo.Replace(ctx, "1", domain.Account{ID: "renamed"}))
+	accounts, err = repo.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"2", "renamed", "3"}, []domain.AccountID{accounts[0].ID, accounts[1].ID, accounts[2].ID})
+	require.ErrorIs(t, repo.Replace(ctx, "renamed", domain.Account{ID: "3"}), domain.ErrAccountExists)
+	require.ErrorIs(t, repo.Replace(ctx, "1", domain.Account{ID: "4"}), domain.ErrAccountNotFound)
+
+	require.NoError(t, repo.Delete(ctx, "renamed"))
+	accounts, err = repo.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"2", "3"}, []domain.AccountID{accounts[0].ID, accounts[1].ID})
+	require.ErrorIs(t, repo.Delete(ctx, "renamed"), domain.ErrAccountNotFound)
+}
+
+func TestPoolRepositoryConcurrentSaves(t *testing.T) {
+	t.Parallel()
+
+	repo := NewPoolRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := domain.PoolID(fmt.Sprintf("pool-%d", i%4))
+			assert.NoError(t, repo.Save(ctx, domain.Pool{ID: id, Members: []domain.AccountID{domain.AccountID(fmt.Sprint(i))}}))
+			_, err := repo.GetByID(ctx, id)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	pools, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pools, 4)
+
+	_, err = repo.GetByID(ctx, "missing")
+	require.ErrorIs(t, err, domain.ErrPoolNotFound)
+}
+
+func TestPoolRuntimeRepositoryConcurrentSessionUpdates(t *testing.T) {
+	t.Parallel()
+
+	repo := NewPoolRuntimeRepository(domain.PoolRuntime{PoolID: "default-openai", Sessions: map[string]domain.SessionLedger{}})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			runtime, err := repo.GetByPoolID(ctx, "default-openai")
+			if !assert.NoError(t, err) {
+				return
+			}
+			// Writing into the returned map must not race with other readers.
+			runtime.Sessions[fmt.Sprint(i)] = domain.SessionLedger{LogicalSessionID: fmt.Sprint(i)}
+			assert.NoError(t, repo.Save(ctx, runtime))
+		}(i)
+	}
+	wg.Wait()
+
+	runtimes, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, runtimes, 1)
+	assert.NotEmpty(t, runtimes[0].Sessions)
+
+	_, err = repo.GetByPoolID(ctx, "missing")
+	require.ErrorIs(t, err, domain.ErrPoolNotFound)
+}
@@ -33,14 +33,14 @@ func NewPoolRepository(cfg *viper.Viper) (*PoolRepository, error) {
 		cfg = viper.New()
 	}
 
-	homeDir, err := os.UserHomeDir()
+	configDir, err := ResolveConfigDir(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("resolve home directory: %w", err)
+		return nil, err
 	}
 
 	path := cfg.GetString(poolsPathKey)
 	if path == "" {
-		path = filepath.Join(homeDir, accountsConfigDir, poolConfigFile)
+		path = filepath.Join(configDir, poolConfigFile)
 	}
 
 	path, err = normalizeAccountsPath(path)
@@ -51,6 +51,24 @@ func NewPoolRepository(cfg *viper.Viper) (*PoolRepository, error) {
 	return &PoolRepository{path: path, mu: lockForPath(path)}, nil
 }
 
+// Path returns the pools file this repository reads and writes.
+func (r *PoolRepository) Path() string {
+	return r.path
+}
+
+// Hold runs fn while writes to the pools file are blocked, so fn sees a
+// single version of it.
+func (r *PoolRepository) Hold(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return fn()
+}
+
 func (r *PoolRepository) Save(ctx context.Context, pool domain.Pool) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -157,14 +175,14 @@ func NewPoolRuntimeRepository(cfg *viper.Viper) (*PoolRuntimeRepository, error)
 		cfg = viper.New()
 	}
 
-	homeDir, err := os.UserHomeDir()
+	configDir, err := ResolveConfigDir(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("resolve home directory: %w", err)
+		return nil, err
 	}
 
 	path := cfg.GetString(poolRuntimePath)
 	if path == "" {
-		path = filepath.Join(homeDir, accountsConfigDir, runtimeFileName)
+		path = filepath.Join(configDir, runtimeFileName)
 	}
 
 	path, err = normalizeAccountsPath(path)
@@ -175,6 +193,24 @@ func NewPoolRuntimeRepository(cfg *viper.Viper) (*PoolRuntimeRepository, error)
 	return &PoolRuntimeRepository{path: path, mu: lockForPath(path)}, nil
 }
 
+// Path returns the runtime file this repository reads and writes.
+func (r *PoolRuntimeRepository) Path() string {
+	return r.path
+}
+
+// Hold runs fn while writes to the runtime file are blocked, so fn sees a
+// single version of it.
+func (r *PoolRuntimeRepository) Hold(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return fn()
+}
+
 func (r *PoolRuntimeRepository) GetByPoolID(ctx context.Context, poolID domain.PoolID) (domain.PoolRuntime, error) {
 	if err := ctx.Err(); err != nil {
 		return domain.PoolRuntime{}, err
@@ -197,6 +233,27 @@ func (r *PoolRuntimeRepository) GetByPoolID(ctx context.Context, poolID domain.P
 	return domain.PoolRuntime{}, domain.ErrPoolNotFound
 }
 
+func (r *PoolRuntimeRepository) List(ctx context.Context) ([]domain.PoolRuntime, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	file, err := r.readSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	runtimes := make([]domain.PoolRuntime, 0, len(file.Runtimes))
+	for _, entry := range file.Runtimes {
+		runtimes = append(runtimes, fromPoolRuntimeSchema(entry))
+	}
+
+	return runtimes, nil
+}
+
 func (r *PoolRuntimeRepository) Save(ctx context.Context, runtime domain.PoolRuntime) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -311,6 +368,8 @@ func toPoolSchema(pool domain.Pool) poolSchema {
 		Strategy:        string(pool.Strategy),
 		Active:          pool.Active,
 		AutoSyncMembers: pool.AutoSyncMembers,
+		MemberTag:       pool.MemberTag,
+		RespectDaily:    pool.RespectDaily,
 		Members:         members,
 		UpdatedAt:       formatTime(pool.UpdatedAt),
 	}
@@ -329,6 +388,8 @@ func fromPoolSchema(schema poolSchema) domain.Pool {
 		Strategy:        domain.PoolStrategy(schema.Strategy),
 		Active:          schema.Active,
 		AutoSyncMembers: schema.AutoSyncMembers,
+		MemberTag:       schema.MemberTag,
+		RespectDaily:    schema.RespectDaily,
 		Members:         members,
 		UpdatedAt:       parseTime(schema.UpdatedAt),
 	}
@@ -352,14 +413,22 @@ func toPoolRuntimeSchema(runtime domain.PoolRuntime) poolRuntimeSchema {
 				LastCodeRefs: session.Memory.LastCodeRefs,
 				UpdatedAt:    formatTime(session.Memory.UpdatedAt),
 			},
+			LastActiveAt: formatTime(session.LastActiveAt),
 		})
 	}
 
+	minSwitchInterval := ""
+	if runtime.MinSwitchInterval > 0 {
+		minSwitchInterval = runtime.MinSwitchInterval.String()
+	}
+
 	return poolRuntimeSchema{
-		PoolID:          string(runtime.PoolID),
-		ActiveAccountID: string(runtime.ActiveAccountID),
-		LastSyncedAt:    formatTime(runtime.LastSyncedAt),
-		Sessions:        sessions,
+		PoolID:            string(runtime.PoolID),
+		ActiveAccountID:   string(runtime.ActiveAccountID),
+		LastSyncedAt:      formatTime(runtime.LastSyncedAt),
+		LastSwitchedAt:    formatTime(runtime.LastSwitchedAt),
+		MinSwitchInterval: minSwitchInterval,
+		Sessions:          sessions,
 	}
 }
 
@@ -381,13 +450,16 @@ func fromPoolRuntimeSchema(schema poolRuntimeSchema) domain.PoolRuntime {
 				LastCodeRefs: session.Memory.LastCodeRefs,
 				UpdatedAt:    parseTime(session.Memory.UpdatedAt),
 			},
+			LastActiveAt: parseTime(session.LastActiveAt),
 		}
 	}
 
 	return domain.PoolRuntime{
-		PoolID:          domain.PoolID(schema.PoolID),
-		ActiveAccountID: domain.AccountID(schema.ActiveAccountID),
-		LastSyncedAt:    parseTime(schema.LastSyncedAt),
-		Sessions:        sessions,
+		PoolID:            domain.PoolID(schema.PoolID),
+		ActiveAccountID:   domain.AccountID(schema.ActiveAccountID),
+		LastSyncedAt:      parseTime(schema.LastSyncedAt),
+		LastSwitchedAt:    parseTime(schema.LastSwitchedAt),
+		MinSwitchInterval: parseDuration(schema.MinSwitchInterval),
+		Sessions:          sessions,
 	}
 }
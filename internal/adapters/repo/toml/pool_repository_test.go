@@ -29,6 +29,8 @@ func TestPoolRepositoryRoundTrip(t *testing.T) {
 		Strategy:        domain.PoolStrategyLeastWeeklyUsed,
 		Active:          true,
 		AutoSyncMembers: true,
+		MemberTag:       "work",
+		RespectDaily:    true,
 		Members:         []domain.AccountID{"1", "2"},
 		UpdatedAt:       time.Date(2026, 2, 28, 10, 0, 0, 0, time.UTC),
 	}
@@ -55,9 +57,11 @@ func TestPoolRuntimeRepositoryRoundTrip(t *testing.T) {
 	require.NoError(t, err)
 
 	runtime := domain.PoolRuntime{
-		PoolID:          "default-openai",
-		ActiveAccountID: "2",
-		LastSyncedAt:    time.Date(2026, 2, 28, 10, 30, 0, 0, time.UTC),
+		PoolID:            "default-openai",
+		ActiveAccountID:   "2",
+		LastSyncedAt:      time.Date(2026, 2, 28, 10, 30, 0, 0, time.UTC),
+		LastSwitchedAt:    time.Date(2026, 2, 28, 10, 20, 0, 0, time.UTC),
+		MinSwitchInterval: 90 * time.Second,
 		Sessions: map[string]domain.SessionLedger{
 			"workspace-a": {
 				LogicalSessionID: "workspace-a",
@@ -31,6 +31,8 @@ type poolSchema struct {
 	Strategy        string   `toml:"strategy"`
 	Active          bool     `toml:"active"`
 	AutoSyncMembers bool     `toml:"auto_sync_members"`
+	MemberTag       string   `toml:"member_tag,omitempty"`
+	RespectDaily    bool     `toml:"respect_daily,omitempty"`
 	Members         []string `toml:"members"`
 	UpdatedAt       string   `toml:"updated_at"`
 }
@@ -55,16 +57,19 @@ func (s poolRuntimeFileSchema) validateVersion() error {
 }
 
 type poolRuntimeSchema struct {
-	PoolID          string                `toml:"pool_id"`
-	ActiveAccountID string                `toml:"active_account_id"`
-	LastSyncedAt    string                `toml:"last_synced_at"`
-	Sessions        []sessionLedgerSchema `toml:"sessions"`
+	PoolID            string                `toml:"pool_id"`
+	ActiveAccountID   string                `toml:"active_account_id"`
+	LastSyncedAt      string                `toml:"last_synced_at"`
+	LastSwitchedAt    string                `toml:"last_switched_at,omitempty"`
+	MinSwitchInterval string                `toml:"min_switch_interval,omitempty"`
+	Sessions          []sessionLedgerSchema `toml:"sessions"`
 }
 
 type sessionLedgerSchema struct {
 	LogicalSessionID string               `toml:"logical_session_id"`
 	AccountSessions  []accountSessionPair `toml:"account_sessions"`
 	Memory           memoryPacketSchema   `toml:"memory"`
+	LastActiveAt     string               `toml:"last_active_at,omitempty"`
 }
 
 type accountSessionPair struct {
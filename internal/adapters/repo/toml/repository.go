@@ -19,15 +19,31 @@ const (
 	configName         = "config"
 	configType         = "toml"
 	accountsPathKey    = "accounts.path"
+	backupKey          = "accounts.backup"
+	backupKeepKey      = "accounts.backup_keep"
+	backupEnv          = "OA_BACKUP"
+	backupKeepEnv      = "OA_BACKUP_KEEP"
+	defaultBackupKeep  = 5
 	accountsFileMode   = 0o600
 	accountsDirMode    = 0o700
 	accountsConfigDir  = ".codex"
+	configDirEnv       = "OA_CONFIG_DIR"
 	accountsConfigFile = "accounts.toml"
 	tempFilePattern    = ".accounts-*.toml.tmp"
+	editFilePattern    = ".accounts-edit-*.toml"
 )
 
+// ErrInvalidEdit reports that Edit left the accounts file unchanged because
+// the edited copy does not decode.
+var ErrInvalidEdit = errors.New("edited accounts file is invalid")
+
+// ConfigDirKey overrides the config directory for a single repository,
+// taking precedence over $OA_CONFIG_DIR.
+const ConfigDirKey = "config.dir"
+
 type Repository struct {
 	accountsPath string
+	backupKeep   int
 	mu           *sync.RWMutex
 }
 
@@ -43,17 +59,20 @@ func NewRepository(cfg *viper.Viper) (*Repository, error) {
 		cfg = viper.New()
 	}
 
-	homeDir, err := os.UserHomeDir()
+	configDir, err := ResolveConfigDir(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("resolve home directory: %w", err)
+		return nil, err
 	}
 
-	defaultPath := filepath.Join(homeDir, accountsConfigDir, accountsConfigFile)
+	defaultPath := filepath.Join(configDir, accountsConfigFile)
 
 	cfg.SetConfigName(configName)
 	cfg.SetConfigType(configType)
-	cfg.AddConfigPath(filepath.Join(homeDir, accountsConfigDir))
+	cfg.AddConfigPath(configDir)
 	cfg.SetDefault(accountsPathKey, defaultPath)
+	cfg.SetDefault(backupKeepKey, defaultBackupKeep)
+	_ = cfg.BindEnv(backupKey, backupEnv)
+	_ = cfg.BindEnv(backupKeepKey, backupKeepEnv)
 
 	err = cfg.ReadInConfig()
 	if err != nil {
@@ -72,10 +91,122 @@ func NewRepository(cfg *viper.Viper) (*Repository, error) {
 		return nil, err
 	}
 
-	return &Repository{accountsPath: accountsPath, mu: lockForPath(accountsPath)}, nil
+	backupKeep := 0
+	if cfg.GetBool(backupKey) {
+		backupKeep = cfg.GetInt(backupKeepKey)
+		if backupKeep <= 0 {
+			return nil, fmt.Errorf("%s must be positive when backups are enabled, got %d", backupKeepKey, backupKeep)
+		}
+	}
+
+	return &Repository{accountsPath: accountsPath, backupKeep: backupKeep, mu: lockForPath(accountsPath)}, nil
+}
+
+// ConfigDir is the directory holding accounts.toml, pools.toml,
+// pool_runtime.toml and the file secrets: $OA_CONFIG_DIR, or ~/.codex.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(configDirEnv); dir != "" {
+		return normalizeAccountsPath(dir)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(homeDir, accountsConfigDir), nil
+}
+
+// ResolveConfigDir returns the directory set under ConfigDirKey in cfg, or
+// ConfigDir when none is set.
+func ResolveConfigDir(cfg *viper.Viper) (string, error) {
+	if cfg != nil {
+		if dir := cfg.GetString(ConfigDirKey); dir != "" {
+			return normalizeAccountsPath(dir)
+		}
+	}
+	return ConfigDir()
+}
+
+// Path returns the accounts file this repository reads and writes.
+func (r *Repository) Path() string {
+	return r.accountsPath
+}
+
+// Hold runs fn while writes to the accounts file are blocked, so fn sees a
+// single version of it.
+func (r *Repository) Hold(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return fn()
+}
+
+// Edit copies the accounts file to a temp file next to it and calls edit with
+// the copy's path. The accounts file is replaced by the copy only when the
+// copy decodes with a supported schema version; otherwise it is left as it
+// was. The repository lock is held throughout, so writes from this process
+// wait for the edit instead of being overwritten by it.
+func (r *Repository) Edit(ctx context.Context, edit func(path string) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	original, err := os.ReadFile(r.accountsPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read accounts file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.accountsPath), accountsDirMode); err != nil {
+		return fmt.Errorf("create accounts directory: %w", err)
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(r.accountsPath), editFilePattern)
+	if err != nil {
+		return fmt.Errorf("create accounts edit copy: %w", err)
+	}
+	tempName := tempFile.Name()
+	defer func() { _ = os.Remove(tempName) }()
+
+	_, writeErr := tempFile.Write(original)
+	if err := errors.Join(writeErr, tempFile.Chmod(accountsFileMode), tempFile.Close()); err != nil {
+		return fmt.Errorf("write accounts edit copy: %w", err)
+	}
+
+	if err := edit(tempName); err != nil {
+		return err
+	}
+
+	edited, err := os.ReadFile(tempName)
+	if err != nil {
+		return fmt.Errorf("read accounts edit copy: %w", err)
+	}
+	if _, err := decodeSchema(edited); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEdit, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.backupCurrent(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempName, r.accountsPath); err != nil {
+		return fmt.Errorf("replace accounts file: %w", err)
+	}
+	if err := os.Chmod(r.accountsPath, accountsFileMode); err != nil {
+		return fmt.Errorf("chmod accounts file: %w", err)
+	}
+
+	return nil
 }
 
 func (r *Repository) Save(ctx context.Context, account domain.Account) error {
+	return r.SaveAll(ctx, []domain.Account{account})
+}
+
+func (r *Repository) SaveAll(ctx context.Context, accounts []domain.Account) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -89,29 +220,100 @@ func (r *Repository) Save(ctx context.Context, account domain.Account) error {
 	}
 	file.applyDefaults()
 
-	encoded := toSchema(account)
-	updated := false
-	for i := range file.Accounts {
-		if file.Accounts[i].ID == encoded.ID {
-			file.Accounts[i] = encoded
-			updated = true
-			break
+	for _, account := range accounts {
+		upsertAccountSchema(&file, toSchema(account))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.writeSchema(file); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Replace rewrites the account stored under id in place. It fails with
+// domain.ErrAccountExists when account takes the ID of another account.
+func (r *Repository) Replace(ctx context.Context, id domain.AccountID, account domain.Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := r.readSchema()
+	if err != nil {
+		return err
+	}
+	file.applyDefaults()
+
+	index := -1
+	for i, entry := range file.Accounts {
+		switch entry.ID {
+		case string(id):
+			index = i
+		case string(account.ID):
+			return fmt.Errorf("account %s: %w", account.ID, domain.ErrAccountExists)
 		}
 	}
+	if index < 0 {
+		return domain.ErrAccountNotFound
+	}
+	file.Accounts[index] = toSchema(account)
 
-	if !updated {
-		file.Accounts = append(file.Accounts, encoded)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
+	return r.writeSchema(file)
+}
+
+// Delete removes the account stored under id.
+func (r *Repository) Delete(ctx context.Context, id domain.AccountID) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	if err := r.writeSchema(file); err != nil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := r.readSchema()
+	if err != nil {
 		return err
 	}
+	file.applyDefaults()
 
-	return nil
+	kept := file.Accounts[:0]
+	for _, entry := range file.Accounts {
+		if entry.ID != string(id) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(file.Accounts) {
+		return domain.ErrAccountNotFound
+	}
+	file.Accounts = kept
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.writeSchema(file)
+}
+
+func upsertAccountSchema(file *fileSchema, encoded accountSchema) {
+	for i := range file.Accounts {
+		if file.Accounts[i].ID == encoded.ID {
+			file.Accounts[i] = encoded
+			return
+		}
+	}
+
+	file.Accounts = append(file.Accounts, encoded)
 }
 
 func (r *Repository) GetByID(ctx context.Context, id domain.AccountID) (domain.Account, error) {
@@ -137,6 +339,41 @@ func (r *Repository) GetByID(ctx context.Context, id domain.AccountID) (domain.A
 	return domain.Account{}, domain.ErrAccountNotFound
 }
 
+// Exists reports whether an account with id is stored. It decodes only the
+// account IDs instead of building full accounts.
+func (r *Repository) Exists(ctx context.Context, id domain.AccountID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, err := os.ReadFile(r.accountsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read accounts file: %w", err)
+	}
+
+	var file accountIDsSchema
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return false, fmt.Errorf("decode accounts file: %w", err)
+	}
+	if err := (fileSchema{Version: file.Version}).validateVersion(); err != nil {
+		return false, err
+	}
+
+	for _, entry := range file.Accounts {
+		if entry.ID == string(id) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (r *Repository) List(ctx context.Context) ([]domain.Account, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -168,6 +405,10 @@ func (r *Repository) readSchema() (fileSchema, error) {
 		return fileSchema{}, fmt.Errorf("read accounts file: %w", err)
 	}
 
+	return decodeSchema(data)
+}
+
+func decodeSchema(data []byte) (fileSchema, error) {
 	var file fileSchema
 	if err := toml.Unmarshal(data, &file); err != nil {
 		return fileSchema{}, fmt.Errorf("decode accounts file: %w", err)
@@ -241,6 +482,10 @@ func (r *Repository) writeSchema(file fileSchema) error {
 		return fmt.Errorf("close temp accounts file: %w", err)
 	}
 
+	if err := r.backupCurrent(); err != nil {
+		return err
+	}
+
 	if err := os.Rename(tempName, r.accountsPath); err != nil {
 		return fmt.Errorf("replace accounts file: %w", err)
 	}
@@ -254,6 +499,41 @@ func (r *Repository) writeSchema(file fileSchema) error {
 	return nil
 }
 
+// backupCurrent copies the accounts file to accounts.toml.bak.1 before it is
+// replaced, shifting older backups up and keeping at most backupKeep of them.
+func (r *Repository) backupCurrent() error {
+	if r.backupKeep <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.accountsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read accounts file for backup: %w", err)
+	}
+
+	if err := os.Remove(backupPath(r.accountsPath, r.backupKeep)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove oldest accounts backup: %w", err)
+	}
+	for i := r.backupKeep - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(r.accountsPath, i), backupPath(r.accountsPath, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate accounts backup: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(backupPath(r.accountsPath, 1), data, accountsFileMode); err != nil {
+		return fmt.Errorf("write accounts backup: %w", err)
+	}
+
+	return nil
+}
+
+func backupPath(accountsPath string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", accountsPath, n)
+}
+
 func toSchema(account domain.Account) accountSchema {
 	limits := limitsSchema{}
 	if account.Limits.Daily != nil {
@@ -262,15 +542,25 @@ func toSchema(account domain.Account) accountSchema {
 	if account.Limits.Weekly != nil {
 		limits.Weekly = toLimitSnapshotSchema(account.Limits.Weekly)
 	}
+	for _, feature := range account.Limits.Features {
+		limits.Features = append(limits.Features, featureLimitsSchema{
+			Feature: feature.Feature,
+			Daily:   toLimitSnapshotSchema(feature.Daily),
+			Weekly:  toLimitSnapshotSchema(feature.Weekly),
+		})
+	}
 
 	return accountSchema{
-		ID:   string(account.ID),
-		Name: account.Name,
+		ID:       string(account.ID),
+		Name:     account.Name,
+		Disabled: account.Disabled,
 		Metadata: metadataSchema{
 			Provider:  account.Metadata.Provider,
 			Model:     account.Metadata.Model,
 			SecretRef: account.Metadata.SecretRef,
 			PlanType:  account.Metadata.PlanType,
+			Tags:      account.Metadata.Tags,
+			BaseURL:   account.Metadata.BaseURL,
 		},
 		Auth: authSchema{
 			Method:    string(account.Auth.Method),
@@ -283,6 +573,7 @@ func toSchema(account domain.Account) accountSchema {
 		},
 		Limits:       limits,
 		Subscription: toSubscriptionSchema(account.Subscription),
+		History:      toHistorySchema(account.History),
 	}
 }
 
@@ -298,13 +589,16 @@ func fromSchema(account accountSchema) domain.Account {
 	}
 
 	return domain.Account{
-		ID:   domain.AccountID(account.ID),
-		Name: account.Name,
+		ID:       domain.AccountID(account.ID),
+		Name:     account.Name,
+		Disabled: account.Disabled,
 		Metadata: domain.AccountMetadata{
 			Provider:  account.Metadata.Provider,
 			Model:     account.Metadata.Model,
 			SecretRef: metadataSecretRef,
 			PlanType:  account.Metadata.PlanType,
+			Tags:      account.Metadata.Tags,
+			BaseURL:   account.Metadata.BaseURL,
 		},
 		Auth: domain.Auth{
 			Method:    domain.AuthMethod(account.Auth.Method),
@@ -316,11 +610,47 @@ func fromSchema(account accountSchema) domain.Account {
 			CachedInputTokens: account.Usage.CachedInputTokens,
 		},
 		Limits: domain.AccountLimitSnapshots{
-			Daily:  fromLimitSnapshotSchema(account.Limits.Daily),
-			Weekly: fromLimitSnapshotSchema(account.Limits.Weekly),
+			Daily:    fromLimitSnapshotSchema(account.Limits.Daily),
+			Weekly:   fromLimitSnapshotSchema(account.Limits.Weekly),
+			Features: fromFeatureLimitsSchema(account.Limits.Features),
 		},
 		Subscription: fromSubscriptionSchema(account.Subscription),
+		History:      fromHistorySchema(account.History),
+	}
+}
+
+func toHistorySchema(history []domain.UsageHistoryEntry) []historyEntrySchema {
+	if len(history) == 0 {
+		return nil
 	}
+
+	entries := make([]historyEntrySchema, 0, len(history))
+	for _, entry := range history {
+		entries = append(entries, historyEntrySchema{
+			Window:     entry.Window,
+			Percent:    entry.Percent,
+			ResetsAt:   formatTime(entry.ResetsAt),
+			CapturedAt: formatTime(entry.CapturedAt),
+		})
+	}
+	return entries
+}
+
+func fromHistorySchema(history []historyEntrySchema) []domain.UsageHistoryEntry {
+	if len(history) == 0 {
+		return nil
+	}
+
+	entries := make([]domain.UsageHistoryEntry, 0, len(history))
+	for _, entry := range history {
+		entries = append(entries, domain.UsageHistoryEntry{
+			Window:     entry.Window,
+			Percent:    entry.Percent,
+			ResetsAt:   parseTime(entry.ResetsAt),
+			CapturedAt: parseTime(entry.CapturedAt),
+		})
+	}
+	return entries
 }
 
 func toLimitSnapshotSchema(snapshot *domain.AccountLimitSnapshot) *limitSnapshotSchema {
@@ -347,6 +677,22 @@ func fromLimitSnapshotSchema(snapshot *limitSnapshotSchema) *domain.AccountLimit
 	}
 }
 
+func fromFeatureLimitsSchema(features []featureLimitsSchema) []domain.FeatureLimitSnapshots {
+	if len(features) == 0 {
+		return nil
+	}
+
+	result := make([]domain.FeatureLimitSnapshots, 0, len(features))
+	for _, feature := range features {
+		result = append(result, domain.FeatureLimitSnapshots{
+			Feature: feature.Feature,
+			Daily:   fromLimitSnapshotSchema(feature.Daily),
+			Weekly:  fromLimitSnapshotSchema(feature.Weekly),
+		})
+	}
+	return result
+}
+
 func parseTime(raw string) time.Time {
 	if raw == "" {
 		return time.Time{}
@@ -360,6 +706,19 @@ func parseTime(raw string) time.Time {
 	return parsed
 }
 
+func parseDuration(raw string) time.Duration {
+	if raw == "" {
+		return 0
+	}
+
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed < 0 {
+		return 0
+	}
+
+	return parsed
+}
+
 func formatTime(value time.Time) string {
 	if value.IsZero() {
 		return ""
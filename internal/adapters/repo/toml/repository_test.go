@@ -34,6 +34,7 @@ func TestRepositoryRoundTrip(t *testing.T) {
 			Provider:  "openai",
 			Model:     "gpt-5",
 			SecretRef: "openai://acc-1",
+			Tags:      []string{"work"},
 		},
 		Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1"},
 	}
@@ -60,6 +61,31 @@ func TestRepositoryRoundTrip(t *testing.T) {
 	assert.ElementsMatch(t, []domain.Account{first, second}, accounts)
 }
 
+func TestRepositorySaveAllUpsertsAccountsInOneWrite(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Save(context.Background(), domain.Account{ID: "acc-1", Name: "Primary"}))
+
+	err = repo.SaveAll(context.Background(), []domain.Account{
+		{ID: "acc-1", Name: "Renamed"},
+		{ID: "acc-2", Name: "Backup"},
+	})
+	require.NoError(t, err)
+
+	accounts, err := repo.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "Renamed", accounts[0].Name)
+	assert.Equal(t, "Backup", accounts[1].Name)
+}
+
 func TestRepositoryRoundTripPersistsUsageAndLimitSnapshots(t *testing.T) {
 	t.Parallel()
 
@@ -100,6 +126,32 @@ func TestRepositoryRoundTripPersistsUsageAndLimitSnapshots(t *testing.T) {
 	assert.Equal(t, account.Limits.Weekly.Percent, got.Limits.Weekly.Percent)
 }
 
+func TestRepositoryRoundTripPersistsUsageHistory(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 2, 14, 11, 0, 0, 0, time.UTC)
+	account := domain.Account{
+		ID: "acc-1",
+		History: []domain.UsageHistoryEntry{
+			{Window: "daily", Percent: 10, ResetsAt: now.Add(5 * time.Hour), CapturedAt: now},
+			{Window: "weekly", Percent: 35, ResetsAt: now.Add(72 * time.Hour), CapturedAt: now.Add(time.Hour)},
+		},
+	}
+
+	require.NoError(t, repo.Save(context.Background(), account))
+
+	got, err := repo.GetByID(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account.History, got.History)
+}
+
 func TestRepositoryBackwardCompatibleWhenUsageAndLimitsMissing(t *testing.T) {
 	t.Parallel()
 
@@ -157,6 +209,21 @@ func TestRepositorySaveCreatesDefaultPathAndEnforcesPermissions(t *testing.T) {
 	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
 }
 
+func TestRepositoryConfigDirKeyOverridesEnv(t *testing.T) {
+	t.Setenv("OA_CONFIG_DIR", t.TempDir())
+	profileDir := t.TempDir()
+
+	config := viper.New()
+	config.Set(ConfigDirKey, profileDir)
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(profileDir, "accounts.toml"), repo.Path())
+
+	pools, err := NewPoolRepository(config)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(profileDir, "pools.toml"), pools.Path())
+}
+
 func TestRepositoryMissingFileBehaviors(t *testing.T) {
 	t.Parallel()
 
@@ -173,6 +240,84 @@ func TestRepositoryMissingFileBehaviors(t *testing.T) {
 
 	_, err = repo.GetByID(context.Background(), "acc-1")
 	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	exists, err := repo.Exists(context.Background(), "acc-1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestRepositoryExistsReportsPresentAndAbsentIDs(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveAll(context.Background(), []domain.Account{
+		{ID: "acc-1", Name: "Primary"},
+		{ID: "acc-2", Name: "Backup"},
+	}))
+
+	for id, want := range map[domain.AccountID]bool{"acc-1": true, "acc-2": true, "acc-3": false, "": false} {
+		exists, err := repo.Exists(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, want, exists, "account %q", id)
+	}
+}
+
+func TestRepositoryReplaceChangesIDInPlace(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, repo.SaveAll(ctx, []domain.Account{
+		{ID: "acc-1", Name: "Primary"},
+		{ID: "acc-2", Name: "Backup"},
+	}))
+
+	require.NoError(t, repo.Replace(ctx, "acc-1", domain.Account{ID: "1", Name: "Primary"}))
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, domain.AccountID("1"), accounts[0].ID)
+	assert.Equal(t, domain.AccountID("acc-2"), accounts[1].ID)
+
+	require.ErrorIs(t, repo.Replace(ctx, "1", domain.Account{ID: "acc-2"}), domain.ErrAccountExists)
+	require.ErrorIs(t, repo.Replace(ctx, "acc-1", domain.Account{ID: "3"}), domain.ErrAccountNotFound)
+}
+
+func TestRepositoryDeleteRemovesOnlyThatAccount(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, repo.SaveAll(ctx, []domain.Account{
+		{ID: "acc-1", Name: "Primary"},
+		{ID: "acc-2", Name: "Backup"},
+		{ID: "acc-3", Name: "Spare"},
+	}))
+
+	require.NoError(t, repo.Delete(ctx, "acc-2"))
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, domain.AccountID("acc-1"), accounts[0].ID)
+	assert.Equal(t, domain.AccountID("acc-3"), accounts[1].ID)
+
+	require.ErrorIs(t, repo.Delete(ctx, "acc-2"), domain.ErrAccountNotFound)
+	require.ErrorIs(t, repo.Delete(ctx, "missing"), domain.ErrAccountNotFound)
 }
 
 func TestRepositoryListMalformedTOMLReturnsError(t *testing.T) {
@@ -192,6 +337,135 @@ func TestRepositoryListMalformedTOMLReturnsError(t *testing.T) {
 	assert.ErrorContains(t, err, "decode accounts file")
 }
 
+func TestRepositoryEditReplacesFileOnlyWithValidCopy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	accountsPath := filepath.Join(dir, "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+	config.Set("accounts.backup_keep", 0)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+	assert.Equal(t, accountsPath, repo.Path())
+	require.NoError(t, os.WriteFile(accountsPath, []byte("version = 1\n"), 0o600))
+
+	writeCopy := func(content string) func(string) error {
+		return func(path string) error {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "version = 1\n", string(data), "the copy starts as the current file")
+			return os.WriteFile(path, []byte(content), 0o600)
+		}
+	}
+
+	err = repo.Edit(context.Background(), writeCopy("accounts = ["))
+	require.ErrorIs(t, err, ErrInvalidEdit)
+	assert.ErrorContains(t, err, "decode accounts file")
+
+	err = repo.Edit(context.Background(), writeCopy("version = 9\n"))
+	require.ErrorIs(t, err, ErrInvalidEdit)
+	assert.ErrorContains(t, err, "unsupported accounts schema version 9")
+
+	data, err := os.ReadFile(accountsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "version = 1\n", string(data))
+
+	require.NoError(t, repo.Edit(context.Background(), writeCopy("version = 1\n\n[[accounts]]\nid = \"1\"\nname = \"Edited\"\n")))
+	account, err := repo.GetByID(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", account.Name)
+
+	leftovers, err := filepath.Glob(filepath.Join(dir, ".accounts-edit-*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestRepositoryHoldBlocksWritesUntilDone(t *testing.T) {
+	t.Parallel()
+
+	config := viper.New()
+	config.Set("accounts.path", filepath.Join(t.TempDir(), "accounts.toml"))
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+
+	saved := make(chan error, 1)
+	err = repo.Hold(context.Background(), func() error {
+		go func() {
+			saved <- repo.Save(context.Background(), domain.Account{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}})
+		}()
+		select {
+		case <-saved:
+			t.Fatal("save finished while the repository was held")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-saved)
+
+	exists, err := repo.Exists(context.Background(), "1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestRepositorySaveBacksUpPreviousFileAndCapsRotation(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+	config.Set("accounts.backup", true)
+	config.Set("accounts.backup_keep", 2)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+
+	save := func(name string) string {
+		t.Helper()
+		require.NoError(t, repo.Save(context.Background(), domain.Account{ID: "acc-1", Name: name}))
+		data, err := os.ReadFile(accountsPath)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	first := save("first")
+	assert.NoFileExists(t, accountsPath+".bak.1")
+
+	second := save("second")
+	backup, err := os.ReadFile(accountsPath + ".bak.1")
+	require.NoError(t, err)
+	assert.Equal(t, first, string(backup))
+
+	save("third")
+	save("fourth")
+
+	newest, err := os.ReadFile(accountsPath + ".bak.1")
+	require.NoError(t, err)
+	assert.Contains(t, string(newest), "third")
+	oldest, err := os.ReadFile(accountsPath + ".bak.2")
+	require.NoError(t, err)
+	assert.Contains(t, string(oldest), "second")
+	assert.NotEqual(t, second, string(newest))
+	assert.NoFileExists(t, accountsPath+".bak.3")
+}
+
+func TestRepositorySaveSkipsBackupByDefault(t *testing.T) {
+	t.Parallel()
+
+	accountsPath := filepath.Join(t.TempDir(), "accounts.toml")
+	config := viper.New()
+	config.Set("accounts.path", accountsPath)
+
+	repo, err := NewRepository(config)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Save(context.Background(), domain.Account{ID: "acc-1", Name: "first"}))
+	require.NoError(t, repo.Save(context.Background(), domain.Account{ID: "acc-1", Name: "second"}))
+	assert.NoFileExists(t, accountsPath+".bak.1")
+}
+
 func TestRepositorySaveCanceledContextReturnsContextError(t *testing.T) {
 	t.Parallel()
 
@@ -297,4 +571,7 @@ func TestRepositoryFutureSchemaVersionReturnsError(t *testing.T) {
 	_, err = repo.List(context.Background())
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "unsupported accounts schema version")
+
+	_, err = repo.Exists(context.Background(), "acc-1")
+	assert.ErrorContains(t, err, "unsupported accounts schema version")
 }
@@ -9,6 +9,14 @@ type fileSchema struct {
 	Accounts []accountSchema `toml:"accounts"`
 }
 
+// accountIDsSchema is the subset of fileSchema needed for existence checks.
+type accountIDsSchema struct {
+	Version  int `toml:"version"`
+	Accounts []struct {
+		ID string `toml:"id"`
+	} `toml:"accounts"`
+}
+
 func (s *fileSchema) applyDefaults() {
 	if s.Version == 0 {
 		s.Version = currentSchemaVersion
@@ -24,20 +32,24 @@ func (s fileSchema) validateVersion() error {
 }
 
 type accountSchema struct {
-	ID           string              `toml:"id"`
-	Name         string              `toml:"name"`
-	Metadata     metadataSchema      `toml:"metadata"`
-	Auth         authSchema          `toml:"auth"`
-	Usage        usageSchema         `toml:"usage,omitempty"`
-	Limits       limitsSchema        `toml:"limits,omitempty"`
-	Subscription *subscriptionSchema `toml:"subscription,omitempty"`
+	ID           string               `toml:"id"`
+	Name         string               `toml:"name"`
+	Disabled     bool                 `toml:"disabled,omitempty"`
+	Metadata     metadataSchema       `toml:"metadata"`
+	Auth         authSchema           `toml:"auth"`
+	Usage        usageSchema          `toml:"usage,omitempty"`
+	Limits       limitsSchema         `toml:"limits,omitempty"`
+	Subscription *subscriptionSchema  `toml:"subscription,omitempty"`
+	History      []historyEntrySchema `toml:"history,omitempty"`
 }
 
 type metadataSchema struct {
-	Provider  string `toml:"provider"`
-	Model     string `toml:"model"`
-	SecretRef string `toml:"secret_ref"`
-	PlanType  string `toml:"plan_type,omitempty"`
+	Provider  string   `toml:"provider"`
+	Model     string   `toml:"model"`
+	SecretRef string   `toml:"secret_ref"`
+	PlanType  string   `toml:"plan_type,omitempty"`
+	Tags      []string `toml:"tags,omitempty"`
+	BaseURL   string   `toml:"base_url,omitempty"`
 }
 
 type authSchema struct {
@@ -52,8 +64,15 @@ type usageSchema struct {
 }
 
 type limitsSchema struct {
-	Daily  *limitSnapshotSchema `toml:"daily,omitempty"`
-	Weekly *limitSnapshotSchema `toml:"weekly,omitempty"`
+	Daily    *limitSnapshotSchema  `toml:"daily,omitempty"`
+	Weekly   *limitSnapshotSchema  `toml:"weekly,omitempty"`
+	Features []featureLimitsSchema `toml:"features,omitempty"`
+}
+
+type featureLimitsSchema struct {
+	Feature string               `toml:"feature"`
+	Daily   *limitSnapshotSchema `toml:"daily,omitempty"`
+	Weekly  *limitSnapshotSchema `toml:"weekly,omitempty"`
 }
 
 type limitSnapshotSchema struct {
@@ -62,6 +81,13 @@ type limitSnapshotSchema struct {
 	CapturedAt string  `toml:"captured_at"`
 }
 
+type historyEntrySchema struct {
+	Window     string  `toml:"window"`
+	Percent    float64 `toml:"percent"`
+	ResetsAt   string  `toml:"resets_at"`
+	CapturedAt string  `toml:"captured_at"`
+}
+
 type subscriptionSchema struct {
 	ActiveStart     string `toml:"active_start"`
 	ActiveUntil     string `toml:"active_until"`
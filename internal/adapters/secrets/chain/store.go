@@ -4,26 +4,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 
 	filestore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/file"
 	passstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/pass"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
 )
 
+// Store tries its backends in order: the first one that succeeds serves the
+// operation and later ones are only consulted after a failure.
 type Store struct {
-	primary  ports.SecretStore
-	fallback ports.SecretStore
+	stores       []ports.SecretStore
+	writeThrough bool
+	refs         []refStore
+	warn         func(message string)
+	warnOnce     sync.Once
+}
+
+type refStore struct {
+	prefix string
+	store  ports.SecretStore
 }
 
 var _ ports.SecretStore = (*Store)(nil)
 
-var (
-	errNilPrimaryStore  = errors.New("primary secret store is nil")
-	errNilFallbackStore = errors.New("fallback secret store is nil")
-)
+var errNoStores = errors.New("secret store chain is empty")
 
-func NewStore(primary ports.SecretStore, fallback ports.SecretStore) *Store {
-	store, err := NewStoreChecked(primary, fallback)
+func NewStore(stores ...ports.SecretStore) *Store {
+	store, err := NewStoreChecked(stores...)
 	if err != nil {
 		panic(err)
 	}
@@ -31,70 +42,285 @@ func NewStore(primary ports.SecretStore, fallback ports.SecretStore) *Store {
 	return store
 }
 
-func NewStoreChecked(primary ports.SecretStore, fallback ports.SecretStore) (*Store, error) {
-	if primary == nil {
-		return nil, errNilPrimaryStore
+func NewStoreChecked(stores ...ports.SecretStore) (*Store, error) {
+	if len(stores) == 0 {
+		return nil, errNoStores
 	}
-	if fallback == nil {
-		return nil, errNilFallbackStore
+	for i, store := range stores {
+		if store == nil {
+			return nil, fmt.Errorf("secret store %d is nil", i+1)
+		}
 	}
 
-	return &Store{primary: primary, fallback: fallback}, nil
+	return &Store{stores: append([]ports.SecretStore(nil), stores...)}, nil
+}
+
+// NewWriteThroughStore chains stores like NewStoreChecked, but Put and Delete
+// apply to every backend and fail only when all of them do, so each backend
+// keeps a full copy. Get still returns the first backend that has the key, so
+// a backend that misses a Put has its old copy deleted.
+func NewWriteThroughStore(stores ...ports.SecretStore) (*Store, error) {
+	store, err := NewStoreChecked(stores...)
+	if err != nil {
+		return nil, err
+	}
+	store.writeThrough = true
+	return store, nil
 }
 
 func NewPassFirstWithFileFallback(fileRoot string) (*Store, error) {
 	return NewStoreChecked(passstore.NewStore(), filestore.NewStore(fileRoot))
 }
 
+// WithWarnings registers a handler for degraded-storage warnings, such as
+// secrets falling back to plaintext files because pass is not initialized.
+func (s *Store) WithWarnings(warn func(message string)) *Store {
+	s.warn = warn
+	return s
+}
+
+// WithRefStore routes keys starting with prefix to store instead of the
+// chained backends, e.g. cmd:// refs resolved by running a
+// command.
+func (s *Store) WithRefStore(prefix string, store ports.SecretStore) *Store {
+	s.refs = append(s.refs, refStore{prefix: prefix, store: store})
+	return s
+}
+
+func (s *Store) refStoreFor(key string) (ports.SecretStore, bool) {
+	trimmed := strings.TrimSpace(key)
+	for _, ref := range s.refs {
+		if strings.HasPrefix(trimmed, ref.prefix) {
+			return ref.store, true
+		}
+	}
+	return nil, false
+}
+
 func (s *Store) Put(ctx context.Context, key string, value string) error {
-	err := s.primary.Put(ctx, key, value)
-	if err == nil {
-		return nil
+	if store, ok := s.refStoreFor(key); ok {
+		return store.Put(ctx, key, value)
 	}
-	if shouldSkipFallback(err) {
-		return err
+
+	errs := make([]error, 0, len(s.stores))
+	var failed []int
+	for i, store := range s.stores {
+		err := store.Put(ctx, key, value)
+		if err == nil {
+			if !s.writeThrough {
+				return nil
+			}
+			continue
+		}
+		if shouldSkipFallback(err) {
+			return err
+		}
+		if errors.Is(err, passstore.ErrNotInitialized) && i < len(s.stores)-1 {
+			s.warnPlaintextFallback()
+		}
+		errs = append(errs, err)
+		failed = append(failed, i)
 	}
 
-	fallbackErr := s.fallback.Put(ctx, key, value)
-	if fallbackErr == nil {
-		return nil
+	if s.writeThrough && len(failed) > 0 && len(failed) < len(s.stores) {
+		return s.dropStaleCopies(ctx, key, failed, errs)
 	}
+	return s.combine("put", errs)
+}
 
-	return fmt.Errorf("primary backend put failed: %w; fallback backend put failed: %w", err, fallbackErr)
+// dropStaleCopies deletes key from the backends a write-through Put missed,
+// so Get cannot serve their old value (e.g. an already rotated token). The
+// Put fails when a stale copy survives ahead of every fresh one.
+func (s *Store) dropStaleCopies(ctx context.Context, key string, failed []int, errs []error) error {
+	firstWritten := 0
+	for firstWritten < len(failed) && failed[firstWritten] == firstWritten {
+		firstWritten++
+	}
+	for n, i := range failed {
+		err := s.stores[i].Delete(ctx, key)
+		if err == nil || i > firstWritten {
+			continue
+		}
+		errs[n] = fmt.Errorf("%w (stale copy not removed: %v)", errs[n], err)
+		return backendErrors{op: "put", errs: errs}
+	}
+	return nil
 }
 
 func (s *Store) Get(ctx context.Context, key string) (string, error) {
-	value, err := s.primary.Get(ctx, key)
-	if err == nil {
-		return value, nil
+	if store, ok := s.refStoreFor(key); ok {
+		return store.Get(ctx, key)
 	}
-	if shouldSkipFallback(err) {
-		return "", err
+
+	value, _, err := s.lookup(ctx, key)
+	return value, err
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if store, ok := s.refStoreFor(key); ok {
+		return store.Delete(ctx, key)
 	}
 
-	fallbackValue, fallbackErr := s.fallback.Get(ctx, key)
-	if fallbackErr == nil {
-		return fallbackValue, nil
+	errs := make([]error, 0, len(s.stores))
+	for _, store := range s.stores {
+		err := store.Delete(ctx, key)
+		if err == nil {
+			if !s.writeThrough {
+				return nil
+			}
+			continue
+		}
+		if shouldSkipFallback(err) {
+			return err
+		}
+		errs = append(errs, err)
 	}
 
-	return "", fmt.Errorf("primary backend get failed: %w; fallback backend get failed: %w", err, fallbackErr)
+	return s.combine("delete", errs)
 }
 
-func (s *Store) Delete(ctx context.Context, key string) error {
-	err := s.primary.Delete(ctx, key)
-	if err == nil {
-		return nil
+// Has reports whether any backend holds key. A backend that cannot answer
+// only fails the check when no other backend holds the key.
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if store, ok := s.refStoreFor(key); ok {
+		return store.Has(ctx, key)
+	}
+
+	errs := make([]error, 0, len(s.stores))
+	for _, store := range s.stores {
+		found, err := store.Has(ctx, key)
+		if err != nil {
+			if shouldSkipFallback(err) {
+				return false, err
+			}
+			if lacksKey(err) {
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if found {
+			return true, nil
+		}
 	}
-	if shouldSkipFallback(err) {
-		return err
+	if len(errs) > 0 {
+		return false, backendErrors{op: "has", errs: errs}
 	}
+	return false, nil
+}
 
-	fallbackErr := s.fallback.Delete(ctx, key)
-	if fallbackErr == nil {
+// combine turns the failures of a write into the operation's result: in
+// write-through mode the write succeeded if any backend took it.
+func (s *Store) combine(op string, errs []error) error {
+	if len(errs) == 0 || (s.writeThrough && len(errs) < len(s.stores)) {
 		return nil
 	}
+	return backendErrors{op: op, errs: errs}
+}
+
+// WhichBackend reports the name of the first backend that holds key. It only
+// checks presence, so no secret is decrypted to answer.
+func (s *Store) WhichBackend(ctx context.Context, key string) (string, error) {
+	if store, ok := s.refStoreFor(key); ok {
+		return backendName(store, "ref"), nil
+	}
+
+	errs := make([]error, 0, len(s.stores))
+	for i, store := range s.stores {
+		found, err := store.Has(ctx, key)
+		if err != nil {
+			if shouldSkipFallback(err) {
+				return "", err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if !found {
+			continue
+		}
+		if i == 0 {
+			return backendName(store, "primary"), nil
+		}
+		return backendName(store, fmt.Sprintf("fallback %d", i)), nil
+	}
+	if len(errs) > 0 {
+		return "", backendErrors{op: "has", errs: errs}
+	}
+	return "", fmt.Errorf("secret %q: %w", key, domain.ErrSecretNotFound)
+}
+
+// lookup returns the value of key from the first backend holding it, and
+// that backend's index.
+func (s *Store) lookup(ctx context.Context, key string) (string, int, error) {
+	errs := make([]error, 0, len(s.stores))
+	for i, store := range s.stores {
+		value, err := store.Get(ctx, key)
+		if err == nil {
+			return value, i, nil
+		}
+		if shouldSkipFallback(err) {
+			return "", 0, err
+		}
+		errs = append(errs, err)
+	}
+
+	return "", 0, backendErrors{op: "get", errs: errs}
+}
+
+// backendErrors names each backend's failure in chain order.
+type backendErrors struct {
+	op   string
+	errs []error
+}
 
-	return fmt.Errorf("primary backend delete failed: %w; fallback backend delete failed: %w", err, fallbackErr)
+func (e backendErrors) Error() string {
+	parts := make([]string, 0, len(e.errs))
+	for i, err := range e.errs {
+		parts = append(parts, fmt.Sprintf("backend %d %s failed: %v", i+1, e.op, err))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap hides the errors of backends that lack the key when another backend
+// failed for a different reason, so errors.Is(err, domain.ErrSecretNotFound)
+// only holds when no backend has the key rather than when one of them could
+// not be asked.
+func (e backendErrors) Unwrap() []error {
+	failures := make([]error, 0, len(e.errs))
+	for _, err := range e.errs {
+		if !lacksKey(err) {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) == 0 {
+		return e.errs
+	}
+	return failures
+}
+
+// lacksKey reports whether err means the backend cannot hold the key: it has
+// no such entry, or pass is not installed or set up at all.
+func lacksKey(err error) bool {
+	return errors.Is(err, domain.ErrSecretNotFound) ||
+		errors.Is(err, os.ErrNotExist) ||
+		errors.Is(err, passstore.ErrUnavailable) ||
+		errors.Is(err, passstore.ErrNotInitialized)
+}
+
+func backendName(store ports.SecretStore, fallback string) string {
+	if named, ok := store.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fallback
+}
+
+func (s *Store) warnPlaintextFallback() {
+	if s.warn == nil {
+		return
+	}
+	s.warnOnce.Do(func() {
+		s.warn("warning: pass is installed but its store is not initialized (run `pass init <gpg-id>`); secrets are being stored in plaintext files")
+	})
 }
 
 func shouldSkipFallback(err error) bool {
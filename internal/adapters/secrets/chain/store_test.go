@@ -3,8 +3,12 @@ package chain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
+	filestore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/file"
+	passstore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/pass"
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	portmocks "github.com/bnema/openai-accounts-cli/internal/ports/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,10 +56,77 @@ func TestStoreGetReturnsCombinedErrorWhenBothBackendsFail(t *testing.T) {
 
 	_, err := store.Get(context.Background(), "codex/oa/accounts/acc-1/api_key")
 	require.Error(t, err)
-	assert.ErrorContains(t, err, "primary backend")
-	assert.ErrorContains(t, err, "fallback backend")
-	assert.ErrorContains(t, err, "pass failed")
-	assert.ErrorContains(t, err, "file failed")
+	assert.EqualError(t, err, "backend 1 get failed: pass failed; backend 2 get failed: file failed")
+}
+
+func TestStoreGetReportsNotFoundOnlyWhenEveryBackendLacksTheKey(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store := NewStore(primary, fallback)
+
+	notFound := fmt.Errorf("file secret: %w", domain.ErrSecretNotFound)
+	primary.EXPECT().Get(mock.Anything, "openai://1/api_key").Return("", notFound).Once()
+	fallback.EXPECT().Get(mock.Anything, "openai://1/api_key").Return("", notFound).Once()
+
+	_, err := store.Get(context.Background(), "openai://1/api_key")
+	require.ErrorIs(t, err, domain.ErrSecretNotFound)
+
+	gpgFailure := errors.New("gpg: decryption failed")
+	primary.EXPECT().Get(mock.Anything, "openai://2/api_key").Return("", gpgFailure).Once()
+	fallback.EXPECT().Get(mock.Anything, "openai://2/api_key").Return("", notFound).Once()
+
+	_, err = store.Get(context.Background(), "openai://2/api_key")
+	require.ErrorIs(t, err, gpgFailure)
+	assert.NotErrorIs(t, err, domain.ErrSecretNotFound)
+	assert.Contains(t, err.Error(), "backend 2 get failed: file secret")
+}
+
+func TestStoreTriesEveryBackendInOrder(t *testing.T) {
+	t.Parallel()
+
+	keychain := portmocks.NewMockSecretStore(t)
+	pass := portmocks.NewMockSecretStore(t)
+	file := filestore.NewStore(t.TempDir())
+	store := NewStore(keychain, pass, file)
+
+	keychain.EXPECT().Put(mock.Anything, "k", "secret").Return(errors.New("keychain locked")).Once()
+	pass.EXPECT().Put(mock.Anything, "k", "secret").Return(passstore.ErrUnavailable).Once()
+	require.NoError(t, store.Put(context.Background(), "k", "secret"))
+
+	keychain.EXPECT().Get(mock.Anything, "k").Return("", errors.New("keychain locked")).Once()
+	pass.EXPECT().Get(mock.Anything, "k").Return("", passstore.ErrUnavailable).Once()
+	value, err := store.Get(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+	keychain.EXPECT().Has(mock.Anything, "k").Return(false, errors.New("keychain locked")).Once()
+	pass.EXPECT().Has(mock.Anything, "k").Return(false, nil).Once()
+	backend, err := store.WhichBackend(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, "file", backend)
+
+	keychain.EXPECT().Delete(mock.Anything, "missing").Return(errors.New("keychain locked")).Once()
+	pass.EXPECT().Delete(mock.Anything, "missing").Return(context.DeadlineExceeded).Once()
+	require.ErrorIs(t, store.Delete(context.Background(), "missing"), context.DeadlineExceeded)
+
+	keychain.EXPECT().Get(mock.Anything, "missing").Return("", errors.New("keychain locked")).Once()
+	pass.EXPECT().Get(mock.Anything, "missing").Return("", passstore.ErrUnavailable).Once()
+	_, err = store.Get(context.Background(), "missing")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrSecretNotFound, "a locked keychain might still hold the key")
+	assert.ErrorContains(t, err, "backend 1 get failed: keychain locked; backend 2 get failed: ")
+	assert.ErrorContains(t, err, "; backend 3 get failed: ")
+}
+
+func TestNewStoreCheckedRejectsEmptyOrNilBackends(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStoreChecked()
+	require.ErrorIs(t, err, errNoStores)
+
+	_, err = NewStoreChecked(portmocks.NewMockSecretStore(t), nil)
+	require.EqualError(t, err, "secret store 2 is nil")
 }
 
 func TestStorePutFallsBackWhenPrimaryFails(t *testing.T) {
@@ -124,3 +195,216 @@ func TestStoreGetDoesNotFallbackOnCanceledContextError(t *testing.T) {
 	_, err := store.Get(context.Background(), "codex/oa/accounts/acc-1/api_key")
 	require.ErrorIs(t, err, context.Canceled)
 }
+
+func TestStoreWhichBackendReportsServingBackend(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := filestore.NewStore(t.TempDir())
+	store := NewStore(primary, fallback)
+	require.NoError(t, fallback.Put(context.Background(), "openai://1/oauth_tokens", "tokens"))
+
+	primary.EXPECT().Has(mock.Anything, "openai://1/oauth_tokens").Return(false, errors.New("pass failed")).Once()
+
+	backend, err := store.WhichBackend(context.Background(), "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, "file", backend)
+
+	primary.EXPECT().Has(mock.Anything, "openai://2/oauth_tokens").Return(true, nil).Once()
+
+	backend, err = store.WhichBackend(context.Background(), "openai://2/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", backend)
+
+	primary.EXPECT().Has(mock.Anything, "openai://3/oauth_tokens").Return(false, nil).Once()
+
+	_, err = store.WhichBackend(context.Background(), "openai://3/oauth_tokens")
+	require.ErrorIs(t, err, domain.ErrSecretNotFound)
+}
+
+func TestStorePutWarnsOnceWhenPassStoreIsNotInitialized(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	var warnings []string
+	store := NewStore(primary, fallback).WithWarnings(func(message string) {
+		warnings = append(warnings, message)
+	})
+
+	uninitialized := fmt.Errorf("pass put %q: %w: exit status 1: Error: password store is empty. Try \"pass init\".", "k", passstore.ErrNotInitialized)
+	primary.EXPECT().Put(mock.Anything, mock.Anything, "secret").Return(uninitialized).Twice()
+	fallback.EXPECT().Put(mock.Anything, mock.Anything, "secret").Return(nil).Twice()
+
+	require.NoError(t, store.Put(context.Background(), "openai://1/oauth_tokens", "secret"))
+	require.NoError(t, store.Put(context.Background(), "openai://2/oauth_tokens", "secret"))
+
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "pass init")
+	assert.Contains(t, warnings[0], "plaintext")
+}
+
+func TestStorePutDoesNotWarnForOtherPassFailures(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	warned := false
+	store := NewStore(primary, fallback).WithWarnings(func(string) { warned = true })
+
+	primary.EXPECT().Put(mock.Anything, "k", "secret").Return(passstore.ErrUnavailable).Once()
+	fallback.EXPECT().Put(mock.Anything, "k", "secret").Return(nil).Once()
+
+	require.NoError(t, store.Put(context.Background(), "k", "secret"))
+	assert.False(t, warned)
+}
+
+func TestStoreRoutesRefKeysToRefStore(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	refs := portmocks.NewMockSecretStore(t)
+	store := NewStore(primary, fallback).WithRefStore("cmd://", refs)
+
+	refs.EXPECT().Get(mock.Anything, "cmd://pass show openai/key").Return("from-cmd", nil).Once()
+	refs.EXPECT().Put(mock.Anything, "cmd://pass show openai/key", "").Return(nil).Once()
+	refs.EXPECT().Delete(mock.Anything, "cmd://pass show openai/key").Return(nil).Once()
+
+	value, err := store.Get(context.Background(), "cmd://pass show openai/key")
+	require.NoError(t, err)
+	assert.Equal(t, "from-cmd", value)
+	require.NoError(t, store.Put(context.Background(), "cmd://pass show openai/key", ""))
+	require.NoError(t, store.Delete(context.Background(), "cmd://pass show openai/key"))
+
+	backend, err := store.WhichBackend(context.Background(), "cmd://pass show openai/key")
+	require.NoError(t, err)
+	assert.Equal(t, "ref", backend)
+}
+
+func TestWriteThroughStorePutReachesEveryBackend(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(nil).Once()
+	fallback.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(nil).Once()
+
+	require.NoError(t, store.Put(context.Background(), "codex/oa/accounts/acc-1/api_key", "secret"))
+}
+
+func TestWriteThroughStorePutSucceedsWhenOneBackendFails(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(nil).Once()
+	fallback.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(errors.New("file failed")).Once()
+	fallback.EXPECT().Delete(mock.Anything, "codex/oa/accounts/acc-1/api_key").Return(errors.New("file failed")).Once()
+
+	require.NoError(t, store.Put(context.Background(), "codex/oa/accounts/acc-1/api_key", "secret"))
+}
+
+func TestWriteThroughStorePutDeletesStaleCopyFromBackendThatMissedIt(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := filestore.NewStore(t.TempDir())
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "openai://1/oauth_tokens", "rotated").Return(errors.New("gpg: no public key")).Once()
+	primary.EXPECT().Delete(mock.Anything, "openai://1/oauth_tokens").Return(nil).Once()
+	require.NoError(t, store.Put(context.Background(), "openai://1/oauth_tokens", "rotated"))
+
+	value, err := fallback.Get(context.Background(), "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+}
+
+func TestWriteThroughStorePutFailsWhenStaleCopyWouldShadowFreshOne(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "k", "rotated").Return(errors.New("pass failed")).Once()
+	primary.EXPECT().Delete(mock.Anything, "k").Return(errors.New("pass rm failed")).Once()
+	fallback.EXPECT().Put(mock.Anything, "k", "rotated").Return(nil).Once()
+
+	err = store.Put(context.Background(), "k", "rotated")
+	assert.EqualError(t, err, "backend 1 put failed: pass failed (stale copy not removed: pass rm failed)")
+}
+
+func TestWriteThroughStorePutReturnsCombinedErrorWhenAllBackendsFail(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(errors.New("pass failed")).Once()
+	fallback.EXPECT().Put(mock.Anything, "codex/oa/accounts/acc-1/api_key", "secret").Return(errors.New("file failed")).Once()
+
+	err = store.Put(context.Background(), "codex/oa/accounts/acc-1/api_key", "secret")
+	assert.EqualError(t, err, "backend 1 put failed: pass failed; backend 2 put failed: file failed")
+}
+
+func TestWriteThroughStoreDeleteReachesEveryBackend(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Delete(mock.Anything, "codex/oa/accounts/acc-1/api_key").Return(errors.New("pass failed")).Once()
+	fallback.EXPECT().Delete(mock.Anything, "codex/oa/accounts/acc-1/api_key").Return(nil).Once()
+
+	require.NoError(t, store.Delete(context.Background(), "codex/oa/accounts/acc-1/api_key"))
+}
+
+func TestWriteThroughStoreGetUsesFirstBackendThatHasTheKey(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := filestore.NewStore(t.TempDir())
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "openai://1/oauth_tokens", "tokens").Return(nil).Once()
+	require.NoError(t, store.Put(context.Background(), "openai://1/oauth_tokens", "tokens"))
+
+	primary.EXPECT().Get(mock.Anything, "openai://1/oauth_tokens").Return("tokens", nil).Once()
+	value, err := store.Get(context.Background(), "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, "tokens", value)
+
+	// Losing the primary (e.g. its GPG key) still leaves the file copy.
+	primary.EXPECT().Get(mock.Anything, "openai://1/oauth_tokens").Return("", errors.New("gpg: decryption failed")).Once()
+	value, err = store.Get(context.Background(), "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, "tokens", value)
+}
+
+func TestWriteThroughStoreStopsOnCanceledContextError(t *testing.T) {
+	t.Parallel()
+
+	primary := portmocks.NewMockSecretStore(t)
+	fallback := portmocks.NewMockSecretStore(t)
+	store, err := NewWriteThroughStore(primary, fallback)
+	require.NoError(t, err)
+
+	primary.EXPECT().Put(mock.Anything, "k", "secret").Return(context.Canceled).Once()
+
+	require.ErrorIs(t, store.Put(context.Background(), "k", "secret"), context.Canceled)
+}
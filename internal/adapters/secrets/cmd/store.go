@@ -0,0 +1,198 @@
+// Package cmd resolves secret refs of the form `cmd://<program> <args...>` by
+// running the program and reading the secret from its stdout, so accounts.toml
+// can point at a password manager (`cmd://op read op://vault/item/token`)
+// instead of holding a copy. The program runs without a shell.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+)
+
+// RefPrefix marks secret refs served by this store.
+const RefPrefix = domain.CommandSecretRefPrefix
+
+const defaultTimeout = 30 * time.Second
+
+// ErrReadOnly is returned when a value is written to a cmd:// ref; the secret
+// lives wherever the command reads it from.
+var ErrReadOnly = errors.New("cmd secret refs are read-only")
+
+// shellSyntax lists characters that only mean something to a shell. Refs
+// containing them outside quotes are rejected instead of being passed on
+// literally, which would silently run something other than what was written.
+const shellSyntax = "|&;<>()$`\\"
+
+type runFunc func(ctx context.Context, name string, args ...string) (stdout string, stderr string, err error)
+
+type Store struct {
+	run     runFunc
+	timeout time.Duration
+}
+
+var _ ports.SecretStore = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{run: runCommand, timeout: defaultTimeout}
+}
+
+func (s *Store) Name() string {
+	return "cmd"
+}
+
+// IsRef reports whether key is a cmd:// secret ref.
+func IsRef(key string) bool {
+	return domain.IsCommandSecretRef(key)
+}
+
+// ParseRef splits a cmd:// ref into the program and its arguments. Single
+// and double quotes group words; shell syntax and control characters are
+// rejected.
+func ParseRef(key string) ([]string, error) {
+	trimmed := strings.TrimSpace(key)
+	if !strings.HasPrefix(trimmed, RefPrefix) {
+		return nil, fmt.Errorf("secret ref %q does not start with %s", key, RefPrefix)
+	}
+	raw := strings.TrimPrefix(trimmed, RefPrefix)
+
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+	)
+	for _, r := range raw {
+		if unicode.IsControl(r) {
+			return nil, fmt.Errorf("cmd secret ref contains a control character")
+		}
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune(shellSyntax, r):
+			return nil, fmt.Errorf("cmd secret ref uses shell syntax %q; the command runs without a shell", r)
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("cmd secret ref has an unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 || args[0] == "" {
+		return nil, fmt.Errorf("cmd secret ref %q names no command", key)
+	}
+
+	return args, nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	args, err := ParseRef(key)
+	if err != nil {
+		return "", err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	// stdout is the secret: errors carry the program and its stderr only.
+	stdout, stderr, err := s.run(ctx, args[0], args[1:]...)
+	if err != nil {
+		if stderr == "" {
+			return "", fmt.Errorf("cmd secret %q: %w", args[0], err)
+		}
+		return "", fmt.Errorf("cmd secret %q: %w: %s", args[0], err, stderr)
+	}
+
+	stdout = strings.TrimSuffix(stdout, "\n")
+	stdout = strings.TrimSuffix(stdout, "\r")
+	if strings.TrimSpace(stdout) == "" {
+		return "", fmt.Errorf("cmd secret %q: command printed no secret", args[0])
+	}
+
+	return stdout, nil
+}
+
+// Put accepts an empty value so a ref can be recorded without a secret to
+// store; any real value is refused.
+func (s *Store) Put(ctx context.Context, key string, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := ParseRef(key); err != nil {
+		return err
+	}
+	if value != "" {
+		return fmt.Errorf("put %q: %w", key, ErrReadOnly)
+	}
+	return nil
+}
+
+// Delete is a no-op: oa never owns the secret behind a cmd:// ref.
+func (s *Store) Delete(ctx context.Context, _ string) error {
+	return ctx.Err()
+}
+
+// Has only validates the ref: whether the command can produce the secret is
+// only known by running it, so a well-formed ref counts as present.
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if _, err := ParseRef(key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) (string, string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", "", fmt.Errorf("locate command: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		// Report the deadline or cancellation, not the kill it caused.
+		err = ctx.Err()
+	}
+	return stdout.String(), strings.TrimSpace(stderr.String()), err
+}
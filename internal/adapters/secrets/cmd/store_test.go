@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreGetRunsCommandWithoutShellAndTrimsNewline(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(ctx context.Context, name string, args ...string) (string, string, error) {
+			assert.Equal(t, "op", name)
+			assert.Equal(t, []string{"read", "op://Private/OpenAI Key/credential"}, args)
+			return "sk-from-op\n", "", nil
+		},
+	}
+
+	value, err := store.Get(context.Background(), `cmd://op read "op://Private/OpenAI Key/credential"`)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-from-op", value)
+}
+
+func TestStoreGetReportsStderrButNotStdout(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(ctx context.Context, name string, args ...string) (string, string, error) {
+			return "partial-secret", "item not found", errors.New("exit status 1")
+		},
+	}
+
+	_, err := store.Get(context.Background(), "cmd://pass show openai/key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "item not found")
+	assert.NotContains(t, err.Error(), "partial-secret")
+}
+
+func TestStoreGetRejectsEmptyOutput(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(ctx context.Context, name string, args ...string) (string, string, error) {
+			return "\n", "", nil
+		},
+	}
+
+	_, err := store.Get(context.Background(), "cmd://pass show openai/key")
+	require.ErrorContains(t, err, "printed no secret")
+}
+
+func TestParseRefRejectsUnsafeRefs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"cmd://":                           "names no command",
+		"cmd://   ":                        "names no command",
+		"cmd://pass show key | tee /tmp/x": "shell syntax",
+		"cmd://pass show key; rm -rf ~":    "shell syntax",
+		"cmd://pass show $(whoami)":        "shell syntax",
+		"cmd://pass show `id`":             "shell syntax",
+		"cmd://pass show key\nrm -rf ~":    "control character",
+		`cmd://op read "op://vault/item`:   "unterminated",
+		"openai://acc-1/api_key":           "does not start with cmd://",
+	}
+	for ref, want := range tests {
+		_, err := ParseRef(ref)
+		assert.ErrorContains(t, err, want, ref)
+	}
+
+	args, err := ParseRef(`cmd://op read 'op://vault/a|b'`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"op", "read", "op://vault/a|b"}, args)
+}
+
+func TestStoreRefusesValuesAndNeverDeletes(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(ctx context.Context, name string, args ...string) (string, string, error) {
+			t.Fatalf("unexpected command %s", name)
+			return "", "", nil
+		},
+	}
+
+	require.NoError(t, store.Put(context.Background(), "cmd://pass show openai/key", ""))
+	require.ErrorIs(t, store.Put(context.Background(), "cmd://pass show openai/key", "sk-new"), ErrReadOnly)
+	require.NoError(t, store.Delete(context.Background(), "cmd://pass show openai/key"))
+	assert.True(t, IsRef(" cmd://pass show openai/key"))
+	assert.False(t, IsRef("openai://acc-1/api_key"))
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
 )
 
@@ -28,6 +29,23 @@ func NewStore(root string) *Store {
 	return &Store{root: filepath.Clean(root)}
 }
 
+func (s *Store) Name() string {
+	return "file"
+}
+
+// Hold runs fn while writes to the store are blocked, so fn sees a single
+// version of every secret.
+func (s *Store) Hold(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn()
+}
+
 func (s *Store) Put(ctx context.Context, key string, value string) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -68,7 +86,7 @@ func (s *Store) Get(ctx context.Context, key string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("file secret %q not found: %w", key, err)
+			return "", fmt.Errorf("file secret %q not found: %w: %w", key, domain.ErrSecretNotFound, err)
 		}
 		return "", fmt.Errorf("read file secret %q: %w", key, err)
 	}
@@ -76,6 +94,28 @@ func (s *Store) Get(ctx context.Context, key string) (string, error) {
 	return string(data), nil
 }
 
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	path, err := s.pathForKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("stat file secret %q: %w", key, err)
+	}
+	return true, nil
+}
+
 func (s *Store) Delete(ctx context.Context, key string) error {
 	if err := ctx.Err(); err != nil {
 		return err
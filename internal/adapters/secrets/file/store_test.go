@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -68,3 +69,22 @@ func TestStoreDeleteIsIdempotentWhenSecretMissing(t *testing.T) {
 	err = store.Delete(context.Background(), key)
 	require.NoError(t, err)
 }
+
+func TestStoreHasChecksPresenceAndGetReportsNotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewStore(t.TempDir())
+	key := "openai://1/oauth_tokens"
+
+	found, err := store.Has(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, err = store.Get(ctx, key)
+	require.ErrorIs(t, err, domain.ErrSecretNotFound)
+
+	require.NoError(t, store.Put(ctx, key, "tokens"))
+	found, err = store.Has(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
@@ -0,0 +1,87 @@
+// Package memory is an in-process secret store for embedding oa as a library
+// or wiring a filesystem-free stack; nothing is persisted.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
+)
+
+// ErrNotFound is returned by Get for keys that were never stored. It wraps
+// domain.ErrSecretNotFound.
+var ErrNotFound = fmt.Errorf("memory %w", domain.ErrSecretNotFound)
+
+type Store struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+var _ ports.SecretStore = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{secrets: map[string]string{}}
+}
+
+func (s *Store) Name() string {
+	return "memory"
+}
+
+func (s *Store) Put(ctx context.Context, key string, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(key) == "" {
+		return errors.New("secret key is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets[key] = value
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", fmt.Errorf("memory secret %q: %w", key, ErrNotFound)
+	}
+	return value, nil
+}
+
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.secrets[key]
+	return ok, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, key)
+	return nil
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreConcurrentPutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("openai://%d/api_key", i)
+			assert.NoError(t, store.Put(ctx, key, fmt.Sprint("sk-", i)))
+			value, err := store.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprint("sk-", i), value)
+			if i%2 == 0 {
+				assert.NoError(t, store.Delete(ctx, key))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, err := store.Get(ctx, "openai://0/api_key")
+	require.ErrorIs(t, err, ErrNotFound)
+	value, err := store.Get(ctx, "openai://1/api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", value)
+	require.ErrorContains(t, store.Put(ctx, " ", "value"), "secret key is empty")
+}
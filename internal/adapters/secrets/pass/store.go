@@ -5,18 +5,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
 )
 
 var ErrUnavailable = errors.New("pass command unavailable")
 
+// ErrNotInitialized reports that pass is installed but no password store has
+// been set up with `pass init`.
+var ErrNotInitialized = errors.New("pass store not initialized")
+
 type runFunc func(ctx context.Context, input string, args ...string) (stdout string, stderr string, err error)
 
 type Store struct {
 	run runFunc
+	dir string
 }
 
 var _ ports.SecretStore = (*Store)(nil)
@@ -25,6 +33,17 @@ func NewStore() *Store {
 	return &Store{run: runPassCommand}
 }
 
+// WithDir sets the password store directory (what pass reads from
+// PASSWORD_STORE_DIR), letting Has look for entries without running pass.
+func (s *Store) WithDir(dir string) *Store {
+	s.dir = dir
+	return s
+}
+
+func (s *Store) Name() string {
+	return "pass"
+}
+
 func (s *Store) Put(ctx context.Context, key string, value string) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -45,6 +64,9 @@ func (s *Store) Get(ctx context.Context, key string) (string, error) {
 
 	stdout, stderr, err := s.run(ctx, "", "show", key)
 	if err != nil {
+		if isNotInStore(stderr) {
+			return "", fmt.Errorf("pass get %q: %w", key, domain.ErrSecretNotFound)
+		}
 		return "", formatError("get", key, err, stderr)
 	}
 
@@ -60,13 +82,37 @@ func (s *Store) Delete(ctx context.Context, key string) error {
 	}
 
 	_, stderr, err := s.run(ctx, "", "rm", "-f", key)
-	if err != nil {
+	if err != nil && !isNotInStore(stderr) {
 		return formatError("delete", key, err, stderr)
 	}
 
 	return nil
 }
 
+// Has looks for the entry's .gpg file, so nothing is decrypted. Without a
+// store directory it falls back to pass show.
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	if s.dir == "" {
+		_, err := s.Get(ctx, key)
+		if errors.Is(err, domain.ErrSecretNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(s.dir, filepath.Clean(key)+".gpg")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("pass has %q: %w", key, err)
+	}
+	return true, nil
+}
+
 func runPassCommand(ctx context.Context, input string, args ...string) (string, string, error) {
 	path, err := exec.LookPath("pass")
 	if err != nil {
@@ -87,13 +133,33 @@ func runPassCommand(ctx context.Context, input string, args ...string) (string,
 	cmd.Stderr = &stderr
 
 	err = cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		// Report the deadline or cancellation, not the kill it caused.
+		err = ctx.Err()
+	}
 	return stdout.String(), strings.TrimSpace(stderr.String()), err
 }
 
 func formatError(op string, key string, err error, stderr string) error {
+	if isNotInitialized(stderr) {
+		return fmt.Errorf("pass %s %q: %w: %w: %s", op, key, ErrNotInitialized, err, stderr)
+	}
 	if stderr == "" {
 		return fmt.Errorf("pass %s %q: %w", op, key, err)
 	}
 
 	return fmt.Errorf("pass %s %q: %w: %s", op, key, err, stderr)
 }
+
+// isNotInStore matches pass's "<key> is not in the password store." error.
+func isNotInStore(stderr string) bool {
+	return strings.Contains(strings.ToLower(stderr), "is not in the password store")
+}
+
+func isNotInitialized(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	if strings.Contains(lower, "password store is empty") {
+		return true
+	}
+	return strings.Contains(lower, "you must run") && strings.Contains(lower, "pass init")
+}
@@ -3,8 +3,11 @@ package pass
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,3 +77,60 @@ func TestStoreGetReturnsClearError(t *testing.T) {
 	assert.ErrorContains(t, err, "codex/oa/accounts/acc-1/api_key")
 	assert.ErrorContains(t, err, "entry not found")
 }
+
+func TestStoreClassifiesUninitializedStore(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(ctx context.Context, input string, args ...string) (string, string, error) {
+			return "", "Error: You must run:\n    pass init your-gpg-id\nbefore you may use the password store.", errors.New("exit status 1")
+		},
+	}
+
+	err := store.Put(context.Background(), "codex/oa/accounts/acc-1/api_key", "top-secret")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotInitialized)
+
+	store.run = func(ctx context.Context, input string, args ...string) (string, string, error) {
+		return "", `Error: password store is empty. Try "pass init".`, errors.New("exit status 1")
+	}
+
+	_, err = store.Get(context.Background(), "codex/oa/accounts/acc-1/api_key")
+	assert.ErrorIs(t, err, ErrNotInitialized)
+}
+
+func TestStoreHasLooksForGPGFileWithoutRunningPass(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := (&Store{
+		run: func(context.Context, string, ...string) (string, string, error) {
+			t.Fatal("Has must not run pass")
+			return "", "", nil
+		},
+	}).WithDir(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "openai:", "1"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "openai:", "1", "oauth_tokens.gpg"), []byte("x"), 0o600))
+
+	found, err := store.Has(context.Background(), "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = store.Has(context.Background(), "openai://2/oauth_tokens")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestStoreTreatsEntryNotInStoreAsNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := &Store{
+		run: func(context.Context, string, ...string) (string, string, error) {
+			return "", "Error: openai://1/oauth_tokens is not in the password store.", errors.New("exit status 1")
+		},
+	}
+
+	_, err := store.Get(context.Background(), "openai://1/oauth_tokens")
+	require.ErrorIs(t, err, domain.ErrSecretNotFound)
+	require.NoError(t, store.Delete(context.Background(), "openai://1/oauth_tokens"))
+}
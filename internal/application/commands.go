@@ -1,6 +1,7 @@
 package application
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/bnema/openai-accounts-cli/internal/domain"
@@ -45,3 +46,49 @@ type SetLimitCommand struct {
 	ResetsAt   time.Time
 	CapturedAt time.Time
 }
+
+type LimitUpdate struct {
+	Window     LimitWindowKind
+	Percent    float64
+	ResetsAt   time.Time
+	CapturedAt time.Time
+}
+
+type FeatureLimitUpdate struct {
+	Feature string
+	Limits  []LimitUpdate
+}
+
+// UsageUpdate describes one fetched usage snapshot. FeatureLimits replaces
+// the stored per-feature limits, the other fields only apply when set.
+type UsageUpdate struct {
+	AccountID     domain.AccountID
+	Name          string
+	PlanType      string
+	Limits        []LimitUpdate
+	FeatureLimits []FeatureLimitUpdate
+	Subscription  *domain.Subscription
+}
+
+// Validate reports the first limit in u that ApplyUsageUpdates would refuse.
+func (u UsageUpdate) Validate() error {
+	for _, limit := range u.Limits {
+		if err := validateLimit(limit); err != nil {
+			return err
+		}
+	}
+	for _, feature := range u.FeatureLimits {
+		for _, limit := range feature.Limits {
+			if err := validateLimit(limit); err != nil {
+				return fmt.Errorf("feature %s: %w", feature.Feature, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SkippedUsageUpdate is an update ApplyUsageUpdates left out of its write.
+type SkippedUsageUpdate struct {
+	AccountID domain.AccountID
+	Err       error
+}
@@ -3,14 +3,28 @@ package application
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
 )
 
-const DefaultOpenAIPoolID domain.PoolID = "default-openai"
+const (
+	DefaultOpenAIPoolID    domain.PoolID = "default-openai"
+	DefaultAnthropicPoolID domain.PoolID = "default-anthropic"
+
+	// UnpooledGroupID labels the accounts in no pool when usage is grouped by
+	// pool, so no pool may take it.
+	UnpooledGroupID domain.PoolID = "unpooled"
+)
+
+// DefaultPoolID returns the ID of the auto-synced default pool of a provider.
+func DefaultPoolID(provider domain.Provider) domain.PoolID {
+	return domain.PoolID("default-" + string(provider))
+}
 
 type PoolService struct {
 	accounts ports.AccountRepository
@@ -26,35 +40,100 @@ func NewPoolService(accounts ports.AccountRepository, pools ports.PoolRepository
 	return &PoolService{accounts: accounts, pools: pools, clock: clock}
 }
 
+// PoolActivationPlan describes the pool that activation would save and how
+// its members differ from the stored pool.
+type PoolActivationPlan struct {
+	Pool    domain.Pool
+	Added   []domain.AccountID
+	Removed []domain.AccountID
+}
+
 func (s *PoolService) ActivateDefaultOpenAIPool(ctx context.Context) (domain.Pool, error) {
-	accounts, err := s.accounts.List(ctx)
+	return s.ActivateDefaultPool(ctx, domain.ProviderOpenAI)
+}
+
+// ActivateDefaultPool creates or re-syncs the default pool of provider and
+// marks it active.
+func (s *PoolService) ActivateDefaultPool(ctx context.Context, provider domain.Provider) (domain.Pool, error) {
+	plan, err := s.PlanDefaultPoolActivation(ctx, provider)
 	if err != nil {
-		return domain.Pool{}, fmt.Errorf("list accounts: %w", err)
+		return domain.Pool{}, err
 	}
 
-	members := openAIMembers(accounts)
+	if err := s.pools.Save(ctx, plan.Pool); err != nil {
+		return domain.Pool{}, fmt.Errorf("save pool: %w", err)
+	}
+
+	return plan.Pool, nil
+}
 
-	pool, err := s.pools.GetByID(ctx, DefaultOpenAIPoolID)
+// PlanDefaultOpenAIPoolActivation computes the activated default pool and its
+// member diff without saving anything.
+func (s *PoolService) PlanDefaultOpenAIPoolActivation(ctx context.Context) (PoolActivationPlan, error) {
+	return s.PlanDefaultPoolActivation(ctx, domain.ProviderOpenAI)
+}
+
+// PlanDefaultPoolActivation is PlanDefaultOpenAIPoolActivation for any
+// supported provider.
+func (s *PoolService) PlanDefaultPoolActivation(ctx context.Context, provider domain.Provider) (PoolActivationPlan, error) {
+	if !provider.Supported() {
+		return PoolActivationPlan{}, fmt.Errorf("unsupported provider %q", provider)
+	}
+
+	accounts, err := s.accounts.List(ctx)
+	if err != nil {
+		return PoolActivationPlan{}, fmt.Errorf("list accounts: %w", err)
+	}
+
+	poolID := DefaultPoolID(provider)
+	pool, err := s.pools.GetByID(ctx, poolID)
 	if err != nil {
 		if err != domain.ErrPoolNotFound {
-			return domain.Pool{}, fmt.Errorf("load default pool: %w", err)
+			return PoolActivationPlan{}, fmt.Errorf("load default pool: %w", err)
 		}
 		pool = domain.Pool{
-			ID:              DefaultOpenAIPoolID,
+			ID:              poolID,
 			Name:            "default",
-			Provider:        domain.ProviderOpenAI,
+			Provider:        provider,
 			Strategy:        domain.PoolStrategyLeastWeeklyUsed,
 			AutoSyncMembers: true,
 		}
 	}
+	previous := append([]domain.AccountID(nil), pool.Members...)
 
 	if pool.AutoSyncMembers {
-		pool.Members = members
+		pool.Members = poolMembers(pool, accounts)
 	}
 	pool.Active = true
 	pool.UpdatedAt = s.clock.Now()
 	pool.NormalizeMembers()
 
+	if err := pool.Validate(); err != nil {
+		return PoolActivationPlan{}, err
+	}
+
+	return PoolActivationPlan{
+		Pool:    pool,
+		Added:   memberDifference(pool.Members, previous),
+		Removed: memberDifference(previous, pool.Members),
+	}, nil
+}
+
+func (s *PoolService) ActivatePool(ctx context.Context, poolID domain.PoolID) (domain.Pool, error) {
+	switch poolID {
+	case DefaultOpenAIPoolID:
+		return s.ActivateDefaultPool(ctx, domain.ProviderOpenAI)
+	case DefaultAnthropicPoolID:
+		return s.ActivateDefaultPool(ctx, domain.ProviderAnthropic)
+	}
+
+	pool, err := s.GetPool(ctx, poolID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	pool.Active = true
+	pool.UpdatedAt = s.clock.Now()
 	if err := pool.Validate(); err != nil {
 		return domain.Pool{}, err
 	}
@@ -66,13 +145,42 @@ func (s *PoolService) ActivateDefaultOpenAIPool(ctx context.Context) (domain.Poo
 	return pool, nil
 }
 
-func (s *PoolService) DeactivatePool(ctx context.Context, poolID domain.PoolID) (domain.Pool, error) {
-	pool, err := s.pools.GetByID(ctx, poolID)
-	if err != nil {
+// CreatePool saves a new pool with a fixed member list. The default pool IDs
+// are reserved for the auto-synced default pools, and every member must be an
+// existing account.
+func (s *PoolService) CreatePool(ctx context.Context, pool domain.Pool) (domain.Pool, error) {
+	if pool.ID == DefaultOpenAIPoolID || pool.ID == DefaultAnthropicPoolID {
+		return domain.Pool{}, fmt.Errorf("pool %s is reserved for a default pool (use oa pool activate)", pool.ID)
+	}
+	if pool.ID == UnpooledGroupID {
+		return domain.Pool{}, fmt.Errorf("pool %s is reserved for accounts in no pool", pool.ID)
+	}
+	if _, err := s.pools.GetByID(ctx, pool.ID); err == nil {
+		return domain.Pool{}, fmt.Errorf("pool %s already exists", pool.ID)
+	} else if err != domain.ErrPoolNotFound {
+		return domain.Pool{}, fmt.Errorf("load pool: %w", err)
+	}
+
+	pool.NormalizeMembers()
+	if err := pool.Validate(); err != nil {
 		return domain.Pool{}, err
 	}
+	if !pool.Strategy.Supported() {
+		return domain.Pool{}, fmt.Errorf("unsupported strategy %q (valid: %s)", pool.Strategy, domain.PoolStrategyLeastWeeklyUsed)
+	}
+	for _, member := range pool.Members {
+		exists, err := s.accounts.Exists(ctx, member)
+		if err != nil {
+			return domain.Pool{}, fmt.Errorf("check account exists: %w", err)
+		}
+		if !exists {
+			return domain.Pool{}, fmt.Errorf("pool member %s: %w", member, domain.ErrAccountNotFound)
+		}
+	}
 
-	pool.Active = false
+	pool.Active = true
+	pool.AutoSyncMembers = false
+	pool.MemberTag = ""
 	pool.UpdatedAt = s.clock.Now()
 	if err := s.pools.Save(ctx, pool); err != nil {
 		return domain.Pool{}, fmt.Errorf("save pool: %w", err)
@@ -81,52 +189,173 @@ func (s *PoolService) DeactivatePool(ctx context.Context, poolID domain.PoolID)
 	return pool, nil
 }
 
-func (s *PoolService) PickAccount(ctx context.Context, poolID domain.PoolID) (domain.AccountID, []domain.AccountID, error) {
-	pool, err := s.pools.GetByID(ctx, poolID)
-	if err != nil {
-		return "", nil, err
+func (s *PoolService) CreatePoolFromTag(ctx context.Context, poolID domain.PoolID, tag string) (domain.Pool, error) {
+	tag = domain.NormalizeTag(tag)
+	if tag == "" {
+		return domain.Pool{}, fmt.Errorf("tag is required")
 	}
-	if !pool.Active {
-		return "", nil, domain.ErrPoolInactive
+	if poolID == UnpooledGroupID {
+		return domain.Pool{}, fmt.Errorf("pool %s is reserved for accounts in no pool", poolID)
+	}
+
+	if _, err := s.pools.GetByID(ctx, poolID); err == nil {
+		return domain.Pool{}, fmt.Errorf("pool %s already exists", poolID)
+	} else if err != domain.ErrPoolNotFound {
+		return domain.Pool{}, fmt.Errorf("load pool: %w", err)
 	}
 
 	accounts, err := s.accounts.List(ctx)
 	if err != nil {
-		return "", nil, fmt.Errorf("list accounts: %w", err)
+		return domain.Pool{}, fmt.Errorf("list accounts: %w", err)
 	}
 
-	byID := make(map[domain.AccountID]domain.Account, len(accounts))
-	for _, account := range accounts {
-		byID[account.ID] = account
+	pool := domain.Pool{
+		ID:              poolID,
+		Name:            tag,
+		Provider:        domain.ProviderOpenAI,
+		Strategy:        domain.PoolStrategyLeastWeeklyUsed,
+		Active:          true,
+		AutoSyncMembers: true,
+		MemberTag:       tag,
+		UpdatedAt:       s.clock.Now(),
 	}
+	pool.Members = poolMembers(pool, accounts)
+	pool.NormalizeMembers()
 
-	candidates := make([]domain.Account, 0, len(pool.Members))
-	for _, member := range pool.Members {
-		account, ok := byID[member]
-		if !ok {
+	if err := pool.Validate(); err != nil {
+		return domain.Pool{}, err
+	}
+
+	if err := s.pools.Save(ctx, pool); err != nil {
+		return domain.Pool{}, fmt.Errorf("save pool: %w", err)
+	}
+
+	return pool, nil
+}
+
+func (s *PoolService) ListPools(ctx context.Context) ([]domain.Pool, error) {
+	pools, err := s.pools.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list pools: %w", err)
+	}
+
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].ID < pools[j].ID
+	})
+
+	return pools, nil
+}
+
+// RemoveMember drops id from the members of every pool and returns the pools
+// that changed.
+func (s *PoolService) RemoveMember(ctx context.Context, id domain.AccountID) ([]domain.PoolID, error) {
+	pools, err := s.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []domain.PoolID
+	for _, pool := range pools {
+		index := slices.Index(pool.Members, id)
+		if index < 0 {
 			continue
 		}
-		if !isPoolProviderMatch(pool, account) {
-			continue
+		pool.Members = slices.Delete(pool.Members, index, index+1)
+		pool.UpdatedAt = s.clock.Now()
+		if err := s.pools.Save(ctx, pool); err != nil {
+			return removed, fmt.Errorf("save pool: %w", err)
 		}
-		if account.Limits.Weekly != nil && account.Limits.Weekly.Percent >= 100 {
+		removed = append(removed, pool.ID)
+	}
+
+	return removed, nil
+}
+
+// RenameMember replaces from with to in the members of every pool and returns
+// the pools that changed.
+func (s *PoolService) RenameMember(ctx context.Context, from, to domain.AccountID) ([]domain.PoolID, error) {
+	pools, err := s.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var renamed []domain.PoolID
+	for _, pool := range pools {
+		index := slices.Index(pool.Members, from)
+		if index < 0 {
 			continue
 		}
-		candidates = append(candidates, account)
+		pool.Members[index] = to
+		pool.NormalizeMembers()
+		pool.UpdatedAt = s.clock.Now()
+		if err := s.pools.Save(ctx, pool); err != nil {
+			return renamed, fmt.Errorf("save pool: %w", err)
+		}
+		renamed = append(renamed, pool.ID)
 	}
 
-	if len(candidates) == 0 {
-		return "", nil, fmt.Errorf("no eligible accounts in pool %s", poolID)
+	return renamed, nil
+}
+
+func (s *PoolService) ActivateAllPools(ctx context.Context) ([]domain.Pool, error) {
+	return s.setAllPoolsActive(ctx, s.ActivatePool)
+}
+
+func (s *PoolService) DeactivateAllPools(ctx context.Context) ([]domain.Pool, error) {
+	return s.setAllPoolsActive(ctx, s.DeactivatePool)
+}
+
+func (s *PoolService) setAllPoolsActive(ctx context.Context, toggle func(context.Context, domain.PoolID) (domain.Pool, error)) ([]domain.Pool, error) {
+	pools, err := s.ListPools(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	sort.Slice(candidates, func(i, j int) bool {
-		left := weeklyPercent(candidates[i])
-		right := weeklyPercent(candidates[j])
-		if left == right {
-			return string(candidates[i].ID) < string(candidates[j].ID)
+	toggled := make([]domain.Pool, 0, len(pools))
+	for _, pool := range pools {
+		updated, err := toggle(ctx, pool.ID)
+		if err != nil {
+			return toggled, fmt.Errorf("pool %s: %w", pool.ID, err)
 		}
-		return left < right
-	})
+		toggled = append(toggled, updated)
+	}
+
+	return toggled, nil
+}
+
+func (s *PoolService) DeactivatePool(ctx context.Context, poolID domain.PoolID) (domain.Pool, error) {
+	pool, err := s.pools.GetByID(ctx, poolID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	pool.Active = false
+	pool.UpdatedAt = s.clock.Now()
+	if err := s.pools.Save(ctx, pool); err != nil {
+		return domain.Pool{}, fmt.Errorf("save pool: %w", err)
+	}
+
+	return pool, nil
+}
+
+// PickOptions tunes account eligibility for a single pick. RespectDaily
+// skips accounts whose 5-hour window is exhausted, in addition to the pool's
+// own respect_daily setting.
+type PickOptions struct {
+	RespectDaily bool
+}
+
+func (s *PoolService) PickAccount(ctx context.Context, poolID domain.PoolID) (domain.AccountID, []domain.AccountID, error) {
+	return s.PickAccountWithOptions(ctx, poolID, PickOptions{})
+}
+
+func (s *PoolService) PickAccountWithOptions(ctx context.Context, poolID domain.PoolID, opts PickOptions) (domain.AccountID, []domain.AccountID, error) {
+	_, candidates, err := s.eligibleMembers(ctx, poolID, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	orderByLeastWeeklyUsed(candidates)
 
 	picked := candidates[0].ID
 	failover := make([]domain.AccountID, 0, len(candidates)-1)
@@ -148,7 +377,7 @@ func (s *PoolService) GetPool(ctx context.Context, poolID domain.PoolID) (domain
 		if err != nil {
 			return domain.Pool{}, fmt.Errorf("list accounts: %w", err)
 		}
-		pool.Members = openAIMembers(accounts)
+		pool.Members = poolMembers(pool, accounts)
 		pool.NormalizeMembers()
 	}
 
@@ -156,17 +385,32 @@ func (s *PoolService) GetPool(ctx context.Context, poolID domain.PoolID) (domain
 }
 
 func (s *PoolService) EligibleAccounts(ctx context.Context, poolID domain.PoolID) ([]domain.Account, error) {
-	pool, err := s.pools.GetByID(ctx, poolID)
+	return s.EligibleAccountsWithOptions(ctx, poolID, PickOptions{})
+}
+
+func (s *PoolService) EligibleAccountsWithOptions(ctx context.Context, poolID domain.PoolID, opts PickOptions) ([]domain.Account, error) {
+	_, eligible, err := s.eligibleMembers(ctx, poolID, opts)
 	if err != nil {
 		return nil, err
 	}
+
+	orderByLeastWeeklyUsed(eligible)
+	return eligible, nil
+}
+
+// eligibleMembers returns the pool and its pickable members in member order.
+func (s *PoolService) eligibleMembers(ctx context.Context, poolID domain.PoolID, opts PickOptions) (domain.Pool, []domain.Account, error) {
+	pool, err := s.pools.GetByID(ctx, poolID)
+	if err != nil {
+		return domain.Pool{}, nil, err
+	}
 	if !pool.Active {
-		return nil, domain.ErrPoolInactive
+		return domain.Pool{}, nil, domain.ErrPoolInactive
 	}
 
 	accounts, err := s.accounts.List(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("list accounts: %w", err)
+		return domain.Pool{}, nil, fmt.Errorf("list accounts: %w", err)
 	}
 
 	byID := make(map[domain.AccountID]domain.Account, len(accounts))
@@ -174,26 +418,28 @@ func (s *PoolService) EligibleAccounts(ctx context.Context, poolID domain.PoolID
 		byID[account.ID] = account
 	}
 
+	respectDaily := opts.RespectDaily || pool.RespectDaily
+	now := s.clock.Now()
 	eligible := make([]domain.Account, 0, len(pool.Members))
 	for _, member := range pool.Members {
 		account, ok := byID[member]
 		if !ok {
 			continue
 		}
-		if !isPoolProviderMatch(pool, account) {
+		if !isPoolProviderMatch(pool, account) || account.Disabled {
 			continue
 		}
-		if account.Limits.Weekly != nil && account.Limits.Weekly.Percent >= 100 {
+		if isLimitExhausted(account, respectDaily, now) {
 			continue
 		}
 		eligible = append(eligible, account)
 	}
 
 	if len(eligible) == 0 {
-		return nil, fmt.Errorf("no eligible accounts in pool %s", poolID)
+		return domain.Pool{}, nil, fmt.Errorf("%w in pool %s", domain.ErrNoEligibleAccounts, poolID)
 	}
 
-	return eligible, nil
+	return pool, eligible, nil
 }
 
 func (s *PoolService) NextAccount(ctx context.Context, poolID domain.PoolID, current domain.AccountID) (domain.AccountID, error) {
@@ -217,7 +463,11 @@ func (s *PoolService) NextAccount(ctx context.Context, poolID domain.PoolID, cur
 }
 
 func (s *PoolService) IsEligibleAccount(ctx context.Context, poolID domain.PoolID, accountID domain.AccountID) (bool, error) {
-	eligible, err := s.EligibleAccounts(ctx, poolID)
+	return s.IsEligibleAccountWithOptions(ctx, poolID, accountID, PickOptions{})
+}
+
+func (s *PoolService) IsEligibleAccountWithOptions(ctx context.Context, poolID domain.PoolID, accountID domain.AccountID, opts PickOptions) (bool, error) {
+	eligible, err := s.EligibleAccountsWithOptions(ctx, poolID, opts)
 	if err != nil {
 		return false, err
 	}
@@ -231,17 +481,62 @@ func (s *PoolService) IsEligibleAccount(ctx context.Context, poolID domain.PoolI
 	return false, nil
 }
 
-func openAIMembers(accounts []domain.Account) []domain.AccountID {
+func poolMembers(pool domain.Pool, accounts []domain.Account) []domain.AccountID {
 	members := make([]domain.AccountID, 0, len(accounts))
 	for _, account := range accounts {
-		provider := strings.TrimSpace(strings.ToLower(account.Metadata.Provider))
-		if provider == string(domain.ProviderOpenAI) || account.Auth.Method == domain.AuthMethodChatGPT {
-			members = append(members, account.ID)
+		if !isPoolProviderMatch(pool, account) {
+			continue
 		}
+		if pool.MemberTag != "" && !account.HasTag(pool.MemberTag) {
+			continue
+		}
+		members = append(members, account.ID)
 	}
 	return members
 }
 
+// memberDifference returns the members of a that are missing from b.
+func memberDifference(a, b []domain.AccountID) []domain.AccountID {
+	present := make(map[domain.AccountID]struct{}, len(b))
+	for _, id := range b {
+		present[id] = struct{}{}
+	}
+
+	diff := make([]domain.AccountID, 0)
+	for _, id := range a {
+		if _, ok := present[id]; !ok {
+			diff = append(diff, id)
+		}
+	}
+	return diff
+}
+
+// isLimitExhausted reports whether an account's weekly window is used up or,
+// when respectDaily is set, its 5-hour window is used up and not yet reset.
+func isLimitExhausted(account domain.Account, respectDaily bool, now time.Time) bool {
+	if account.Limits.Weekly != nil && account.Limits.Weekly.Percent >= 100 {
+		return true
+	}
+	if !respectDaily || account.Limits.Daily == nil || account.Limits.Daily.Percent < 100 {
+		return false
+	}
+	return account.Limits.Daily.ResetsAt.IsZero() || account.Limits.Daily.ResetsAt.After(now)
+}
+
+// orderByLeastWeeklyUsed sorts accounts best-first, so the first entry is the
+// account PickAccount would choose. least_weekly_used is the only strategy,
+// and pools saved without one fall back to it.
+func orderByLeastWeeklyUsed(accounts []domain.Account) {
+	sort.Slice(accounts, func(i, j int) bool {
+		left := weeklyPercent(accounts[i])
+		right := weeklyPercent(accounts[j])
+		if left == right {
+			return string(accounts[i].ID) < string(accounts[j].ID)
+		}
+		return left < right
+	})
+}
+
 func weeklyPercent(account domain.Account) float64 {
 	if account.Limits.Weekly == nil {
 		return 0
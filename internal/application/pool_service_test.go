@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	memoryrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/memory"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,12 +14,12 @@ import (
 func TestPoolServiceActivateDefaultOpenAIPoolCreatesPool(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
 		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
 		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
 		{ID: "x", Metadata: domain.AccountMetadata{Provider: "anthropic"}},
-	}}
-	pools := &inMemoryPoolRepo{}
+	}...)
+	pools := memoryrepo.NewPoolRepository()
 	svc := NewPoolService(repo, pools, nil)
 
 	pool, err := svc.ActivateDefaultOpenAIPool(context.Background())
@@ -29,15 +30,45 @@ func TestPoolServiceActivateDefaultOpenAIPoolCreatesPool(t *testing.T) {
 	assert.True(t, pool.AutoSyncMembers)
 }
 
+func TestPoolServiceActivateDefaultAnthropicPoolSelectsAnthropicMembers(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+		{ID: "x", Metadata: domain.AccountMetadata{Provider: "anthropic"}},
+		{ID: "y", Metadata: domain.AccountMetadata{Provider: "Anthropic"}},
+	}...)
+	pools := memoryrepo.NewPoolRepository()
+	svc := NewPoolService(repo, pools, nil)
+
+	pool, err := svc.ActivateDefaultPool(context.Background(), domain.ProviderAnthropic)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAnthropicPoolID, pool.ID)
+	assert.Equal(t, domain.ProviderAnthropic, pool.Provider)
+	assert.Equal(t, []domain.AccountID{"x", "y"}, pool.Members)
+
+	eligible, err := svc.EligibleAccounts(context.Background(), DefaultAnthropicPoolID)
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+
+	openai, err := svc.ActivatePool(context.Background(), DefaultOpenAIPoolID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"1", "2"}, openai.Members)
+
+	_, err = svc.ActivateDefaultPool(context.Background(), "foo")
+	require.ErrorContains(t, err, "unsupported provider")
+}
+
 func TestPoolServiceActivateDefaultPoolSyncsMembers(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
 		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
 		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:              "default-openai",
 			Name:            "default",
 			Provider:        domain.ProviderOpenAI,
@@ -46,7 +77,7 @@ func TestPoolServiceActivateDefaultPoolSyncsMembers(t *testing.T) {
 			AutoSyncMembers: true,
 			Members:         []domain.AccountID{"1"},
 		},
-	}}
+	)
 
 	svc := NewPoolService(repo, pools, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
 
@@ -56,22 +87,186 @@ func TestPoolServiceActivateDefaultPoolSyncsMembers(t *testing.T) {
 	assert.True(t, pool.Active)
 }
 
+func TestPoolServicePlanDefaultPoolActivationReportsDiffWithoutSaving(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
+			ID:              "default-openai",
+			Name:            "default",
+			Provider:        domain.ProviderOpenAI,
+			Strategy:        domain.PoolStrategyLeastWeeklyUsed,
+			AutoSyncMembers: true,
+			Members:         []domain.AccountID{"1", "3"},
+		},
+	)
+	svc := NewPoolService(repo, pools, nil)
+
+	plan, err := svc.PlanDefaultOpenAIPoolActivation(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"1", "2"}, plan.Pool.Members)
+	assert.Equal(t, []domain.AccountID{"2"}, plan.Added)
+	assert.Equal(t, []domain.AccountID{"3"}, plan.Removed)
+
+	stored, err := pools.GetByID(context.Background(), "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"1", "3"}, stored.Members)
+	assert.False(t, stored.Active)
+}
+
+func TestPoolServiceActivateAndDeactivateAllPools(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
+			ID:              "default-openai",
+			Name:            "default",
+			Provider:        domain.ProviderOpenAI,
+			Strategy:        domain.PoolStrategyLeastWeeklyUsed,
+			AutoSyncMembers: true,
+		},
+		domain.Pool{
+			ID:       "team",
+			Name:     "team",
+			Provider: domain.ProviderOpenAI,
+			Strategy: domain.PoolStrategyLeastWeeklyUsed,
+			Members:  []domain.AccountID{"1"},
+		},
+	)
+	svc := NewPoolService(repo, pools, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
+
+	activated, err := svc.ActivateAllPools(context.Background())
+	require.NoError(t, err)
+	require.Len(t, activated, 2)
+	assert.True(t, storedPool(t, pools, "default-openai").Active)
+	assert.True(t, storedPool(t, pools, "team").Active)
+	assert.Equal(t, []domain.AccountID{"1"}, storedPool(t, pools, "default-openai").Members)
+
+	deactivated, err := svc.DeactivateAllPools(context.Background())
+	require.NoError(t, err)
+	require.Len(t, deactivated, 2)
+	assert.False(t, storedPool(t, pools, "default-openai").Active)
+	assert.False(t, storedPool(t, pools, "team").Active)
+}
+
+func TestPoolServiceTagPoolSyncsOnlyTaggedAccounts(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai", Tags: []string{"work"}}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "3", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}, Metadata: domain.AccountMetadata{Tags: []string{"Work", "personal"}}},
+		{ID: "x", Metadata: domain.AccountMetadata{Provider: "anthropic", Tags: []string{"work"}}},
+	}...)
+	pools := memoryrepo.NewPoolRepository()
+	svc := NewPoolService(repo, pools, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
+
+	pool, err := svc.CreatePoolFromTag(context.Background(), "work", "work")
+	require.NoError(t, err)
+	assert.Equal(t, "work", pool.MemberTag)
+	assert.True(t, pool.Active)
+	assert.Equal(t, []domain.AccountID{"1", "3"}, pool.Members)
+
+	require.NoError(t, repo.SaveAll(context.Background(), []domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai", Tags: []string{"work"}}},
+	}))
+
+	synced, err := svc.GetPool(context.Background(), "work")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"2", "3"}, synced.Members)
+
+	_, err = svc.CreatePoolFromTag(context.Background(), "work", "work")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pool work already exists")
+}
+
+func TestPoolServiceCreatePoolSavesFixedMembers(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "3", Metadata: domain.AccountMetadata{Provider: "openai"}},
+	}...)
+	pools := memoryrepo.NewPoolRepository()
+	now := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+	svc := NewPoolService(repo, pools, fixedClock{now: now})
+	ctx := context.Background()
+
+	pool, err := svc.CreatePool(ctx, domain.Pool{
+		ID:       "team",
+		Name:     "Team",
+		Provider: domain.ProviderOpenAI,
+		Strategy: domain.PoolStrategyLeastWeeklyUsed,
+		Members:  []domain.AccountID{"3", "1", "3"},
+	})
+	require.NoError(t, err)
+	assert.True(t, pool.Active)
+	assert.False(t, pool.AutoSyncMembers)
+	assert.Equal(t, now, pool.UpdatedAt)
+
+	stored := storedPool(t, pools, "team")
+	assert.Equal(t, "Team", stored.Name)
+	assert.Equal(t, []domain.AccountID{"3", "1"}, stored.Members)
+
+	listed, err := svc.ListPools(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, domain.PoolID("team"), listed[0].ID)
+
+	eligible, err := svc.EligibleAccounts(ctx, "team")
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: "team", Name: "Team", Provider: domain.ProviderOpenAI, Strategy: domain.PoolStrategyLeastWeeklyUsed})
+	require.ErrorContains(t, err, "pool team already exists")
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: DefaultOpenAIPoolID, Name: "default", Provider: domain.ProviderOpenAI, Strategy: domain.PoolStrategyLeastWeeklyUsed})
+	require.ErrorContains(t, err, "reserved")
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: UnpooledGroupID, Name: "Unpooled", Provider: domain.ProviderOpenAI, Strategy: domain.PoolStrategyLeastWeeklyUsed})
+	require.ErrorContains(t, err, "pool unpooled is reserved")
+
+	_, err = svc.CreatePoolFromTag(ctx, UnpooledGroupID, "work")
+	require.ErrorContains(t, err, "pool unpooled is reserved")
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: "odd", Name: "Odd", Provider: domain.ProviderOpenAI, Strategy: "round_robin"})
+	require.ErrorContains(t, err, `unsupported strategy "round_robin"`)
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: "ghost", Name: "Ghost", Provider: domain.ProviderOpenAI, Strategy: domain.PoolStrategyLeastWeeklyUsed, Members: []domain.AccountID{"9"}})
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	_, err = svc.CreatePool(ctx, domain.Pool{ID: "nameless", Provider: domain.ProviderOpenAI, Strategy: domain.PoolStrategyLeastWeeklyUsed})
+	require.Error(t, err)
+
+	_, err = pools.GetByID(ctx, "ghost")
+	require.ErrorIs(t, err, domain.ErrPoolNotFound)
+}
+
 func TestPoolServicePickAccountSkipsExhausted(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
 		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 100}}},
 		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 30}}},
 		{ID: "3", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 10}}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:       "default-openai",
 			Provider: domain.ProviderOpenAI,
 			Active:   true,
 			Members:  []domain.AccountID{"1", "2", "3"},
 		},
-	}}
+	)
 	svc := NewPoolService(repo, pools, nil)
 
 	picked, failover, err := svc.PickAccount(context.Background(), "default-openai")
@@ -80,162 +275,276 @@ func TestPoolServicePickAccountSkipsExhausted(t *testing.T) {
 	assert.Equal(t, []domain.AccountID{"2"}, failover)
 }
 
+func TestPoolServiceSkipsDisabledAccountsWithBudget(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 50}}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 0}}, Disabled: true},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
+			ID:       "default-openai",
+			Provider: domain.ProviderOpenAI,
+			Active:   true,
+			Members:  []domain.AccountID{"1", "2"},
+		},
+	)
+	svc := NewPoolService(repo, pools, nil)
+	ctx := context.Background()
+
+	picked, failover, err := svc.PickAccount(ctx, "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("1"), picked)
+	assert.Empty(t, failover)
+
+	eligible, err := svc.EligibleAccounts(ctx, "default-openai")
+	require.NoError(t, err)
+	require.Len(t, eligible, 1)
+	assert.Equal(t, domain.AccountID("1"), eligible[0].ID)
+
+	ok, err := svc.IsEligibleAccount(ctx, "default-openai", "2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPoolServicePickAccountRespectsDailyExhaustion(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{
+			Weekly: &domain.AccountLimitSnapshot{Percent: 5},
+			Daily:  &domain.AccountLimitSnapshot{Percent: 100, ResetsAt: now.Add(2 * time.Hour)},
+		}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{
+			Weekly: &domain.AccountLimitSnapshot{Percent: 40},
+			Daily:  &domain.AccountLimitSnapshot{Percent: 100, ResetsAt: now.Add(-time.Minute)},
+		}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
+			ID:       "default-openai",
+			Provider: domain.ProviderOpenAI,
+			Active:   true,
+			Members:  []domain.AccountID{"1", "2"},
+		},
+	)
+	svc := NewPoolService(repo, pools, fixedClock{now: now})
+
+	picked, _, err := svc.PickAccount(context.Background(), "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("1"), picked)
+
+	picked, failover, err := svc.PickAccountWithOptions(context.Background(), "default-openai", PickOptions{RespectDaily: true})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("2"), picked)
+	assert.Empty(t, failover)
+
+	pool := storedPool(t, pools, "default-openai")
+	pool.RespectDaily = true
+	require.NoError(t, pools.Save(context.Background(), pool))
+
+	eligible, err := svc.IsEligibleAccount(context.Background(), "default-openai", "1")
+	require.NoError(t, err)
+	assert.False(t, eligible)
+}
+
 func TestPoolServicePickAccountFailsWhenPoolIsInactive(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
 		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:       "default-openai",
 			Provider: domain.ProviderOpenAI,
 			Active:   false,
 			Members:  []domain.AccountID{"1"},
 		},
-	}}
+	)
 	svc := NewPoolService(repo, pools, nil)
 
 	_, _, err := svc.PickAccount(context.Background(), "default-openai")
 	require.ErrorIs(t, err, domain.ErrPoolInactive)
 }
 
-func TestPoolServiceEligibleAccountsKeepsPoolOrderAndSkipsExhausted(t *testing.T) {
+func TestPoolServiceEligibleAccountsOrdersByStrategyAndSkipsExhausted(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
 		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 10}}},
 		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 100}}},
 		{ID: "3", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 60}}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:       "default-openai",
 			Provider: domain.ProviderOpenAI,
 			Active:   true,
 			Members:  []domain.AccountID{"3", "2", "1"},
 		},
-	}}
+	)
 
 	svc := NewPoolService(repo, pools, nil)
 
 	accounts, err := svc.EligibleAccounts(context.Background(), "default-openai")
 	require.NoError(t, err)
 	require.Len(t, accounts, 2)
-	assert.Equal(t, domain.AccountID("3"), accounts[0].ID)
-	assert.Equal(t, domain.AccountID("1"), accounts[1].ID)
+	assert.Equal(t, domain.AccountID("1"), accounts[0].ID)
+	assert.Equal(t, domain.AccountID("3"), accounts[1].ID)
 }
 
-func TestPoolServiceNextAccountRotatesInEligibleOrder(t *testing.T) {
+func TestPoolServiceEligibleAccountsMatchesPickOrder(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
-		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}},
-		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}},
-		{ID: "3", Metadata: domain.AccountMetadata{Provider: "openai"}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "a", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 75}}},
+		{ID: "b", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 20}}},
+		{ID: "c", Metadata: domain.AccountMetadata{Provider: "openai"}},
+		{ID: "d", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 20}}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:       "default-openai",
 			Provider: domain.ProviderOpenAI,
+			Strategy: domain.PoolStrategyLeastWeeklyUsed,
 			Active:   true,
-			Members:  []domain.AccountID{"1", "2", "3"},
+			Members:  []domain.AccountID{"a", "d", "b", "c"},
 		},
-	}}
+	)
 
 	svc := NewPoolService(repo, pools, nil)
 
-	next, err := svc.NextAccount(context.Background(), "default-openai", "1")
+	eligible, err := svc.EligibleAccounts(context.Background(), "default-openai")
 	require.NoError(t, err)
-	assert.Equal(t, domain.AccountID("2"), next)
+	ids := make([]domain.AccountID, 0, len(eligible))
+	for _, account := range eligible {
+		ids = append(ids, account.ID)
+	}
+	assert.Equal(t, []domain.AccountID{"c", "b", "d", "a"}, ids)
 
-	wrapped, err := svc.NextAccount(context.Background(), "default-openai", "3")
+	picked, failover, err := svc.PickAccount(context.Background(), "default-openai")
 	require.NoError(t, err)
-	assert.Equal(t, domain.AccountID("1"), wrapped)
+	assert.Equal(t, ids[0], picked)
+	assert.Equal(t, ids[1:], failover)
 }
 
-func TestPoolServiceEligibleAccountsIncludesChatGPTAuthWithEmptyProvider(t *testing.T) {
+func TestPoolServiceNextAccountRotatesInStrategyOrder(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryAccountRepo{accounts: []domain.Account{
-		{ID: "1", Metadata: domain.AccountMetadata{Provider: ""}, Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
-		{ID: "2", Metadata: domain.AccountMetadata{Provider: ""}, Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
-	}}
-	pools := &inMemoryPoolRepo{pools: map[domain.PoolID]domain.Pool{
-		"default-openai": {
+	weekly := func(percent float64) domain.AccountLimitSnapshots {
+		return domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: percent}}
+	}
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: weekly(10)},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: weekly(80)},
+		{ID: "3", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: weekly(100)},
+		{ID: "4", Metadata: domain.AccountMetadata{Provider: "openai"}, Limits: weekly(5)},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
 			ID:       "default-openai",
 			Provider: domain.ProviderOpenAI,
 			Active:   true,
-			Members:  []domain.AccountID{"1", "2"},
+			Members:  []domain.AccountID{"1", "2", "3", "4"},
 		},
-	}}
+	)
 
 	svc := NewPoolService(repo, pools, nil)
 
-	eligible, err := svc.EligibleAccounts(context.Background(), "default-openai")
-	require.NoError(t, err)
-	require.Len(t, eligible, 2)
-	assert.Equal(t, domain.AccountID("1"), eligible[0].ID)
-	assert.Equal(t, domain.AccountID("2"), eligible[1].ID)
+	// least_weekly_used orders the eligible accounts 4, 1, 2.
+	for current, want := range map[domain.AccountID]domain.AccountID{
+		"":  "4",
+		"4": "1",
+		"1": "2",
+		"2": "4",
+		"3": "4",
+	} {
+		next, err := svc.NextAccount(context.Background(), "default-openai", current)
+		require.NoError(t, err)
+		assert.Equal(t, want, next, "after %q", current)
+	}
 }
 
-type inMemoryPoolRepo struct {
-	pools map[domain.PoolID]domain.Pool
-}
+func TestPoolServiceChatGPTAccountWithoutProviderIsMemberAndPickable(t *testing.T) {
+	t.Parallel()
 
-func (r *inMemoryPoolRepo) GetByID(_ context.Context, id domain.PoolID) (domain.Pool, error) {
-	if r.pools == nil {
-		r.pools = map[domain.PoolID]domain.Pool{}
-	}
-	pool, ok := r.pools[id]
-	if !ok {
-		return domain.Pool{}, domain.ErrPoolNotFound
-	}
-	return pool, nil
-}
+	// A fresh chatgpt login may not carry a provider yet; it is still an
+	// openai pool member and must be pickable. Blank API-key accounts are not.
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 0}}},
+		{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 20}}},
+	}...)
+	pools := memoryrepo.NewPoolRepository()
+	svc := NewPoolService(repo, pools, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
+	ctx := context.Background()
 
-func (r *inMemoryPoolRepo) List(_ context.Context) ([]domain.Pool, error) {
-	result := make([]domain.Pool, 0, len(r.pools))
-	for _, pool := range r.pools {
-		result = append(result, pool)
-	}
-	return result, nil
-}
+	pool, err := svc.ActivateDefaultOpenAIPool(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AccountID{"2"}, pool.Members)
 
-func (r *inMemoryPoolRepo) Save(_ context.Context, pool domain.Pool) error {
-	if r.pools == nil {
-		r.pools = map[domain.PoolID]domain.Pool{}
-	}
-	r.pools[pool.ID] = pool
-	return nil
+	picked, failover, err := svc.PickAccount(ctx, pool.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("2"), picked)
+	assert.Empty(t, failover)
 }
 
-type inMemoryAccountRepo struct {
-	accounts []domain.Account
-}
+func TestPoolServicePickAccountKeepsBlankProviderChatGPTMember(t *testing.T) {
+	t.Parallel()
 
-func (r *inMemoryAccountRepo) GetByID(_ context.Context, id domain.AccountID) (domain.Account, error) {
-	for _, account := range r.accounts {
-		if account.ID == id {
-			return account, nil
-		}
-	}
-	return domain.Account{}, domain.ErrAccountNotFound
+	// The provider filter must not skip a chatgpt member whose provider was
+	// never recorded, even when it is the least used account in the pool.
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: "openai"}, Auth: domain.Auth{Method: domain.AuthMethodAPIKey}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 40}}},
+		{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodChatGPT}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 10}}},
+		{ID: "3", Auth: domain.Auth{Method: domain.AuthMethodAPIKey}, Limits: domain.AccountLimitSnapshots{Weekly: &domain.AccountLimitSnapshot{Percent: 0}}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(domain.Pool{
+		ID:       "work",
+		Provider: domain.ProviderOpenAI,
+		Active:   true,
+		Members:  []domain.AccountID{"1", "2", "3"},
+	})
+	svc := NewPoolService(repo, pools, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
+
+	picked, failover, err := svc.PickAccount(context.Background(), "work")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("2"), picked)
+	assert.Equal(t, []domain.AccountID{"1"}, failover)
 }
 
-func (r *inMemoryAccountRepo) List(_ context.Context) ([]domain.Account, error) {
-	return r.accounts, nil
+func TestPoolServiceEligibleAccountsIncludesChatGPTAuthWithEmptyProvider(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewAccountRepository([]domain.Account{
+		{ID: "1", Metadata: domain.AccountMetadata{Provider: ""}, Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+		{ID: "2", Metadata: domain.AccountMetadata{Provider: ""}, Auth: domain.Auth{Method: domain.AuthMethodChatGPT}},
+	}...)
+	pools := memoryrepo.NewPoolRepository(
+		domain.Pool{
+			ID:       "default-openai",
+			Provider: domain.ProviderOpenAI,
+			Active:   true,
+			Members:  []domain.AccountID{"1", "2"},
+		},
+	)
+
+	svc := NewPoolService(repo, pools, nil)
+
+	eligible, err := svc.EligibleAccounts(context.Background(), "default-openai")
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+	assert.Equal(t, domain.AccountID("1"), eligible[0].ID)
+	assert.Equal(t, domain.AccountID("2"), eligible[1].ID)
 }
 
-func (r *inMemoryAccountRepo) Save(_ context.Context, account domain.Account) error {
-	for i := range r.accounts {
-		if r.accounts[i].ID == account.ID {
-			r.accounts[i] = account
-			return nil
-		}
-	}
-	r.accounts = append(r.accounts, account)
-	return nil
+func storedPool(t *testing.T, pools *memoryrepo.PoolRepository, id domain.PoolID) domain.Pool {
+	t.Helper()
+	pool, err := pools.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	return pool
 }
 
 type fixedClock struct {
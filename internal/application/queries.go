@@ -13,6 +13,20 @@ type StatusLimit struct {
 	CapturedAt time.Time
 }
 
+// LeftPercent is the share of the window still available, clamped to 0-100.
+func (l StatusLimit) LeftPercent() float64 {
+	return min(max(100-l.Percent, 0), 100)
+}
+
+// SecondsUntilReset counts down to ResetsAt from now. It is 0 when the reset
+// time is unknown or already passed.
+func (l StatusLimit) SecondsUntilReset(now time.Time) int64 {
+	if l.ResetsAt.IsZero() || !l.ResetsAt.After(now) {
+		return 0
+	}
+	return int64(l.ResetsAt.Sub(now) / time.Second)
+}
+
 type StatusSubscription struct {
 	ActiveStart     time.Time
 	ActiveUntil     time.Time
@@ -23,10 +37,17 @@ type StatusSubscription struct {
 	IsDelinquent    bool
 }
 
+type StatusFeatureLimit struct {
+	Feature     string
+	DailyLimit  *StatusLimit
+	WeeklyLimit *StatusLimit
+}
+
 type Status struct {
-	Account      domain.Account
-	Usage        domain.Usage
-	DailyLimit   *StatusLimit
-	WeeklyLimit  *StatusLimit
-	Subscription *StatusSubscription
+	Account       domain.Account
+	Usage         domain.Usage
+	DailyLimit    *StatusLimit
+	WeeklyLimit   *StatusLimit
+	FeatureLimits []StatusFeatureLimit
+	Subscription  *StatusSubscription
 }
@@ -12,6 +12,10 @@ import (
 
 var ErrUnsupportedWindowKind = errors.New("unsupported limit window kind")
 
+// ErrInvalidLimitPercent rejects percents outside [0,100] so bad payloads are
+// surfaced instead of being hidden by render-time clamping.
+var ErrInvalidLimitPercent = errors.New("limit percent must be between 0 and 100")
+
 type Service struct {
 	repo  ports.AccountRepository
 	store ports.SecretStore
@@ -129,6 +133,207 @@ func (s *Service) RemoveAuth(ctx context.Context, id domain.AccountID) error {
 	return nil
 }
 
+// RemoveAccount deletes the account's secrets, as RemoveAuth does, and then
+// the account itself.
+func (s *Service) RemoveAccount(ctx context.Context, id domain.AccountID) error {
+	if err := s.RemoveAuth(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete account: %w", err)
+	}
+
+	return nil
+}
+
+// DetachAuth clears the account's auth like RemoveAuth but leaves the stored
+// secrets in place, so they can be kept or reattached to another account.
+func (s *Service) DetachAuth(ctx context.Context, id domain.AccountID) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+
+	account.Auth = domain.Auth{}
+	account.Metadata.SecretRef = ""
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account auth: %w", err)
+	}
+	return nil
+}
+
+// MigrateSecrets copies every account secret, read through the configured
+// store chain, into target and then deletes it from each stale backend that
+// holds a copy.
+func (s *Service) MigrateSecrets(ctx context.Context, target ports.SecretStore, stale []ports.SecretStore) ([]string, error) {
+	refs, err := s.storedSecretRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := make([]string, 0, len(refs))
+	for _, secretRef := range refs {
+		value, err := s.store.Get(ctx, secretRef)
+		if err != nil {
+			return migrated, fmt.Errorf("load secret %q: %w", secretRef, err)
+		}
+		if err := target.Put(ctx, secretRef, value); err != nil {
+			return migrated, fmt.Errorf("store secret %q in target backend: %w", secretRef, err)
+		}
+		for _, backend := range stale {
+			// Only touch backends that hold a copy: one that never had the
+			// key, or cannot even be reached, has nothing to clean up.
+			held, err := backend.Has(ctx, secretRef)
+			if err != nil || !held {
+				continue
+			}
+			if err := backend.Delete(ctx, secretRef); err != nil && !errors.Is(err, domain.ErrSecretNotFound) {
+				return migrated, fmt.Errorf("delete secret %q from previous backend: %w", secretRef, err)
+			}
+		}
+		migrated = append(migrated, secretRef)
+	}
+
+	return migrated, nil
+}
+
+// SecretMove is the outcome of MoveSecrets.
+type SecretMove struct {
+	Moved   []string
+	Skipped []string
+}
+
+// MoveSecrets copies every account secret that source holds into target and,
+// with deleteSource, removes the source copy afterwards. Secrets source does
+// not hold are skipped, so it only moves what actually lives there; any other
+// read failure stops the move.
+func (s *Service) MoveSecrets(ctx context.Context, source, target ports.SecretStore, deleteSource bool) (SecretMove, error) {
+	refs, err := s.storedSecretRefs(ctx)
+	if err != nil {
+		return SecretMove{}, err
+	}
+
+	var move SecretMove
+	for _, secretRef := range refs {
+		value, err := source.Get(ctx, secretRef)
+		if errors.Is(err, domain.ErrSecretNotFound) {
+			move.Skipped = append(move.Skipped, secretRef)
+			continue
+		}
+		if err != nil {
+			return move, fmt.Errorf("read secret %q from source backend: %w", secretRef, err)
+		}
+		if err := target.Put(ctx, secretRef, value); err != nil {
+			return move, fmt.Errorf("store secret %q in target backend: %w", secretRef, err)
+		}
+		if deleteSource {
+			if err := source.Delete(ctx, secretRef); err != nil {
+				return move, fmt.Errorf("delete secret %q from source backend: %w", secretRef, err)
+			}
+		}
+		move.Moved = append(move.Moved, secretRef)
+	}
+
+	return move, nil
+}
+
+// SecretRename is a secret copied to a new ref by ChangeAccountID. DeleteErr
+// is set when the copy under From could not be removed; the account already
+// uses To, so the rename itself stands.
+type SecretRename struct {
+	From      string
+	To        string
+	DeleteErr error
+}
+
+// ChangeAccountID rewrites the ID of account from to to, keeping its place
+// in the accounts file. Each secret ref listed in refs is moved to its
+// mapped ref (read, written under the new ref, then deleted); other refs,
+// such as custom keys and cmd:// refs, are kept as they are.
+func (s *Service) ChangeAccountID(ctx context.Context, from, to domain.AccountID, refs map[string]string) ([]SecretRename, error) {
+	exists, err := s.repo.Exists(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("check account exists: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("account %s: %w", to, domain.ErrAccountExists)
+	}
+
+	account, err := s.repo.GetByID(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("get account by id: %w", err)
+	}
+
+	var renames []SecretRename
+	for _, secretRef := range uniqueSecretRefs(account.Metadata.SecretRef, account.Auth.SecretRef) {
+		target, ok := refs[secretRef]
+		if !ok || target == secretRef {
+			continue
+		}
+		value, err := s.store.Get(ctx, secretRef)
+		if err == nil {
+			err = s.store.Put(ctx, target, value)
+		}
+		if err != nil {
+			return nil, s.rollbackSecretRenames(ctx, renames, fmt.Errorf("move secret %q to %q: %w", secretRef, target, err))
+		}
+		renames = append(renames, SecretRename{From: secretRef, To: target})
+	}
+
+	account.ID = to
+	for _, rename := range renames {
+		if account.Auth.SecretRef == rename.From {
+			account.Auth.SecretRef = rename.To
+		}
+		if account.Metadata.SecretRef == rename.From {
+			account.Metadata.SecretRef = rename.To
+		}
+	}
+	if err := s.repo.Replace(ctx, from, account); err != nil {
+		return nil, s.rollbackSecretRenames(ctx, renames, fmt.Errorf("save account id: %w", err))
+	}
+
+	for i, rename := range renames {
+		if err := s.store.Delete(ctx, rename.From); err != nil {
+			renames[i].DeleteErr = fmt.Errorf("delete previous secret %q: %w", rename.From, err)
+		}
+	}
+
+	return renames, nil
+}
+
+// rollbackSecretRenames deletes the copies written before err stopped a
+// rename, leaving the original secrets as the only ones.
+func (s *Service) rollbackSecretRenames(ctx context.Context, renames []SecretRename, err error) error {
+	for _, rename := range renames {
+		if deleteErr := s.store.Delete(ctx, rename.To); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback secret %q: %w", rename.To, deleteErr))
+		}
+	}
+	return err
+}
+
+// storedSecretRefs lists the secret refs of every account, once each. Command
+// refs live outside oa's backends and are left out.
+func (s *Service) storedSecretRefs(ctx context.Context) ([]string, error) {
+	accounts, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	refs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		for _, secretRef := range []string{account.Metadata.SecretRef, account.Auth.SecretRef} {
+			if !domain.IsCommandSecretRef(secretRef) {
+				refs = append(refs, secretRef)
+			}
+		}
+	}
+	return uniqueSecretRefs(refs...), nil
+}
+
 func uniqueSecretRefs(secretRefs ...string) []string {
 	result := make([]string, 0, len(secretRefs))
 	seen := make(map[string]struct{}, len(secretRefs))
@@ -200,13 +405,29 @@ func (s *Service) SetAccountName(ctx context.Context, id domain.AccountID, name
 	return nil
 }
 
+// SetAccountDisabled toggles whether pools may select the account.
+func (s *Service) SetAccountDisabled(ctx context.Context, id domain.AccountID, disabled bool) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+
+	account.Disabled = disabled
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account disabled: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) SetAccountPlanType(ctx context.Context, id domain.AccountID, planType string) error {
 	account, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("get account by id: %w", err)
 	}
 
-	account.Metadata.PlanType = planType
+	account.Metadata.PlanType = domain.NormalizePlanType(planType)
 
 	if err := s.repo.Save(ctx, account); err != nil {
 		return fmt.Errorf("save account plan type: %w", err)
@@ -215,6 +436,95 @@ func (s *Service) SetAccountPlanType(ctx context.Context, id domain.AccountID, p
 	return nil
 }
 
+func (s *Service) SetAccountProvider(ctx context.Context, id domain.AccountID, provider string) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+
+	account.Metadata.Provider = provider
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account provider: %w", err)
+	}
+
+	return nil
+}
+
+// DefaultAccountProvider tags the account with provider only when it has
+// none yet, so re-authenticating keeps a provider chosen earlier.
+func (s *Service) DefaultAccountProvider(ctx context.Context, id domain.AccountID, provider string) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+	if account.Metadata.Provider != "" {
+		return nil
+	}
+
+	account.Metadata.Provider = provider
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account provider: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) SetAccountBaseURL(ctx context.Context, id domain.AccountID, baseURL string) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+
+	account.Metadata.BaseURL = baseURL
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account base url: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) TagAccount(ctx context.Context, id domain.AccountID, add, remove []string) (domain.Account, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("get account by id: %w", err)
+	}
+
+	removed := make(map[string]struct{}, len(remove))
+	for _, tag := range remove {
+		removed[domain.NormalizeTag(tag)] = struct{}{}
+	}
+
+	tags := make([]string, 0, len(account.Metadata.Tags)+len(add))
+	seen := make(map[string]struct{}, cap(tags))
+	for _, tag := range append(append([]string{}, account.Metadata.Tags...), add...) {
+		tag = domain.NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := removed[tag]; ok {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	account.Metadata.Tags = tags
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return domain.Account{}, fmt.Errorf("save account tags: %w", err)
+	}
+
+	return account, nil
+}
+
 func (s *Service) SetLimit(ctx context.Context, id domain.AccountID, kind LimitWindowKind, percent float64, resetsAt, capturedAt time.Time) error {
 	if !kind.Valid() {
 		return fmt.Errorf("%w: %q", ErrUnsupportedWindowKind, kind)
@@ -225,46 +535,209 @@ func (s *Service) SetLimit(ctx context.Context, id domain.AccountID, kind LimitW
 		return fmt.Errorf("get account by id: %w", err)
 	}
 
+	if err := s.applyLimit(&account.Limits, LimitUpdate{Window: kind, Percent: percent, ResetsAt: resetsAt, CapturedAt: capturedAt}); err != nil {
+		return err
+	}
+	recordLimitHistory(&account, kind)
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account limit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) SetSubscription(ctx context.Context, id domain.AccountID, sub domain.Subscription) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account by id: %w", err)
+	}
+
+	s.applySubscription(&account, sub)
+
+	if err := s.repo.Save(ctx, account); err != nil {
+		return fmt.Errorf("save account subscription: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyUsageUpdates applies a batch of fetched usage data and persists every
+// touched account with a single repository write. An update that fails
+// validation or whose account is gone is skipped and returned instead of
+// failing the whole batch.
+func (s *Service) ApplyUsageUpdates(ctx context.Context, updates []UsageUpdate) ([]SkippedUsageUpdate, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	indexByID := make(map[domain.AccountID]int, len(accounts))
+	for i, account := range accounts {
+		indexByID[account.ID] = i
+	}
+
+	var skipped []SkippedUsageUpdate
+	touched := make([]domain.Account, 0, len(updates))
+	touchedIndex := make(map[domain.AccountID]int, len(updates))
+	for _, update := range updates {
+		i, ok := indexByID[update.AccountID]
+		if !ok {
+			skipped = append(skipped, SkippedUsageUpdate{AccountID: update.AccountID, Err: domain.ErrAccountNotFound})
+			continue
+		}
+		if err := update.Validate(); err != nil {
+			skipped = append(skipped, SkippedUsageUpdate{AccountID: update.AccountID, Err: err})
+			continue
+		}
+		account := &accounts[i]
+
+		for _, limit := range update.Limits {
+			if err := s.applyLimit(&account.Limits, limit); err != nil {
+				return nil, fmt.Errorf("apply usage update for account %s: %w", update.AccountID, err)
+			}
+			recordLimitHistory(account, limit.Window)
+		}
+		features, err := s.buildFeatureLimits(update.FeatureLimits)
+		if err != nil {
+			return nil, fmt.Errorf("apply usage update for account %s: %w", update.AccountID, err)
+		}
+		account.Limits.Features = features
+		if update.Name != "" {
+			account.Name = update.Name
+		}
+		if planType := domain.NormalizePlanType(update.PlanType); planType != "" {
+			account.Metadata.PlanType = planType
+		}
+		if update.Subscription != nil {
+			s.applySubscription(account, *update.Subscription)
+		}
+
+		if j, ok := touchedIndex[account.ID]; ok {
+			touched[j] = *account
+			continue
+		}
+		touchedIndex[account.ID] = len(touched)
+		touched = append(touched, *account)
+	}
+
+	if len(touched) > 0 {
+		if err := s.repo.SaveAll(ctx, touched); err != nil {
+			return nil, fmt.Errorf("save usage updates: %w", err)
+		}
+	}
+
+	return skipped, nil
+}
+
+func (s *Service) buildFeatureLimits(updates []FeatureLimitUpdate) ([]domain.FeatureLimitSnapshots, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	features := make([]domain.FeatureLimitSnapshots, 0, len(updates))
+	for _, update := range updates {
+		var limits domain.AccountLimitSnapshots
+		for _, limit := range update.Limits {
+			if err := s.applyLimit(&limits, limit); err != nil {
+				return nil, fmt.Errorf("feature %s: %w", update.Feature, err)
+			}
+		}
+		features = append(features, domain.FeatureLimitSnapshots{
+			Feature: update.Feature,
+			Daily:   limits.Daily,
+			Weekly:  limits.Weekly,
+		})
+	}
+	return features, nil
+}
+
+func validateLimit(update LimitUpdate) error {
+	if !update.Window.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnsupportedWindowKind, update.Window)
+	}
+	if !(update.Percent >= 0 && update.Percent <= 100) {
+		return fmt.Errorf("%w: got %v", ErrInvalidLimitPercent, update.Percent)
+	}
+	return nil
+}
+
+func (s *Service) applyLimit(limits *domain.AccountLimitSnapshots, update LimitUpdate) error {
+	if err := validateLimit(update); err != nil {
+		return err
+	}
+
+	capturedAt := update.CapturedAt
 	if capturedAt.IsZero() {
 		capturedAt = s.clock.Now()
 	}
 
 	snapshot := &domain.AccountLimitSnapshot{
-		Percent:    percent,
-		ResetsAt:   resetsAt,
+		Percent:    update.Percent,
+		ResetsAt:   update.ResetsAt,
 		CapturedAt: capturedAt,
 	}
-	switch kind {
+	switch update.Window {
 	case LimitWindowDaily:
-		account.Limits.Daily = snapshot
+		limits.Daily = snapshot
 	case LimitWindowWeekly:
-		account.Limits.Weekly = snapshot
-	}
-
-	if err := s.repo.Save(ctx, account); err != nil {
-		return fmt.Errorf("save account limit: %w", err)
+		limits.Weekly = snapshot
 	}
 
 	return nil
 }
 
-func (s *Service) SetSubscription(ctx context.Context, id domain.AccountID, sub domain.Subscription) error {
-	account, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("get account by id: %w", err)
+// recordLimitHistory appends the snapshot just applied for window to the
+// account's usage history.
+func recordLimitHistory(account *domain.Account, window LimitWindowKind) {
+	snapshot := account.Limits.Daily
+	if window == LimitWindowWeekly {
+		snapshot = account.Limits.Weekly
+	}
+	if snapshot == nil {
+		return
 	}
 
+	account.RecordUsageHistory(domain.UsageHistoryEntry{
+		Window:     string(window),
+		Percent:    snapshot.Percent,
+		ResetsAt:   snapshot.ResetsAt,
+		CapturedAt: snapshot.CapturedAt,
+	})
+}
+
+func (s *Service) applySubscription(account *domain.Account, sub domain.Subscription) {
 	if sub.CapturedAt.IsZero() {
 		sub.CapturedAt = s.clock.Now()
 	}
 
 	account.Subscription = &sub
+}
 
-	if err := s.repo.Save(ctx, account); err != nil {
-		return fmt.Errorf("save account subscription: %w", err)
+// UsageHistory returns the recorded usage history of an account captured
+// within [since, until]. A zero bound leaves that side open.
+func (s *Service) UsageHistory(ctx context.Context, id domain.AccountID, since, until time.Time) ([]domain.UsageHistoryEntry, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account by id: %w", err)
 	}
 
-	return nil
+	entries := make([]domain.UsageHistoryEntry, 0, len(account.History))
+	for _, entry := range account.History {
+		if !since.IsZero() && entry.CapturedAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && entry.CapturedAt.After(until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
 }
 
 func (s *Service) GetStatus(ctx context.Context, id domain.AccountID) (Status, error) {
@@ -292,12 +765,29 @@ func (s *Service) GetStatusAll(ctx context.Context) ([]Status, error) {
 
 func statusFromAccount(account domain.Account) Status {
 	return Status{
-		Account:      account,
-		Usage:        account.Usage,
-		DailyLimit:   toStatusLimit(LimitWindowDaily, account.Limits.Daily),
-		WeeklyLimit:  toStatusLimit(LimitWindowWeekly, account.Limits.Weekly),
-		Subscription: toStatusSubscription(account.Subscription),
+		Account:       account,
+		Usage:         account.Usage,
+		DailyLimit:    toStatusLimit(LimitWindowDaily, account.Limits.Daily),
+		WeeklyLimit:   toStatusLimit(LimitWindowWeekly, account.Limits.Weekly),
+		FeatureLimits: toStatusFeatureLimits(account.Limits.Features),
+		Subscription:  toStatusSubscription(account.Subscription),
+	}
+}
+
+func toStatusFeatureLimits(features []domain.FeatureLimitSnapshots) []StatusFeatureLimit {
+	if len(features) == 0 {
+		return nil
+	}
+
+	result := make([]StatusFeatureLimit, 0, len(features))
+	for _, feature := range features {
+		result = append(result, StatusFeatureLimit{
+			Feature:     feature.Feature,
+			DailyLimit:  toStatusLimit(LimitWindowDaily, feature.Daily),
+			WeeklyLimit: toStatusLimit(LimitWindowWeekly, feature.Weekly),
+		})
 	}
+	return result
 }
 
 func toStatusSubscription(sub *domain.Subscription) *StatusSubscription {
@@ -3,12 +3,17 @@ package application
 import (
 	"context"
 	"errors"
+	"math"
 	"path/filepath"
 	"testing"
 	"time"
 
+	memoryrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/memory"
 	tomlrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/toml"
+	filestore "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/file"
+	memorysecrets "github.com/bnema/openai-accounts-cli/internal/adapters/secrets/memory"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
+	"github.com/bnema/openai-accounts-cli/internal/ports"
 	"github.com/bnema/openai-accounts-cli/internal/ports/mocks"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -231,6 +236,77 @@ func TestServiceRemoveAuthSuccess(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceRemoveAccountDeletesSecretsAndAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.NewAccountRepository(
+		domain.Account{
+			ID:       "acc-1",
+			Metadata: domain.AccountMetadata{SecretRef: "openai://acc-1/metadata_key"},
+			Auth:     domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"},
+		},
+		domain.Account{ID: "acc-2"},
+	)
+	store := memorysecrets.NewStore()
+	require.NoError(t, store.Put(ctx, "openai://acc-1/metadata_key", "meta"))
+	require.NoError(t, store.Put(ctx, "openai://acc-1/api_key", "sk-1"))
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	require.NoError(t, service.RemoveAccount(ctx, "acc-1"))
+
+	_, err := repo.GetByID(ctx, "acc-1")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	for _, secretRef := range []string{"openai://acc-1/metadata_key", "openai://acc-1/api_key"} {
+		_, err := store.Get(ctx, secretRef)
+		require.ErrorIs(t, err, memorysecrets.ErrNotFound, secretRef)
+	}
+
+	err = service.RemoveAccount(ctx, "acc-1")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func TestServiceRemoveAccountKeepsAccountWhenSecretDeleteFails(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	deleteErr := errors.New("delete failed")
+	account := domain.Account{ID: "acc-1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"}}
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(account, nil)
+	repo.EXPECT().Save(mockAnyContext(), domain.Account{ID: "acc-1"}).Return(nil)
+	store.EXPECT().Delete(mockAnyContext(), "openai://acc-1/api_key").Return(deleteErr)
+	restored := account
+	restored.Metadata.SecretRef = "openai://acc-1/api_key"
+	repo.EXPECT().Save(mockAnyContext(), restored).Return(nil)
+
+	err := service.RemoveAccount(context.Background(), "acc-1")
+	require.ErrorIs(t, err, deleteErr)
+}
+
+func TestServiceDetachAuthKeepsSecret(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.NewAccountRepository(domain.Account{
+		ID:       "acc-1",
+		Metadata: domain.AccountMetadata{SecretRef: "openai://acc-1/api_key"},
+		Auth:     domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"},
+	})
+	store := memorysecrets.NewStore()
+	require.NoError(t, store.Put(ctx, "openai://acc-1/api_key", "sk-keep"))
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	require.NoError(t, service.DetachAuth(ctx, "acc-1"))
+
+	account, err := repo.GetByID(ctx, "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Auth{}, account.Auth)
+	assert.Empty(t, account.Metadata.SecretRef)
+	value, err := store.Get(ctx, "openai://acc-1/api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-keep", value)
+}
+
 func TestServiceRemoveAuthReturnsErrorWhenDeleteFails(t *testing.T) {
 	repo := mocks.NewMockAccountRepository(t)
 	store := mocks.NewMockSecretStore(t)
@@ -373,6 +449,205 @@ func TestServiceSetUsageAndGetStatus(t *testing.T) {
 	assert.Equal(t, usage, status.Usage)
 }
 
+func TestServiceMigrateSecretsCopiesIntoTargetAndDeletesStaleCopies(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	target := mocks.NewMockSecretStore(t)
+	stale := mocks.NewMockSecretStore(t)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	repo.EXPECT().List(mockAnyContext()).Return([]domain.Account{
+		{ID: "acc-1", Metadata: domain.AccountMetadata{SecretRef: "openai://acc-1/api_key"}, Auth: domain.Auth{SecretRef: "openai://acc-1/api_key"}},
+		{ID: "acc-2"},
+		{ID: "acc-3", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "cmd://op read op://vault/item/token"}},
+	}, nil)
+	store.EXPECT().Get(mockAnyContext(), "openai://acc-1/api_key").Return("secret-value", nil)
+	target.EXPECT().Put(mockAnyContext(), "openai://acc-1/api_key", "secret-value").Return(nil)
+	stale.EXPECT().Has(mockAnyContext(), "openai://acc-1/api_key").Return(true, nil)
+	stale.EXPECT().Delete(mockAnyContext(), "openai://acc-1/api_key").Return(nil)
+
+	migrated, err := service.MigrateSecrets(context.Background(), target, []ports.SecretStore{stale})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openai://acc-1/api_key"}, migrated)
+}
+
+func TestServiceMigrateSecretsSkipsStaleBackendsWithoutTheKey(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	target := mocks.NewMockSecretStore(t)
+	missing := mocks.NewMockSecretStore(t)
+	unreachable := mocks.NewMockSecretStore(t)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	repo.EXPECT().List(mockAnyContext()).Return([]domain.Account{
+		{ID: "acc-1", Auth: domain.Auth{SecretRef: "openai://acc-1/api_key"}},
+	}, nil)
+	store.EXPECT().Get(mockAnyContext(), "openai://acc-1/api_key").Return("secret-value", nil)
+	target.EXPECT().Put(mockAnyContext(), "openai://acc-1/api_key", "secret-value").Return(nil)
+	missing.EXPECT().Has(mockAnyContext(), "openai://acc-1/api_key").Return(false, nil)
+	unreachable.EXPECT().Has(mockAnyContext(), "openai://acc-1/api_key").Return(false, errors.New("pass command unavailable"))
+
+	migrated, err := service.MigrateSecrets(context.Background(), target, []ports.SecretStore{missing, unreachable})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openai://acc-1/api_key"}, migrated)
+}
+
+func TestServiceMigrateSecretsKeepsStaleCopyWhenTargetPutFails(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	target := mocks.NewMockSecretStore(t)
+	stale := mocks.NewMockSecretStore(t)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	repo.EXPECT().List(mockAnyContext()).Return([]domain.Account{
+		{ID: "acc-1", Auth: domain.Auth{SecretRef: "openai://acc-1/api_key"}},
+	}, nil)
+	store.EXPECT().Get(mockAnyContext(), "openai://acc-1/api_key").Return("secret-value", nil)
+	target.EXPECT().Put(mockAnyContext(), "openai://acc-1/api_key", "secret-value").Return(errors.New("pass unavailable"))
+
+	migrated, err := service.MigrateSecrets(context.Background(), target, []ports.SecretStore{stale})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "store secret \"openai://acc-1/api_key\" in target backend")
+	assert.Empty(t, migrated)
+}
+
+func TestServiceMoveSecretsCopiesFromSourceRootAndSkipsMissing(t *testing.T) {
+	ctx := context.Background()
+	source := filestore.NewStore(filepath.Join(t.TempDir(), "from"))
+	target := filestore.NewStore(filepath.Join(t.TempDir(), "to"))
+	repo := memoryrepo.NewAccountRepository(
+		domain.Account{ID: "1", Auth: domain.Auth{Method: domain.AuthMethodChatGPT, SecretRef: "openai://1/oauth_tokens"}},
+		domain.Account{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://2/api_key"}},
+		domain.Account{ID: "3", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "cmd://printf sk-3"}},
+	)
+	service := NewService(repo, source, mocks.NewMockClock(t))
+	require.NoError(t, source.Put(ctx, "openai://1/oauth_tokens", `{"access_token":"a"}`))
+
+	move, err := service.MoveSecrets(ctx, source, target, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openai://1/oauth_tokens"}, move.Moved)
+	assert.Equal(t, []string{"openai://2/api_key"}, move.Skipped)
+
+	value, err := target.Get(ctx, "openai://1/oauth_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, `{"access_token":"a"}`, value)
+	_, err = source.Get(ctx, "openai://1/oauth_tokens")
+	require.NoError(t, err, "the source copy stays without deleteSource")
+
+	move, err = service.MoveSecrets(ctx, source, target, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openai://1/oauth_tokens"}, move.Moved)
+	_, err = source.Get(ctx, "openai://1/oauth_tokens")
+	require.Error(t, err)
+	_, err = target.Get(ctx, "openai://1/oauth_tokens")
+	require.NoError(t, err)
+}
+
+func TestServiceMoveSecretsStopsOnSourceReadError(t *testing.T) {
+	ctx := context.Background()
+	source := mocks.NewMockSecretStore(t)
+	target := mocks.NewMockSecretStore(t)
+	repo := memoryrepo.NewAccountRepository(
+		domain.Account{ID: "1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://1/api_key"}},
+		domain.Account{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://2/api_key"}},
+		domain.Account{ID: "3", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://3/api_key"}},
+	)
+	service := NewService(repo, source, mocks.NewMockClock(t))
+
+	source.EXPECT().Get(mockAnyContext(), "openai://1/api_key").Return("", memorysecrets.ErrNotFound).Once()
+	source.EXPECT().Get(mockAnyContext(), "openai://2/api_key").Return("", errors.New("gpg: decryption failed: No secret key")).Once()
+
+	move, err := service.MoveSecrets(ctx, source, target, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `read secret "openai://2/api_key" from source backend`)
+	assert.Contains(t, err.Error(), "decryption failed")
+	assert.Equal(t, []string{"openai://1/api_key"}, move.Skipped)
+	assert.Empty(t, move.Moved)
+}
+
+func TestServiceChangeAccountIDMovesMappedSecrets(t *testing.T) {
+	ctx := context.Background()
+	store := memorysecrets.NewStore()
+	repo := memoryrepo.NewAccountRepository(
+		domain.Account{ID: "acc-1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"}, Metadata: domain.AccountMetadata{SecretRef: "openai://acc-1/api_key"}},
+		domain.Account{ID: "2", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "custom/key"}},
+	)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+	require.NoError(t, store.Put(ctx, "openai://acc-1/api_key", "sk-1"))
+	require.NoError(t, store.Put(ctx, "custom/key", "sk-2"))
+
+	renames, err := service.ChangeAccountID(ctx, "acc-1", "1", map[string]string{"openai://acc-1/api_key": "openai://1/api_key"})
+	require.NoError(t, err)
+	assert.Equal(t, []SecretRename{{From: "openai://acc-1/api_key", To: "openai://1/api_key"}}, renames)
+
+	account, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "openai://1/api_key", account.Auth.SecretRef)
+	assert.Equal(t, "openai://1/api_key", account.Metadata.SecretRef)
+	value, err := store.Get(ctx, "openai://1/api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", value)
+	_, err = store.Get(ctx, "openai://acc-1/api_key")
+	require.ErrorIs(t, err, memorysecrets.ErrNotFound)
+
+	renames, err = service.ChangeAccountID(ctx, "2", "3", map[string]string{"openai://2/api_key": "openai://3/api_key"})
+	require.NoError(t, err)
+	assert.Empty(t, renames, "custom keys keep their ref")
+	account, err = repo.GetByID(ctx, "3")
+	require.NoError(t, err)
+	assert.Equal(t, "custom/key", account.Auth.SecretRef)
+
+	_, err = service.ChangeAccountID(ctx, "3", "1", nil)
+	require.ErrorIs(t, err, domain.ErrAccountExists)
+	_, err = service.ChangeAccountID(ctx, "missing", "4", nil)
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func TestServiceChangeAccountIDRollsBackCopiedSecretWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockAccountRepository(t)
+	store := memorysecrets.NewStore()
+	service := NewService(repo, store, mocks.NewMockClock(t))
+	require.NoError(t, store.Put(ctx, "openai://acc-1/api_key", "sk-1"))
+
+	account := domain.Account{ID: "acc-1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"}}
+	repo.EXPECT().Exists(mockAnyContext(), domain.AccountID("1")).Return(false, nil)
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(account, nil)
+	repo.EXPECT().Replace(mockAnyContext(), domain.AccountID("acc-1"), mock.Anything).Return(errors.New("disk full"))
+
+	_, err := service.ChangeAccountID(ctx, "acc-1", "1", map[string]string{"openai://acc-1/api_key": "openai://1/api_key"})
+	require.ErrorContains(t, err, "save account id: disk full")
+
+	_, err = store.Get(ctx, "openai://1/api_key")
+	require.ErrorIs(t, err, memorysecrets.ErrNotFound)
+	value, err := store.Get(ctx, "openai://acc-1/api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", value)
+}
+
+func TestServiceChangeAccountIDKeepsRenameWhenOldSecretDeleteFails(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.NewAccountRepository(
+		domain.Account{ID: "acc-1", Auth: domain.Auth{Method: domain.AuthMethodAPIKey, SecretRef: "openai://acc-1/api_key"}},
+	)
+	store := mocks.NewMockSecretStore(t)
+	service := NewService(repo, store, mocks.NewMockClock(t))
+
+	store.EXPECT().Get(mockAnyContext(), "openai://acc-1/api_key").Return("sk-1", nil)
+	store.EXPECT().Put(mockAnyContext(), "openai://1/api_key", "sk-1").Return(nil)
+	store.EXPECT().Delete(mockAnyContext(), "openai://acc-1/api_key").Return(errors.New("pass locked"))
+
+	renames, err := service.ChangeAccountID(ctx, "acc-1", "1", map[string]string{"openai://acc-1/api_key": "openai://1/api_key"})
+	require.NoError(t, err)
+	require.Len(t, renames, 1)
+	assert.Equal(t, "openai://1/api_key", renames[0].To)
+	require.ErrorContains(t, renames[0].DeleteErr, `delete previous secret "openai://acc-1/api_key": pass locked`)
+
+	account, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "openai://1/api_key", account.Auth.SecretRef)
+}
+
 func TestServiceSetAccountName(t *testing.T) {
 	repo := mocks.NewMockAccountRepository(t)
 	store := mocks.NewMockSecretStore(t)
@@ -405,6 +680,84 @@ func TestServiceSetAccountPlanType(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceSetAccountPlanTypeNormalizesCaseAndWhitespace(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	clock := mocks.NewMockClock(t)
+	service := NewService(repo, store, clock)
+
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(domain.Account{ID: "acc-1", Name: "Primary"}, nil)
+	repo.EXPECT().Save(mockAnyContext(), domain.Account{
+		ID:   "acc-1",
+		Name: "Primary",
+		Metadata: domain.AccountMetadata{
+			PlanType: "team",
+		},
+	}).Return(nil)
+
+	err := service.SetAccountPlanType(context.Background(), "acc-1", " TEAM ")
+	require.NoError(t, err)
+}
+
+func TestServiceSetAccountProvider(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	clock := mocks.NewMockClock(t)
+	service := NewService(repo, store, clock)
+
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(domain.Account{ID: "acc-1", Name: "Primary"}, nil)
+	repo.EXPECT().Save(mockAnyContext(), domain.Account{
+		ID:   "acc-1",
+		Name: "Primary",
+		Metadata: domain.AccountMetadata{
+			Provider: "openai",
+		},
+	}).Return(nil)
+
+	err := service.SetAccountProvider(context.Background(), "acc-1", "openai")
+	require.NoError(t, err)
+}
+
+func TestServiceDefaultAccountProviderOnlyFillsEmptyProvider(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	clock := mocks.NewMockClock(t)
+	service := NewService(repo, store, clock)
+
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(domain.Account{
+		ID:       "acc-1",
+		Metadata: domain.AccountMetadata{Provider: "anthropic"},
+	}, nil)
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-2")).Return(domain.Account{ID: "acc-2"}, nil)
+	repo.EXPECT().Save(mockAnyContext(), domain.Account{
+		ID:       "acc-2",
+		Metadata: domain.AccountMetadata{Provider: "openai"},
+	}).Return(nil)
+
+	require.NoError(t, service.DefaultAccountProvider(context.Background(), "acc-1", "openai"))
+	require.NoError(t, service.DefaultAccountProvider(context.Background(), "acc-2", "openai"))
+}
+
+func TestServiceTagAccountAddsAndRemovesNormalizedTags(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	clock := mocks.NewMockClock(t)
+	service := NewService(repo, store, clock)
+
+	repo.EXPECT().GetByID(mockAnyContext(), domain.AccountID("acc-1")).Return(domain.Account{
+		ID:       "acc-1",
+		Metadata: domain.AccountMetadata{Tags: []string{"work", "old"}},
+	}, nil)
+	repo.EXPECT().Save(mockAnyContext(), domain.Account{
+		ID:       "acc-1",
+		Metadata: domain.AccountMetadata{Tags: []string{"work", "team-a"}},
+	}).Return(nil)
+
+	account, err := service.TagAccount(context.Background(), "acc-1", []string{" Team-A ", "WORK"}, []string{"old"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work", "team-a"}, account.Metadata.Tags)
+}
+
 func TestServiceSetLimitUsesClockWhenCapturedAtZero(t *testing.T) {
 	repo := mocks.NewMockAccountRepository(t)
 	store := mocks.NewMockSecretStore(t)
@@ -542,6 +895,22 @@ func TestServiceSetLimitRejectsUnsupportedWindow(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestServiceSetLimitRejectsPercentOutOfRange(t *testing.T) {
+	repo := memoryrepo.NewAccountRepository(domain.Account{ID: "acc-1"})
+	service := NewService(repo, nil, fixedClock{now: time.Now()})
+
+	for _, percent := range []float64{120, -5, math.NaN()} {
+		err := service.SetLimit(context.Background(), "acc-1", LimitWindowWeekly, percent, time.Now(), time.Now())
+		require.ErrorIs(t, err, ErrInvalidLimitPercent)
+	}
+	err := service.SetLimit(context.Background(), "acc-1", LimitWindowWeekly, 120, time.Now(), time.Now())
+	assert.EqualError(t, err, "limit percent must be between 0 and 100: got 120")
+
+	account, err := repo.GetByID(context.Background(), "acc-1")
+	require.NoError(t, err)
+	assert.Nil(t, account.Limits.Weekly)
+}
+
 func TestServiceSetSubscription(t *testing.T) {
 	repo := mocks.NewMockAccountRepository(t)
 	store := mocks.NewMockSecretStore(t)
@@ -571,6 +940,139 @@ func TestServiceSetSubscription(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceApplyUsageUpdatesPersistsBatchWithSingleWrite(t *testing.T) {
+	repo := mocks.NewMockAccountRepository(t)
+	store := mocks.NewMockSecretStore(t)
+	clock := mocks.NewMockClock(t)
+	service := NewService(repo, store, clock)
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	accounts := []domain.Account{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	repo.EXPECT().List(mockAnyContext()).Return(accounts, nil).Once()
+	repo.EXPECT().SaveAll(mockAnyContext(), mock.MatchedBy(func(saved []domain.Account) bool {
+		if len(saved) != 3 {
+			return false
+		}
+		for _, account := range saved {
+			if account.Limits.Daily == nil || account.Limits.Weekly == nil || account.Subscription == nil {
+				return false
+			}
+		}
+		return saved[0].Name == "one@example.com" && saved[1].Metadata.PlanType == "pro"
+	})).Return(nil).Once()
+
+	updates := make([]UsageUpdate, 0, len(accounts))
+	for _, account := range accounts {
+		updates = append(updates, UsageUpdate{
+			AccountID: account.ID,
+			Limits: []LimitUpdate{
+				{Window: LimitWindowDaily, Percent: 10, ResetsAt: now.Add(time.Hour), CapturedAt: now},
+				{Window: LimitWindowWeekly, Percent: 20, ResetsAt: now.Add(48 * time.Hour), CapturedAt: now},
+			},
+			Subscription: &domain.Subscription{WillRenew: true, CapturedAt: now},
+		})
+	}
+	updates[0].Name = "one@example.com"
+	updates[1].PlanType = "pro"
+
+	skipped, err := service.ApplyUsageUpdates(context.Background(), updates)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+}
+
+func TestServiceApplyUsageUpdatesSkipsMissingAccount(t *testing.T) {
+	repo := memoryrepo.NewAccountRepository(domain.Account{ID: "1"})
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	service := NewService(repo, nil, fixedClock{now: now})
+
+	skipped, err := service.ApplyUsageUpdates(context.Background(), []UsageUpdate{
+		{AccountID: "missing", Limits: []LimitUpdate{{Window: LimitWindowWeekly, Percent: 10}}},
+		{AccountID: "1", Limits: []LimitUpdate{{Window: LimitWindowWeekly, Percent: 20}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+	assert.Equal(t, domain.AccountID("missing"), skipped[0].AccountID)
+	assert.ErrorIs(t, skipped[0].Err, domain.ErrAccountNotFound)
+
+	account, err := repo.GetByID(context.Background(), "1")
+	require.NoError(t, err)
+	require.NotNil(t, account.Limits.Weekly)
+	assert.Equal(t, 20.0, account.Limits.Weekly.Percent)
+
+	_, err = repo.GetByID(context.Background(), "missing")
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func TestServiceApplyUsageUpdatesSkipsPercentOutOfRange(t *testing.T) {
+	repo := memoryrepo.NewAccountRepository(domain.Account{ID: "1"}, domain.Account{ID: "2"}, domain.Account{ID: "3"})
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	service := NewService(repo, nil, fixedClock{now: now})
+
+	skipped, err := service.ApplyUsageUpdates(context.Background(), []UsageUpdate{
+		{
+			AccountID: "1",
+			Limits:    []LimitUpdate{{Window: LimitWindowWeekly, Percent: 140}},
+		},
+		{
+			AccountID: "2",
+			FeatureLimits: []FeatureLimitUpdate{{
+				Feature: "codex",
+				Limits:  []LimitUpdate{{Window: LimitWindowDaily, Percent: math.NaN()}},
+			}},
+		},
+		{
+			AccountID: "3",
+			Limits:    []LimitUpdate{{Window: LimitWindowWeekly, Percent: 30}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, skipped, 2)
+	assert.Equal(t, domain.AccountID("1"), skipped[0].AccountID)
+	assert.ErrorIs(t, skipped[0].Err, ErrInvalidLimitPercent)
+	assert.Equal(t, domain.AccountID("2"), skipped[1].AccountID)
+	assert.ErrorIs(t, skipped[1].Err, ErrInvalidLimitPercent)
+	assert.ErrorContains(t, skipped[1].Err, "feature codex")
+
+	first, err := repo.GetByID(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Nil(t, first.Limits.Weekly)
+	second, err := repo.GetByID(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Empty(t, second.Limits.Features)
+	third, err := repo.GetByID(context.Background(), "3")
+	require.NoError(t, err)
+	require.NotNil(t, third.Limits.Weekly)
+	assert.Equal(t, 30.0, third.Limits.Weekly.Percent)
+}
+
+func TestServiceUsageHistoryRecordsCapsAndFiltersByCapturedAt(t *testing.T) {
+	repo := memoryrepo.NewAccountRepository(domain.Account{ID: "1"})
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	service := NewService(repo, nil, fixedClock{now: now})
+
+	total := domain.MaxUsageHistoryEntries + 5
+	for i := 0; i < total; i++ {
+		capturedAt := now.Add(time.Duration(i-total) * time.Hour)
+		_, err := service.ApplyUsageUpdates(context.Background(), []UsageUpdate{{
+			AccountID: "1",
+			Limits:    []LimitUpdate{{Window: LimitWindowWeekly, Percent: float64(i % 100), CapturedAt: capturedAt}},
+		}})
+		require.NoError(t, err)
+	}
+
+	all, err := service.UsageHistory(context.Background(), "1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, all, domain.MaxUsageHistoryEntries)
+	assert.Equal(t, now.Add(time.Duration(5-total)*time.Hour), all[0].CapturedAt)
+	assert.Equal(t, "weekly", all[0].Window)
+
+	window, err := service.UsageHistory(context.Background(), "1", now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, window, 2)
+	assert.Equal(t, now.Add(-3*time.Hour), window[0].CapturedAt)
+	assert.Equal(t, now.Add(-2*time.Hour), window[1].CapturedAt)
+}
+
 func mockAnyContext() interface{} {
 	return mock.Anything
 }
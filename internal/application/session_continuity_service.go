@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/bnema/openai-accounts-cli/internal/ports"
@@ -31,6 +32,11 @@ func (s *SessionContinuityService) ResolveLogicalSessionID(workspaceRoot, window
 	return hex.EncodeToString(hash[:])
 }
 
+// sessionActivityResolution is how stale LastActiveAt may get before reusing
+// a session refreshes it, so a reuse does not rewrite the runtime file on
+// every run.
+const sessionActivityResolution = time.Hour
+
 func (s *SessionContinuityService) GetOrAttachAccountSession(ctx context.Context, poolID domain.PoolID, logicalSessionID string, accountID domain.AccountID) (string, bool, error) {
 	runtime, err := s.loadRuntime(ctx, poolID)
 	if err != nil {
@@ -45,7 +51,16 @@ func (s *SessionContinuityService) GetOrAttachAccountSession(ctx context.Context
 		ledger.AccountSessions = map[domain.AccountID]string{}
 	}
 
+	now := s.clock.Now()
 	if sessionID, ok := ledger.AccountSessions[accountID]; ok && strings.TrimSpace(sessionID) != "" {
+		if now.Sub(ledger.LastActiveAt) < sessionActivityResolution {
+			return sessionID, false, nil
+		}
+		ledger.LastActiveAt = now
+		runtime.Sessions[logicalSessionID] = ledger
+		if err := s.runtime.Save(ctx, runtime); err != nil {
+			return "", false, fmt.Errorf("save pool runtime: %w", err)
+		}
 		return sessionID, false, nil
 	}
 
@@ -53,10 +68,11 @@ func (s *SessionContinuityService) GetOrAttachAccountSession(ctx context.Context
 	if err != nil {
 		return "", false, err
 	}
+	ledger.LastActiveAt = now
 	ledger.AccountSessions[accountID] = sessionID
 	runtime.Sessions[logicalSessionID] = ledger
 	runtime.ActiveAccountID = accountID
-	runtime.LastSyncedAt = s.clock.Now()
+	runtime.LastSyncedAt = now
 
 	if err := s.runtime.Save(ctx, runtime); err != nil {
 		return "", false, fmt.Errorf("save pool runtime: %w", err)
@@ -65,6 +81,15 @@ func (s *SessionContinuityService) GetOrAttachAccountSession(ctx context.Context
 	return sessionID, true, nil
 }
 
+func (s *SessionContinuityService) LookupAccountSession(ctx context.Context, poolID domain.PoolID, logicalSessionID string, accountID domain.AccountID) (string, error) {
+	runtime, err := s.loadRuntime(ctx, poolID)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(runtime.Sessions[logicalSessionID].AccountSessions[accountID]), nil
+}
+
 func (s *SessionContinuityService) UpdateMemoryPacket(ctx context.Context, poolID domain.PoolID, logicalSessionID string, memory domain.MemoryPacket) error {
 	runtime, err := s.loadRuntime(ctx, poolID)
 	if err != nil {
@@ -87,6 +112,20 @@ func (s *SessionContinuityService) UpdateMemoryPacket(ctx context.Context, poolI
 	return nil
 }
 
+// UpdateMemorySummary replaces only the summary of a session's memory packet,
+// keeping its decisions, pending tasks and code refs.
+func (s *SessionContinuityService) UpdateMemorySummary(ctx context.Context, poolID domain.PoolID, logicalSessionID string, summary string) error {
+	runtime, err := s.loadRuntime(ctx, poolID)
+	if err != nil {
+		return err
+	}
+
+	memory := runtime.Sessions[logicalSessionID].Memory
+	memory.Summary = summary
+
+	return s.UpdateMemoryPacket(ctx, poolID, logicalSessionID, memory)
+}
+
 func (s *SessionContinuityService) GetActiveAccountID(ctx context.Context, poolID domain.PoolID) (domain.AccountID, error) {
 	runtime, err := s.loadRuntime(ctx, poolID)
 	if err != nil {
@@ -102,6 +141,9 @@ func (s *SessionContinuityService) SetActiveAccountID(ctx context.Context, poolI
 		return err
 	}
 
+	if runtime.ActiveAccountID != accountID {
+		runtime.LastSwitchedAt = s.clock.Now()
+	}
 	runtime.ActiveAccountID = accountID
 	runtime.LastSyncedAt = s.clock.Now()
 
@@ -112,6 +154,158 @@ func (s *SessionContinuityService) SetActiveAccountID(ctx context.Context, poolI
 	return nil
 }
 
+// SetMinSwitchInterval stores the pool next cooldown; zero disables it.
+func (s *SessionContinuityService) SetMinSwitchInterval(ctx context.Context, poolID domain.PoolID, interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("min switch interval must not be negative, got %s", interval)
+	}
+
+	runtime, err := s.loadRuntime(ctx, poolID)
+	if err != nil {
+		return err
+	}
+
+	runtime.MinSwitchInterval = interval
+
+	if err := s.runtime.Save(ctx, runtime); err != nil {
+		return fmt.Errorf("save pool runtime: %w", err)
+	}
+
+	return nil
+}
+
+// SwitchCooldownRemaining reports how long pool next must still wait after
+// the last account switch. It is zero when no cooldown applies.
+func (s *SessionContinuityService) SwitchCooldownRemaining(ctx context.Context, poolID domain.PoolID) (time.Duration, error) {
+	runtime, err := s.loadRuntime(ctx, poolID)
+	if err != nil {
+		return 0, err
+	}
+
+	if runtime.MinSwitchInterval <= 0 || runtime.LastSwitchedAt.IsZero() {
+		return 0, nil
+	}
+
+	remaining := runtime.LastSwitchedAt.Add(runtime.MinSwitchInterval).Sub(s.clock.Now())
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// PruneSessions drops session ledgers in every pool whose last activity is
+// older than maxAge and returns how many were removed. Ledgers that predate
+// activity tracking are judged by the runtime's last sync, the latest they
+// can have been used. Active accounts and switch state are left untouched.
+func (s *SessionContinuityService) PruneSessions(ctx context.Context, maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		return 0, fmt.Errorf("prune age must be positive, got %s", maxAge)
+	}
+
+	runtimes, err := s.runtime.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list pool runtimes: %w", err)
+	}
+
+	cutoff := s.clock.Now().Add(-maxAge)
+	pruned := 0
+	for _, runtime := range runtimes {
+		removed := 0
+		for id, ledger := range runtime.Sessions {
+			activity := ledger.LastActivity()
+			if activity.IsZero() {
+				activity = runtime.LastSyncedAt
+			}
+			if activity.Before(cutoff) {
+				delete(runtime.Sessions, id)
+				removed++
+			}
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := s.runtime.Save(ctx, runtime); err != nil {
+			return pruned, fmt.Errorf("save pool runtime: %w", err)
+		}
+		pruned += removed
+	}
+
+	return pruned, nil
+}
+
+// RenameAccount points every pool runtime that references from, as its
+// active account or in a session ledger, at to instead. It returns the pools
+// whose runtime changed.
+func (s *SessionContinuityService) RenameAccount(ctx context.Context, from, to domain.AccountID) ([]domain.PoolID, error) {
+	runtimes, err := s.runtime.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list pool runtimes: %w", err)
+	}
+
+	var renamed []domain.PoolID
+	for _, runtime := range runtimes {
+		changed := false
+		if runtime.ActiveAccountID == from {
+			runtime.ActiveAccountID = to
+			changed = true
+		}
+		for id, ledger := range runtime.Sessions {
+			sessionID, ok := ledger.AccountSessions[from]
+			if !ok {
+				continue
+			}
+			delete(ledger.AccountSessions, from)
+			ledger.AccountSessions[to] = sessionID
+			runtime.Sessions[id] = ledger
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := s.runtime.Save(ctx, runtime); err != nil {
+			return renamed, fmt.Errorf("save pool runtime: %w", err)
+		}
+		renamed = append(renamed, runtime.PoolID)
+	}
+
+	return renamed, nil
+}
+
+// ForgetAccount clears id as the active account of every pool runtime and
+// drops its provider sessions from the ledgers, keeping each ledger's memory.
+// It returns the pools whose runtime changed.
+func (s *SessionContinuityService) ForgetAccount(ctx context.Context, id domain.AccountID) ([]domain.PoolID, error) {
+	runtimes, err := s.runtime.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list pool runtimes: %w", err)
+	}
+
+	var forgotten []domain.PoolID
+	for _, runtime := range runtimes {
+		changed := false
+		if runtime.ActiveAccountID == id {
+			runtime.ActiveAccountID = ""
+			changed = true
+		}
+		for _, ledger := range runtime.Sessions {
+			if _, ok := ledger.AccountSessions[id]; !ok {
+				continue
+			}
+			delete(ledger.AccountSessions, id)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := s.runtime.Save(ctx, runtime); err != nil {
+			return forgotten, fmt.Errorf("save pool runtime: %w", err)
+		}
+		forgotten = append(forgotten, runtime.PoolID)
+	}
+
+	return forgotten, nil
+}
+
 func (s *SessionContinuityService) loadRuntime(ctx context.Context, poolID domain.PoolID) (domain.PoolRuntime, error) {
 	runtime, err := s.runtime.GetByPoolID(ctx, poolID)
 	if err != nil {
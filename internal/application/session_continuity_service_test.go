@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	memoryrepo "github.com/bnema/openai-accounts-cli/internal/adapters/repo/memory"
 	"github.com/bnema/openai-accounts-cli/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,8 +14,8 @@ import (
 func TestSessionContinuityReuseMappedSession(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryPoolRuntimeRepo{runtimes: map[domain.PoolID]domain.PoolRuntime{
-		"default-openai": {
+	repo := memoryrepo.NewPoolRuntimeRepository(
+		domain.PoolRuntime{
 			PoolID: "default-openai",
 			Sessions: map[string]domain.SessionLedger{
 				"proj-a": {
@@ -25,7 +26,7 @@ func TestSessionContinuityReuseMappedSession(t *testing.T) {
 				},
 			},
 		},
-	}}
+	)
 
 	svc := NewSessionContinuityService(repo, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
 
@@ -38,7 +39,7 @@ func TestSessionContinuityReuseMappedSession(t *testing.T) {
 func TestSessionContinuityBootstrapsMissingSessionAndSavesMemory(t *testing.T) {
 	t.Parallel()
 
-	repo := &inMemoryPoolRuntimeRepo{runtimes: map[domain.PoolID]domain.PoolRuntime{}}
+	repo := memoryrepo.NewPoolRuntimeRepository()
 	svc := NewSessionContinuityService(repo, fixedClock{now: time.Date(2026, 2, 28, 12, 30, 0, 0, time.UTC)})
 
 	sessionID, bootstrapped, err := svc.GetOrAttachAccountSession(context.Background(), "default-openai", "proj-a", "3")
@@ -64,10 +65,190 @@ func TestSessionContinuityBootstrapsMissingSessionAndSavesMemory(t *testing.T) {
 	require.False(t, ledger.Memory.UpdatedAt.IsZero())
 }
 
+func TestSessionContinuityUpdateMemorySummaryKeepsOtherFields(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 28, 13, 0, 0, 0, time.UTC)
+	repo := memoryrepo.NewPoolRuntimeRepository(
+		domain.PoolRuntime{
+			PoolID: "default-openai",
+			Sessions: map[string]domain.SessionLedger{
+				"proj-a": {
+					LogicalSessionID: "proj-a",
+					Memory: domain.MemoryPacket{
+						Summary:   "old summary",
+						Decisions: []string{"use wrapper"},
+					},
+				},
+			},
+		},
+	)
+	svc := NewSessionContinuityService(repo, fixedClock{now: now})
+
+	require.NoError(t, svc.UpdateMemorySummary(context.Background(), "default-openai", "proj-a", "shipped the fix"))
+
+	runtime, err := repo.GetByPoolID(context.Background(), "default-openai")
+	require.NoError(t, err)
+	memory := runtime.Sessions["proj-a"].Memory
+	assert.Equal(t, "shipped the fix", memory.Summary)
+	assert.Equal(t, []string{"use wrapper"}, memory.Decisions)
+	assert.Equal(t, now, memory.UpdatedAt)
+}
+
+func TestSessionContinuityLookupAccountSessionDoesNotAttach(t *testing.T) {
+	t.Parallel()
+
+	repo := memoryrepo.NewPoolRuntimeRepository(
+		domain.PoolRuntime{
+			PoolID: "default-openai",
+			Sessions: map[string]domain.SessionLedger{
+				"proj-a": {
+					LogicalSessionID: "proj-a",
+					AccountSessions:  map[domain.AccountID]string{"2": "session-2"},
+				},
+			},
+		},
+	)
+	svc := NewSessionContinuityService(repo, fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)})
+
+	sessionID, err := svc.LookupAccountSession(context.Background(), "default-openai", "proj-a", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "session-2", sessionID)
+
+	sessionID, err = svc.LookupAccountSession(context.Background(), "default-openai", "proj-a", "3")
+	require.NoError(t, err)
+	assert.Empty(t, sessionID)
+	runtime, err := repo.GetByPoolID(context.Background(), "default-openai")
+	require.NoError(t, err)
+	assert.NotContains(t, runtime.Sessions["proj-a"].AccountSessions, domain.AccountID("3"))
+}
+
+func TestSessionContinuitySwitchCooldownTracksAccountChanges(t *testing.T) {
+	t.Parallel()
+
+	clock := &fixedClock{now: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)}
+	svc := NewSessionContinuityService(memoryrepo.NewPoolRuntimeRepository(), clock)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SetMinSwitchInterval(ctx, "default-openai", 5*time.Minute))
+	remaining, err := svc.SwitchCooldownRemaining(ctx, "default-openai")
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	require.NoError(t, svc.SetActiveAccountID(ctx, "default-openai", "1"))
+	clock.now = clock.now.Add(2 * time.Minute)
+	remaining, err = svc.SwitchCooldownRemaining(ctx, "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, remaining)
+
+	// Re-selecting the same account does not restart the cooldown.
+	require.NoError(t, svc.SetActiveAccountID(ctx, "default-openai", "1"))
+	clock.now = clock.now.Add(4 * time.Minute)
+	remaining, err = svc.SwitchCooldownRemaining(ctx, "default-openai")
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	require.Error(t, svc.SetMinSwitchInterval(ctx, "default-openai", -time.Second))
+}
+
+func TestSessionContinuityPruneSessionsDropsOnlyInactiveLedgers(t *testing.T) {
+	t.Parallel()
+
+	clock := &fixedClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc := NewSessionContinuityService(memoryrepo.NewPoolRuntimeRepository(), clock)
+	ctx := context.Background()
+
+	_, _, err := svc.GetOrAttachAccountSession(ctx, "default-openai", "old", "1")
+	require.NoError(t, err)
+	_, _, err = svc.GetOrAttachAccountSession(ctx, "other", "old-other", "1")
+	require.NoError(t, err)
+
+	clock.now = clock.now.AddDate(0, 0, 40)
+	_, _, err = svc.GetOrAttachAccountSession(ctx, "default-openai", "recent", "2")
+	require.NoError(t, err)
+
+	pruned, err := svc.PruneSessions(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+
+	kept, err := svc.LookupAccountSession(ctx, "default-openai", "recent", "2")
+	require.NoError(t, err)
+	assert.NotEmpty(t, kept)
+	gone, err := svc.LookupAccountSession(ctx, "default-openai", "old", "1")
+	require.NoError(t, err)
+	assert.Empty(t, gone)
+
+	active, err := svc.GetActiveAccountID(ctx, "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("2"), active)
+	active, err = svc.GetActiveAccountID(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AccountID("1"), active)
+
+	_, err = svc.PruneSessions(ctx, 0)
+	require.Error(t, err)
+}
+
+func TestSessionContinuityPruneSessionsAgesLegacyLedgersByLastSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 15, 9, 0, 0, 0, time.UTC)
+	repo := memoryrepo.NewPoolRuntimeRepository(
+		domain.PoolRuntime{
+			PoolID:       "default-openai",
+			LastSyncedAt: now.AddDate(0, 0, -10),
+			Sessions: map[string]domain.SessionLedger{
+				"legacy": {LogicalSessionID: "legacy", AccountSessions: map[domain.AccountID]string{"1": "sess-1"}},
+			},
+		},
+	)
+	svc := NewSessionContinuityService(repo, fixedClock{now: now})
+	ctx := context.Background()
+
+	pruned, err := svc.PruneSessions(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+
+	pruned, err = svc.PruneSessions(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+}
+
+func TestSessionContinuityReuseSavesOnlyWhenActivityIsStale(t *testing.T) {
+	t.Parallel()
+
+	clock := &fixedClock{now: time.Date(2026, 4, 15, 9, 0, 0, 0, time.UTC)}
+	svc := NewSessionContinuityService(memoryrepo.NewPoolRuntimeRepository(), clock)
+	ctx := context.Background()
+
+	sessionID, _, err := svc.GetOrAttachAccountSession(ctx, "default-openai", "proj-a", "1")
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	reused, bootstrapped, err := svc.GetOrAttachAccountSession(ctx, "default-openai", "proj-a", "1")
+	require.NoError(t, err)
+	assert.False(t, bootstrapped)
+	assert.Equal(t, sessionID, reused)
+	assertLedgerLastActiveAt(t, svc, clock.now.Add(-10*time.Minute))
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	_, _, err = svc.GetOrAttachAccountSession(ctx, "default-openai", "proj-a", "1")
+	require.NoError(t, err)
+	assertLedgerLastActiveAt(t, svc, clock.now)
+}
+
+func assertLedgerLastActiveAt(t *testing.T, svc *SessionContinuityService, want time.Time) {
+	t.Helper()
+
+	runtime, err := svc.runtime.GetByPoolID(context.Background(), "default-openai")
+	require.NoError(t, err)
+	assert.Equal(t, want, runtime.Sessions["proj-a"].LastActiveAt)
+}
+
 func TestSessionContinuityResolveLogicalSessionPerWindow(t *testing.T) {
 	t.Parallel()
 
-	svc := NewSessionContinuityService(&inMemoryPoolRuntimeRepo{runtimes: map[domain.PoolID]domain.PoolRuntime{}}, fixedClock{now: time.Now()})
+	svc := NewSessionContinuityService(memoryrepo.NewPoolRuntimeRepository(), fixedClock{now: time.Now()})
 
 	one := svc.ResolveLogicalSessionID("/repo/a", "window-1")
 	two := svc.ResolveLogicalSessionID("/repo/a", "window-2")
@@ -77,23 +258,3 @@ func TestSessionContinuityResolveLogicalSessionPerWindow(t *testing.T) {
 	assert.NotEqual(t, one, three)
 	assert.NotEmpty(t, one)
 }
-
-type inMemoryPoolRuntimeRepo struct {
-	runtimes map[domain.PoolID]domain.PoolRuntime
-}
-
-func (r *inMemoryPoolRuntimeRepo) GetByPoolID(_ context.Context, poolID domain.PoolID) (domain.PoolRuntime, error) {
-	runtime, ok := r.runtimes[poolID]
-	if !ok {
-		return domain.PoolRuntime{}, domain.ErrPoolNotFound
-	}
-	return runtime, nil
-}
-
-func (r *inMemoryPoolRuntimeRepo) Save(_ context.Context, runtime domain.PoolRuntime) error {
-	if r.runtimes == nil {
-		r.runtimes = map[domain.PoolID]domain.PoolRuntime{}
-	}
-	r.runtimes[runtime.PoolID] = runtime
-	return nil
-}
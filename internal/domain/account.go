@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type AccountID string
 
@@ -12,6 +15,10 @@ type Account struct {
 	Usage        Usage
 	Limits       AccountLimitSnapshots
 	Subscription *Subscription
+	History      []UsageHistoryEntry
+	// Disabled keeps the account out of pool selection until re-enabled,
+	// regardless of its remaining budget.
+	Disabled bool
 }
 
 type AccountMetadata struct {
@@ -19,6 +26,50 @@ type AccountMetadata struct {
 	Model     string
 	SecretRef string
 	PlanType  string
+	Tags      []string
+	// BaseURL overrides the global usage API base URL for this account.
+	BaseURL string
+}
+
+func (a Account) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return false
+	}
+	for _, existing := range a.Metadata.Tags {
+		if NormalizeTag(existing) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizePlanType trims and lowercases a plan type ("Pro " -> "pro").
+func NormalizePlanType(planType string) string {
+	return strings.ToLower(strings.TrimSpace(planType))
+}
+
+// MaxUsageHistoryEntries caps the usage history kept per account; the oldest
+// entries are dropped first. History is stored inline in accounts.toml, so
+// the cap stays small enough to keep that file readable.
+const MaxUsageHistoryEntries = 100
+
+type UsageHistoryEntry struct {
+	Window     string
+	Percent    float64
+	ResetsAt   time.Time
+	CapturedAt time.Time
+}
+
+func (a *Account) RecordUsageHistory(entry UsageHistoryEntry) {
+	a.History = append(a.History, entry)
+	if overflow := len(a.History) - MaxUsageHistoryEntries; overflow > 0 {
+		a.History = append([]UsageHistoryEntry(nil), a.History[overflow:]...)
+	}
 }
 
 type Subscription struct {
@@ -1,9 +1,7 @@
 package domain
 
-import "strings"
-
 func AccountClassification(planType string) string {
-	switch strings.ToLower(strings.TrimSpace(planType)) {
+	switch NormalizePlanType(planType) {
 	case "":
 		return "Unknown"
 	case "team":
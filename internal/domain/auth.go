@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 type AuthMethod string
 
 const (
@@ -12,3 +14,11 @@ type Auth struct {
 	// SecretRef points to a secret-store entry, typically in "provider://path" form.
 	SecretRef string
 }
+
+// CommandSecretRefPrefix marks secret refs resolved by running a command
+// rather than read from a secret store oa writes to.
+const CommandSecretRefPrefix = "cmd://"
+
+func IsCommandSecretRef(secretRef string) bool {
+	return strings.HasPrefix(strings.TrimSpace(secretRef), CommandSecretRefPrefix)
+}
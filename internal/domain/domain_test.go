@@ -106,6 +106,10 @@ func TestAccountClassification(t *testing.T) {
 		{name: "enterprise", planType: "enterprise", want: "Business"},
 		{name: "empty", planType: "", want: "Unknown"},
 		{name: "other", planType: "plus", want: "Personal"},
+		{name: "mixed case team", planType: "TEAM", want: "Team"},
+		{name: "padded business", planType: " Business\t", want: "Business"},
+		{name: "padded personal", planType: "Pro ", want: "Personal"},
+		{name: "whitespace only", planType: "   ", want: "Unknown"},
 	}
 
 	for _, tt := range tests {
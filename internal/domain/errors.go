@@ -3,8 +3,10 @@ package domain
 import "errors"
 
 var (
-	ErrAccountNotFound = errors.New("account not found")
-	ErrPoolInactive    = errors.New("pool is deactivated")
-	ErrPoolNotFound    = errors.New("pool not found")
-	ErrSecretNotFound  = errors.New("secret not found")
+	ErrAccountExists      = errors.New("account already exists")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrNoEligibleAccounts = errors.New("no eligible accounts")
+	ErrPoolInactive       = errors.New("pool is deactivated")
+	ErrPoolNotFound       = errors.New("pool not found")
+	ErrSecretNotFound     = errors.New("secret not found")
 )
@@ -31,8 +31,15 @@ type LimitSnapshot struct {
 }
 
 type AccountLimitSnapshots struct {
-	Daily  *AccountLimitSnapshot
-	Weekly *AccountLimitSnapshot
+	Daily    *AccountLimitSnapshot
+	Weekly   *AccountLimitSnapshot
+	Features []FeatureLimitSnapshots
+}
+
+type FeatureLimitSnapshots struct {
+	Feature string
+	Daily   *AccountLimitSnapshot
+	Weekly  *AccountLimitSnapshot
 }
 
 type AccountLimitSnapshot struct {
@@ -11,11 +11,28 @@ type Provider string
 type PoolStrategy string
 
 const (
-	ProviderOpenAI Provider = "openai"
+	ProviderOpenAI    Provider = "openai"
+	ProviderAnthropic Provider = "anthropic"
 
 	PoolStrategyLeastWeeklyUsed PoolStrategy = "least_weekly_used"
 )
 
+// Supported reports whether pools can be created for the provider. Usage
+// fetching is still OpenAI-only.
+func (p Provider) Supported() bool {
+	switch p {
+	case ProviderOpenAI, ProviderAnthropic:
+		return true
+	default:
+		return false
+	}
+}
+
+// Supported reports whether pools know how to order accounts by the strategy.
+func (s PoolStrategy) Supported() bool {
+	return s == PoolStrategyLeastWeeklyUsed
+}
+
 type Pool struct {
 	ID              PoolID
 	Name            string
@@ -23,6 +40,8 @@ type Pool struct {
 	Strategy        PoolStrategy
 	Active          bool
 	AutoSyncMembers bool
+	MemberTag       string
+	RespectDaily    bool
 	Members         []AccountID
 	UpdatedAt       time.Time
 }
@@ -37,7 +56,7 @@ func (p Pool) Validate() error {
 	if strings.TrimSpace(string(p.Provider)) == "" {
 		return fmt.Errorf("provider is required")
 	}
-	if p.Provider != ProviderOpenAI {
+	if !p.Provider.Supported() {
 		return fmt.Errorf("unsupported provider %q", p.Provider)
 	}
 	if p.Strategy == "" {
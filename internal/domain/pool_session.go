@@ -14,11 +14,25 @@ type SessionLedger struct {
 	LogicalSessionID string
 	AccountSessions  map[AccountID]string
 	Memory           MemoryPacket
+	LastActiveAt     time.Time
+}
+
+// LastActivity is the latest of LastActiveAt and the memory update time. It
+// is zero for ledgers that predate activity tracking and were never reused.
+func (l SessionLedger) LastActivity() time.Time {
+	if l.Memory.UpdatedAt.After(l.LastActiveAt) {
+		return l.Memory.UpdatedAt
+	}
+	return l.LastActiveAt
 }
 
 type PoolRuntime struct {
 	PoolID          PoolID
 	ActiveAccountID AccountID
 	LastSyncedAt    time.Time
-	Sessions        map[string]SessionLedger
+	// LastSwitchedAt is when ActiveAccountID last changed; MinSwitchInterval
+	// is the cooldown pool next waits after it before advancing again.
+	LastSwitchedAt    time.Time
+	MinSwitchInterval time.Duration
+	Sessions          map[string]SessionLedger
 }
@@ -18,6 +18,10 @@ func TestPoolValidate(t *testing.T) {
 			name: "valid",
 			pool: Pool{ID: "default-openai", Name: "default", Provider: ProviderOpenAI, Strategy: PoolStrategyLeastWeeklyUsed},
 		},
+		{
+			name: "anthropic",
+			pool: Pool{ID: "default-anthropic", Name: "default", Provider: ProviderAnthropic, Strategy: PoolStrategyLeastWeeklyUsed},
+		},
 		{
 			name:    "missing id",
 			pool:    Pool{Name: "default", Provider: ProviderOpenAI, Strategy: PoolStrategyLeastWeeklyUsed},
@@ -8,6 +8,12 @@ import (
 
 type AccountRepository interface {
 	GetByID(ctx context.Context, id domain.AccountID) (domain.Account, error)
+	Exists(ctx context.Context, id domain.AccountID) (bool, error)
 	List(ctx context.Context) ([]domain.Account, error)
 	Save(ctx context.Context, account domain.Account) error
+	SaveAll(ctx context.Context, accounts []domain.Account) error
+	// Replace swaps the account stored under id for account, keeping its
+	// position, so an account can change ID in a single write.
+	Replace(ctx context.Context, id domain.AccountID, account domain.Account) error
+	Delete(ctx context.Context, id domain.AccountID) error
 }
@@ -38,6 +38,129 @@ func (_m *MockAccountRepository) EXPECT() *MockAccountRepository_Expecter {
 	return &MockAccountRepository_Expecter{mock: &_m.Mock}
 }
 
+// Delete provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) Delete(ctx context.Context, id domain.AccountID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.AccountID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockAccountRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id domain.AccountID
+func (_e *MockAccountRepository_Expecter) Delete(ctx interface{}, id interface{}) *MockAccountRepository_Delete_Call {
+	return &MockAccountRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockAccountRepository_Delete_Call) Run(run func(ctx context.Context, id domain.AccountID)) *MockAccountRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.AccountID
+		if args[1] != nil {
+			arg1 = args[1].(domain.AccountID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_Delete_Call) Return(err error) *MockAccountRepository_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, id domain.AccountID) error) *MockAccountRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Exists provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) Exists(ctx context.Context, id domain.AccountID) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.AccountID) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.AccountID) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.AccountID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_Exists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Exists'
+type MockAccountRepository_Exists_Call struct {
+	*mock.Call
+}
+
+// Exists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id domain.AccountID
+func (_e *MockAccountRepository_Expecter) Exists(ctx interface{}, id interface{}) *MockAccountRepository_Exists_Call {
+	return &MockAccountRepository_Exists_Call{Call: _e.mock.On("Exists", ctx, id)}
+}
+
+func (_c *MockAccountRepository_Exists_Call) Run(run func(ctx context.Context, id domain.AccountID)) *MockAccountRepository_Exists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.AccountID
+		if args[1] != nil {
+			arg1 = args[1].(domain.AccountID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_Exists_Call) Return(b bool, err error) *MockAccountRepository_Exists_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_Exists_Call) RunAndReturn(run func(ctx context.Context, id domain.AccountID) (bool, error)) *MockAccountRepository_Exists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByID provides a mock function for the type MockAccountRepository
 func (_mock *MockAccountRepository) GetByID(ctx context.Context, id domain.AccountID) (domain.Account, error) {
 	ret := _mock.Called(ctx, id)
@@ -166,6 +289,69 @@ func (_c *MockAccountRepository_List_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// Replace provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) Replace(ctx context.Context, id domain.AccountID, account domain.Account) error {
+	ret := _mock.Called(ctx, id, account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replace")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.AccountID, domain.Account) error); ok {
+		r0 = returnFunc(ctx, id, account)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_Replace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replace'
+type MockAccountRepository_Replace_Call struct {
+	*mock.Call
+}
+
+// Replace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id domain.AccountID
+//   - account domain.Account
+func (_e *MockAccountRepository_Expecter) Replace(ctx interface{}, id interface{}, account interface{}) *MockAccountRepository_Replace_Call {
+	return &MockAccountRepository_Replace_Call{Call: _e.mock.On("Replace", ctx, id, account)}
+}
+
+func (_c *MockAccountRepository_Replace_Call) Run(run func(ctx context.Context, id domain.AccountID, account domain.Account)) *MockAccountRepository_Replace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.AccountID
+		if args[1] != nil {
+			arg1 = args[1].(domain.AccountID)
+		}
+		var arg2 domain.Account
+		if args[2] != nil {
+			arg2 = args[2].(domain.Account)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_Replace_Call) Return(err error) *MockAccountRepository_Replace_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_Replace_Call) RunAndReturn(run func(ctx context.Context, id domain.AccountID, account domain.Account) error) *MockAccountRepository_Replace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Save provides a mock function for the type MockAccountRepository
 func (_mock *MockAccountRepository) Save(ctx context.Context, account domain.Account) error {
 	ret := _mock.Called(ctx, account)
@@ -222,3 +408,60 @@ func (_c *MockAccountRepository_Save_Call) RunAndReturn(run func(ctx context.Con
 	_c.Call.Return(run)
 	return _c
 }
+
+// SaveAll provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) SaveAll(ctx context.Context, accounts []domain.Account) error {
+	ret := _mock.Called(ctx, accounts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAll")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.Account) error); ok {
+		r0 = returnFunc(ctx, accounts)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_SaveAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAll'
+type MockAccountRepository_SaveAll_Call struct {
+	*mock.Call
+}
+
+// SaveAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accounts []domain.Account
+func (_e *MockAccountRepository_Expecter) SaveAll(ctx interface{}, accounts interface{}) *MockAccountRepository_SaveAll_Call {
+	return &MockAccountRepository_SaveAll_Call{Call: _e.mock.On("SaveAll", ctx, accounts)}
+}
+
+func (_c *MockAccountRepository_SaveAll_Call) Run(run func(ctx context.Context, accounts []domain.Account)) *MockAccountRepository_SaveAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []domain.Account
+		if args[1] != nil {
+			arg1 = args[1].([]domain.Account)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_SaveAll_Call) Return(err error) *MockAccountRepository_SaveAll_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_SaveAll_Call) RunAndReturn(run func(ctx context.Context, accounts []domain.Account) error) *MockAccountRepository_SaveAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -14,5 +14,6 @@ type PoolRepository interface {
 
 type PoolRuntimeRepository interface {
 	GetByPoolID(ctx context.Context, poolID domain.PoolID) (domain.PoolRuntime, error)
+	List(ctx context.Context) ([]domain.PoolRuntime, error)
 	Save(ctx context.Context, runtime domain.PoolRuntime) error
 }
@@ -6,4 +6,7 @@ type SecretStore interface {
 	Get(ctx context.Context, key string) (string, error)
 	Put(ctx context.Context, key string, value string) error
 	Delete(ctx context.Context, key string) error
+	// Has reports whether the store holds key without reading its value, so
+	// checking a gpg-backed secret does not prompt for decryption.
+	Has(ctx context.Context, key string) (bool, error)
 }